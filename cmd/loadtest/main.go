@@ -1,18 +1,19 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"net/http"
 	"sort"
 	"sync"
 	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/pkg/client"
 )
 
 type Result struct {
-	Duration   time.Duration
+	Duration time.Duration
+	// StatusCode is the status of an error response, 0 on success or a
+	// network error.
 	StatusCode int
 	Success    bool
 	Error      error
@@ -38,8 +39,13 @@ const (
 	testDuration    = 60 * time.Second
 	sliResponseTime = 300 * time.Millisecond
 	sliSuccessRate  = 99.9
+	requestTimeout  = 5 * time.Second
 )
 
+// api sends every request once: a retry would hide failures from the
+// success rate and stretch the response times.
+var api = client.New(baseURL, client.WithRetry(client.NoRetry))
+
 func main() {
 	fmt.Println("=== PR Reviewer Service Load Test ===")
 	fmt.Printf("Target RPS: %d\n", targetRPS)
@@ -60,23 +66,23 @@ func main() {
 func setupTestData() {
 	fmt.Println("Setting up test data...")
 
-	teamPayload := map[string]any{
-		"team_name": "loadtest-team",
-		"members": []map[string]any{
-			{"user_id": "lt-u1", "username": "LoadUser1", "is_active": true},
-			{"user_id": "lt-u2", "username": "LoadUser2", "is_active": true},
-			{"user_id": "lt-u3", "username": "LoadUser3", "is_active": true},
-		},
-	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
 
-	resp, err := makeRequest("POST", "/team/add", teamPayload)
-	if err != nil {
+	_, err := api.CreateTeam(ctx, client.CreateTeamRequest{
+		TeamName: "loadtest-team",
+		Members: []*client.TeamMember{
+			{UserID: "lt-u1", Username: "LoadUser1", IsActive: true},
+			{UserID: "lt-u2", Username: "LoadUser2", IsActive: true},
+			{UserID: "lt-u3", Username: "LoadUser3", IsActive: true},
+		},
+	})
+	if err != nil && !client.IsCode(err, client.ErrCodeTeamExists) {
 		fmt.Printf("Warning: Failed to create team: %v\n", err)
-	} else if resp.StatusCode != 201 && resp.StatusCode != 400 {
-		fmt.Printf("Warning: Unexpected status code when creating team: %d\n", resp.StatusCode)
 	}
 
-	fmt.Println("Test data setup complete\n")
+	fmt.Println("Test data setup complete")
+	fmt.Println()
 }
 
 func runLoadTest() []Result {
@@ -107,70 +113,43 @@ func runLoadTest() []Result {
 }
 
 func executeRequest(num int) Result {
-	endpoints := []struct {
-		method string
-		path   string
-		body   any
-	}{
-		{"GET", "/team/get?team_name=loadtest-team", nil},
-		{"GET", "/users/getReview?user_id=lt-u1", nil},
-		{"POST", "/pullRequest/create", map[string]any{
-			"pull_request_id":   fmt.Sprintf("lt-pr-%d", num),
-			"pull_request_name": fmt.Sprintf("Load Test PR %d", num),
-			"author_id":         "lt-u1",
-		}},
-		{"POST", "/pullRequest/merge", map[string]any{
-			"pull_request_id": fmt.Sprintf("lt-pr-%d", num),
-		}},
+	prID := fmt.Sprintf("lt-pr-%d", num)
+	endpoints := []func(ctx context.Context) error{
+		func(ctx context.Context) error {
+			_, err := api.GetTeam(ctx, "loadtest-team")
+			return err
+		},
+		func(ctx context.Context) error {
+			_, err := api.GetUserReviews(ctx, "lt-u1")
+			return err
+		},
+		func(ctx context.Context) error {
+			_, err := api.CreatePR(ctx, client.CreatePRRequest{
+				PullRequestID:   prID,
+				PullRequestName: fmt.Sprintf("Load Test PR %d", num),
+				AuthorID:        "lt-u1",
+			})
+			return err
+		},
+		func(ctx context.Context) error {
+			_, err := api.MergePR(ctx, prID)
+			return err
+		},
 	}
 
-	endpoint := endpoints[num%len(endpoints)]
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
 
 	start := time.Now()
-	resp, err := makeRequest(endpoint.method, endpoint.path, endpoint.body)
+	err := endpoints[num%len(endpoints)](ctx)
 	duration := time.Since(start)
 
-	result := Result{
-		Duration: duration,
-		Error:    err,
-	}
-
-	if err != nil {
-		result.Success = false
-		result.StatusCode = 0
-	} else {
-		result.StatusCode = resp.StatusCode
-		result.Success = resp.StatusCode >= 200 && resp.StatusCode < 300
+	return Result{
+		Duration:   duration,
+		StatusCode: client.StatusCode(err),
+		Success:    err == nil,
+		Error:      err,
 	}
-
-	return result
-}
-
-func makeRequest(method, path string, body any) (*http.Response, error) {
-	var bodyReader io.Reader
-
-	if body != nil {
-		jsonData, err := json.Marshal(body)
-		if err != nil {
-			return nil, err
-		}
-		bodyReader = bytes.NewBuffer(jsonData)
-	}
-
-	req, err := http.NewRequest(method, baseURL+path, bodyReader)
-	if err != nil {
-		return nil, err
-	}
-
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
-
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-	}
-
-	return client.Do(req)
 }
 
 func calculateStats(results []Result) Stats {
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/pkg/client"
+)
+
+const (
+	requestTimeout = 5 * time.Minute
+	apiKeyHeader   = "X-API-Key"
+)
+
+// exitCodes gives every error code of the service its own exit status so
+// that scripts can tell failures apart without parsing the output.
+var exitCodes = map[client.ErrorCode]int{
+	client.ErrCodeInvalidRequest:      10,
+	client.ErrCodeNotFound:            11,
+	client.ErrCodeTeamExists:          12,
+	client.ErrCodePRExists:            13,
+	client.ErrCodePRMerged:            14,
+	client.ErrCodeNotAssigned:         15,
+	client.ErrCodeNoCandidate:         16,
+	client.ErrCodeConflict:            17,
+	client.ErrCodeRateLimited:         18,
+	client.ErrCodeIdempotencyConflict: 19,
+	client.ErrCodeInvalidSnapshot:     20,
+	client.ErrCodeImportConflict:      21,
+	client.ErrCodeInvalidRoster:       22,
+	client.ErrCodeInternal:            30,
+}
+
+func exitCode(err *client.Error) int {
+	if code, ok := exitCodes[err.Code]; ok {
+		return code
+	}
+	return exitError
+}
+
+func newClient(p *profile) (*client.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if p.CertFile != "" || p.KeyFile != "" || p.CAFile != "" {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if p.CertFile != "" || p.KeyFile != "" {
+			cert, err := tls.LoadX509KeyPair(p.CertFile, p.KeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load client certificate: %w", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		if p.CAFile != "" {
+			pem, err := os.ReadFile(p.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA file: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in %s", p.CAFile)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return client.New(p.URL,
+		client.WithAPIKey(p.APIKey),
+		client.WithHTTPClient(&http.Client{Timeout: requestTimeout, Transport: transport}),
+	), nil
+}
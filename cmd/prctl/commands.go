@@ -0,0 +1,724 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/transport/http/dto"
+	"github.com/Raisondetr3/Avito-test-assignment/pkg/client"
+)
+
+const dateLayout = "2006-01-02"
+
+var commands = []command{
+	{"team add", "[-member USER_ID=USERNAME]... [-inactive USER_ID]... TEAM", "create a team with its members", runTeamAdd},
+	{"team get", "TEAM", "show a team and its members", runTeamGet},
+	{"team deactivate", "[-user USER_ID]... [-best-effort] TEAM", "deactivate members and reassign their reviews", runTeamDeactivate},
+	{"team delete", "TEAM", "deactivate all members and delete the team", runTeamDelete},
+	{"team restore", "TEAM", "restore a deleted team", runTeamRestore},
+	{"team import", "[-dry-run] FILE", "sync teams with a roster CSV", runTeamImport},
+	{"user get", "USER_ID", "show a user", runUserGet},
+	{"user list", "[-team TEAM] [-active true|false]", "list users", runUserList},
+	{"user set-active", "USER_ID true|false", "activate or deactivate a user", runUserSetActive},
+	{"user delete", "USER_ID", "deactivate and delete a user", runUserDelete},
+	{"user restore", "USER_ID", "restore a deleted user", runUserRestore},
+	{"review list", "USER_ID", "list pull requests the user reviews", runReviewList},
+	{"pr create", "PR_ID NAME AUTHOR_ID", "create a pull request and assign reviewers", runPRCreate},
+	{"pr get", "PR_ID", "show a pull request", runPRGet},
+	{"pr merge", "[-if-match VERSION] PR_ID", "merge a pull request", runPRMerge},
+	{"pr reassign", "[-if-match VERSION] PR_ID OLD_REVIEWER_ID", "replace a reviewer", runPRReassign},
+	{"pr delete", "PR_ID", "delete a pull request", runPRDelete},
+	{"pr restore", "PR_ID", "restore a deleted pull request", runPRRestore},
+	{"stats", "[-team TEAM] [-from DATE] [-to DATE]", "show pull request and review statistics", runStats},
+	{"stats cycle-time", "[-team TEAM] [-from DATE] [-to DATE]", "show time to merge percentiles", runStatsCycleTime},
+	{"stats workload", "[-team TEAM] [-from DATE] [-to DATE]", "show review load per user and team", runStatsWorkload},
+	{"health", "[-live]", "check whether the service is ready", runHealth},
+	{"export", "[-format json|ndjson] [-o FILE]", "write a snapshot of teams, users and pull requests", runExport},
+	{"import", "[-on-conflict fail|skip|overwrite] [-format json|ndjson] FILE", "load a snapshot produced by export", runImport},
+	{"profile list", "", "list profiles of the config file", runProfileList},
+}
+
+// stringList is a flag that can be repeated.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func runTeamAdd(env *env, fs *flag.FlagSet, args []string) error {
+	var members, inactive stringList
+	fs.Var(&members, "member", "member as USER_ID=USERNAME, repeatable")
+	fs.Var(&inactive, "inactive", "user ID of a member to add as inactive, repeatable")
+	args, err := parseArgs(fs, args, "TEAM")
+	if err != nil {
+		return err
+	}
+
+	req := client.CreateTeamRequest{TeamName: args[0], Members: []*client.TeamMember{}}
+	for _, member := range members {
+		userID, username, ok := strings.Cut(member, "=")
+		if !ok || userID == "" || username == "" {
+			return usagef("-member must look like USER_ID=USERNAME, got %q", member)
+		}
+		req.Members = append(req.Members, &client.TeamMember{
+			UserID:   userID,
+			Username: username,
+			IsActive: !contains(inactive, userID),
+		})
+	}
+
+	team, err := env.client.CreateTeam(env.ctx, req)
+	if err != nil {
+		return err
+	}
+	return env.printer.print(dto.TeamResponse{Team: team}, func(t *table) { printTeam(t, team) })
+}
+
+func runTeamGet(env *env, fs *flag.FlagSet, args []string) error {
+	args, err := parseArgs(fs, args, "TEAM")
+	if err != nil {
+		return err
+	}
+
+	team, err := env.client.GetTeam(env.ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return env.printer.print(team, func(t *table) { printTeam(t, team) })
+}
+
+func runTeamDeactivate(env *env, fs *flag.FlagSet, args []string) error {
+	var userIDs stringList
+	fs.Var(&userIDs, "user", "user ID to deactivate, repeatable; all members if omitted")
+	bestEffort := fs.Bool("best-effort", false, "commit every reassignment on its own and report failures")
+	args, err := parseArgs(fs, args, "TEAM")
+	if err != nil {
+		return err
+	}
+
+	req := client.BulkDeactivateRequest{TeamName: args[0], UserIDs: userIDs, BestEffort: *bestEffort}
+	resp, err := env.client.DeactivateUsers(env.ctx, req)
+	if err != nil {
+		return err
+	}
+	return env.printer.print(resp, func(t *table) { printDeactivation(t, resp) })
+}
+
+func runTeamDelete(env *env, fs *flag.FlagSet, args []string) error {
+	args, err := parseArgs(fs, args, "TEAM")
+	if err != nil {
+		return err
+	}
+
+	resp, err := env.client.DeleteTeam(env.ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return env.printer.print(resp, func(t *table) { printDeactivation(t, resp) })
+}
+
+func runTeamRestore(env *env, fs *flag.FlagSet, args []string) error {
+	args, err := parseArgs(fs, args, "TEAM")
+	if err != nil {
+		return err
+	}
+
+	team, err := env.client.RestoreTeam(env.ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return env.printer.print(dto.TeamResponse{Team: team}, func(t *table) { printTeam(t, team) })
+}
+
+func runTeamImport(env *env, fs *flag.FlagSet, args []string) error {
+	dryRun := fs.Bool("dry-run", false, "only show what the roster would change")
+	args, err := parseArgs(fs, args, "FILE")
+	if err != nil {
+		return err
+	}
+
+	roster, err := readInput(args[0])
+	if err != nil {
+		return err
+	}
+
+	result, err := env.client.ImportRoster(env.ctx, bytes.NewReader(roster), *dryRun)
+	if err != nil {
+		return err
+	}
+
+	return env.printer.print(result, func(t *table) {
+		t.section("CHANGE", "USER_ID", "TEAM", "DETAILS")
+		for _, team := range result.NewTeams {
+			t.row("new team", "", team, "")
+		}
+		for _, user := range result.NewUsers {
+			t.row("new user", user.UserID, user.TeamName, user.Username)
+		}
+		for _, move := range result.MovedUsers {
+			t.row("moved", move.UserID, move.ToTeam, "from "+move.FromTeam)
+		}
+		for _, user := range result.UpdatedUsers {
+			t.row("updated", user.UserID, user.TeamName, user.Username)
+		}
+		for _, d := range result.Deactivations {
+			t.row("deactivated", d.UserID, d.TeamName, d.Reason)
+		}
+		printReassignments(t, result.ReassignedPRs, result.SkippedPRs)
+		if result.DryRun {
+			t.note("Dry run, nothing was changed.")
+		}
+	})
+}
+
+func runUserGet(env *env, fs *flag.FlagSet, args []string) error {
+	args, err := parseArgs(fs, args, "USER_ID")
+	if err != nil {
+		return err
+	}
+
+	user, err := env.client.GetUser(env.ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return env.printer.print(dto.UserDetailsResponse{User: user}, func(t *table) { printUserDetails(t, user) })
+}
+
+func runUserList(env *env, fs *flag.FlagSet, args []string) error {
+	team := fs.String("team", "", "only users of this team")
+	active := fs.String("active", "", "only active (true) or inactive (false) users")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	filter := client.UserFilter{TeamName: *team}
+	if *active != "" {
+		isActive, err := strconv.ParseBool(*active)
+		if err != nil {
+			return usagef("-active must be true or false, got %q", *active)
+		}
+		filter.IsActive = &isActive
+	}
+
+	users, err := env.client.ListUsers(env.ctx, filter)
+	if err != nil {
+		return err
+	}
+	return env.printer.print(dto.ListUsersResponse{Users: users}, func(t *table) { printUserDetails(t, users...) })
+}
+
+func runUserSetActive(env *env, fs *flag.FlagSet, args []string) error {
+	args, err := parseArgs(fs, args, "USER_ID", "true|false")
+	if err != nil {
+		return err
+	}
+	isActive, err := strconv.ParseBool(args[1])
+	if err != nil {
+		return usagef("expected true or false, got %q", args[1])
+	}
+
+	user, err := env.client.SetUserActive(env.ctx, args[0], isActive)
+	if err != nil {
+		return err
+	}
+	return env.printer.print(dto.UserResponse{User: user}, func(t *table) { printUser(t, user) })
+}
+
+func runUserDelete(env *env, fs *flag.FlagSet, args []string) error {
+	args, err := parseArgs(fs, args, "USER_ID")
+	if err != nil {
+		return err
+	}
+
+	resp, err := env.client.DeleteUser(env.ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return env.printer.print(resp, func(t *table) { printDeactivation(t, resp) })
+}
+
+func runUserRestore(env *env, fs *flag.FlagSet, args []string) error {
+	args, err := parseArgs(fs, args, "USER_ID")
+	if err != nil {
+		return err
+	}
+
+	user, err := env.client.RestoreUser(env.ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return env.printer.print(dto.UserResponse{User: user}, func(t *table) { printUser(t, user) })
+}
+
+func runReviewList(env *env, fs *flag.FlagSet, args []string) error {
+	args, err := parseArgs(fs, args, "USER_ID")
+	if err != nil {
+		return err
+	}
+
+	resp, err := env.client.GetUserReviews(env.ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return env.printer.print(resp, func(t *table) {
+		t.section("PULL_REQUEST", "NAME", "AUTHOR", "STATUS")
+		for _, pr := range resp.PullRequests {
+			t.row(pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.Status)
+		}
+	})
+}
+
+func runPRCreate(env *env, fs *flag.FlagSet, args []string) error {
+	args, err := parseArgs(fs, args, "PR_ID", "NAME", "AUTHOR_ID")
+	if err != nil {
+		return err
+	}
+
+	req := client.CreatePRRequest{PullRequestID: args[0], PullRequestName: args[1], AuthorID: args[2]}
+	pr, err := env.client.CreatePR(env.ctx, req)
+	if err != nil {
+		return err
+	}
+	return printPR(env, pr)
+}
+
+func runPRGet(env *env, fs *flag.FlagSet, args []string) error {
+	args, err := parseArgs(fs, args, "PR_ID")
+	if err != nil {
+		return err
+	}
+
+	pr, err := env.client.GetPR(env.ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return printPR(env, pr)
+}
+
+func runPRMerge(env *env, fs *flag.FlagSet, args []string) error {
+	ifMatch := fs.Int("if-match", 0, "fail with CONFLICT unless the pull request has this version")
+	args, err := parseArgs(fs, args, "PR_ID")
+	if err != nil {
+		return err
+	}
+
+	pr, err := env.client.MergePR(env.ctx, args[0], ifMatchOption(*ifMatch)...)
+	if err != nil {
+		return err
+	}
+	return printPR(env, pr)
+}
+
+func runPRReassign(env *env, fs *flag.FlagSet, args []string) error {
+	ifMatch := fs.Int("if-match", 0, "fail with CONFLICT unless the pull request has this version")
+	args, err := parseArgs(fs, args, "PR_ID", "OLD_REVIEWER_ID")
+	if err != nil {
+		return err
+	}
+
+	resp, err := env.client.ReassignReviewer(env.ctx, args[0], args[1], ifMatchOption(*ifMatch)...)
+	if err != nil {
+		return err
+	}
+	return env.printer.print(resp, func(t *table) {
+		prRows(t, resp.PR)
+		t.note("Replaced " + args[1] + " with " + resp.ReplacedBy + ".")
+	})
+}
+
+func runPRDelete(env *env, fs *flag.FlagSet, args []string) error {
+	args, err := parseArgs(fs, args, "PR_ID")
+	if err != nil {
+		return err
+	}
+
+	if err := env.client.DeletePR(env.ctx, args[0]); err != nil {
+		return err
+	}
+	env.printer.note("Deleted pull request " + args[0] + ".")
+	return nil
+}
+
+func runPRRestore(env *env, fs *flag.FlagSet, args []string) error {
+	args, err := parseArgs(fs, args, "PR_ID")
+	if err != nil {
+		return err
+	}
+
+	pr, err := env.client.RestorePR(env.ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return printPR(env, pr)
+}
+
+// statsFlags adds the filter shared by the stats commands and returns a
+// function building it.
+func statsFlags(fs *flag.FlagSet) func() (client.StatsFilter, error) {
+	team := fs.String("team", "", "only this team")
+	from := fs.String("from", "", "start of the period, YYYY-MM-DD or RFC 3339")
+	to := fs.String("to", "", "end of the period, YYYY-MM-DD or RFC 3339")
+	return func() (client.StatsFilter, error) {
+		filter := client.StatsFilter{TeamName: *team}
+		var err error
+		if filter.From, err = parseTime("-from", *from); err != nil {
+			return filter, err
+		}
+		filter.To, err = parseTime("-to", *to)
+		return filter, err
+	}
+}
+
+func parseTime(name, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, dateLayout} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, usagef("%s must be YYYY-MM-DD or RFC 3339, got %q", name, value)
+}
+
+func runStats(env *env, fs *flag.FlagSet, args []string) error {
+	buildFilter := statsFlags(fs)
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	filter, err := buildFilter()
+	if err != nil {
+		return err
+	}
+
+	stats, err := env.client.Stats(env.ctx, filter)
+	if err != nil {
+		return err
+	}
+	return env.printer.print(stats, func(t *table) {
+		t.section("PULL_REQUESTS", "OPEN", "MERGED", "USERS", "ACTIVE", "INACTIVE", "TEAMS")
+		t.row(stats.PullRequests.Total, stats.PullRequests.Open, stats.PullRequests.Merged,
+			stats.Users.Total, stats.Users.Active, stats.Users.Inactive, stats.Teams.Total)
+
+		t.section("TEAM", "PULL_REQUESTS", "OPEN", "MERGED", "USERS", "ACTIVE", "REVIEW_ASSIGNMENTS")
+		for _, team := range stats.ByTeam {
+			t.row(team.TeamName, team.PullRequests.Total, team.PullRequests.Open, team.PullRequests.Merged,
+				team.Users.Total, team.Users.Active, team.ReviewAssignments)
+		}
+
+		t.section("REVIEWER", "USERNAME", "REVIEWS")
+		for _, reviewer := range stats.TopReviewers {
+			t.row(reviewer.UserID, reviewer.Username, reviewer.ReviewCount)
+		}
+	})
+}
+
+func runStatsCycleTime(env *env, fs *flag.FlagSet, args []string) error {
+	buildFilter := statsFlags(fs)
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	filter, err := buildFilter()
+	if err != nil {
+		return err
+	}
+
+	stats, err := env.client.CycleTime(env.ctx, filter)
+	if err != nil {
+		return err
+	}
+	return env.printer.print(stats, func(t *table) {
+		t.section("SCOPE", "NAME", "MERGED", "P50", "P90", "P99")
+		row := func(scope, name string, s client.DurationSummary) {
+			t.row(scope, name, s.Count, seconds(s.P50Seconds), seconds(s.P90Seconds), seconds(s.P99Seconds))
+		}
+		row("all", "", stats.TimeToMerge)
+		for _, team := range stats.ByTeam {
+			row("team", team.TeamName, team.TimeToMerge)
+		}
+		for _, author := range stats.ByAuthor {
+			row("author", author.AuthorID, author.TimeToMerge)
+		}
+	})
+}
+
+func runStatsWorkload(env *env, fs *flag.FlagSet, args []string) error {
+	buildFilter := statsFlags(fs)
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	filter, err := buildFilter()
+	if err != nil {
+		return err
+	}
+
+	stats, err := env.client.Workload(env.ctx, filter)
+	if err != nil {
+		return err
+	}
+	return env.printer.print(stats, func(t *table) {
+		t.section("USER_ID", "USERNAME", "TEAM", "OPEN_REVIEWS", "ASSIGNED_REVIEWS")
+		for _, user := range stats.Users {
+			t.row(user.UserID, user.Username, user.TeamName, user.OpenReviews, user.AssignedReviews)
+		}
+
+		t.section("TEAM", "ACTIVE_USERS", "OPEN_REVIEWS", "OPEN_GINI", "ASSIGNED_REVIEWS", "ASSIGNED_GINI")
+		for _, team := range stats.Teams {
+			t.row(team.TeamName, team.ActiveUsers, team.OpenReviews.Total, team.OpenReviews.Gini,
+				team.AssignedReviews.Total, team.AssignedReviews.Gini)
+		}
+	})
+}
+
+func runHealth(env *env, fs *flag.FlagSet, args []string) error {
+	live := fs.Bool("live", false, "only check that the process is up")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	probe := env.client.Ready
+	if *live {
+		probe = env.client.Live
+	}
+
+	// An unready service still reports its checks, so they are printed
+	// before the error is returned.
+	health, checkErr := probe(env.ctx)
+	if health == nil {
+		return checkErr
+	}
+
+	err := env.printer.print(health, func(t *table) {
+		t.section("CHECK", "STATUS", "LATENCY_MS", "ERROR")
+		t.row("service", health.Status, "", "")
+		names := make([]string, 0, len(health.Checks))
+		for name := range health.Checks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			check := health.Checks[name]
+			t.row(name, check.Status, check.LatencyMs, check.Error)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return checkErr
+}
+
+func runExport(env *env, fs *flag.FlagSet, args []string) error {
+	format := fs.String("format", "json", "snapshot format: json or ndjson")
+	output := fs.String("o", "-", "output file, - for stdout")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	if *format != "json" && *format != "ndjson" {
+		return usagef("unknown format %q", *format)
+	}
+
+	out := env.stdout
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if *format == "ndjson" {
+		return env.client.ExportNDJSON(env.ctx, out)
+	}
+
+	snapshot, err := env.client.Export(env.ctx)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(out).Encode(snapshot); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+func runImport(env *env, fs *flag.FlagSet, args []string) error {
+	onConflict := fs.String("on-conflict", "fail", "what to do with existing records: fail, skip or overwrite")
+	format := fs.String("format", "", "snapshot format: json or ndjson (default: by file extension)")
+	args, err := parseArgs(fs, args, "FILE")
+	if err != nil {
+		return err
+	}
+	path := args[0]
+
+	if *format == "" {
+		*format = "json"
+		if filepath.Ext(path) == ".ndjson" {
+			*format = "ndjson"
+		}
+	}
+
+	if *format != "json" && *format != "ndjson" {
+		return usagef("unknown format %q", *format)
+	}
+
+	in := io.Reader(os.Stdin)
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	policy := client.ConflictPolicy(*onConflict)
+	var result *client.ImportResponse
+	if *format == "ndjson" {
+		result, err = env.client.ImportNDJSON(env.ctx, in, policy)
+	} else {
+		var snapshot client.Snapshot
+		if err := json.NewDecoder(in).Decode(&snapshot); err != nil {
+			return fmt.Errorf("failed to read snapshot: %w", err)
+		}
+		result, err = env.client.Import(env.ctx, &snapshot, policy)
+	}
+	if err != nil {
+		return err
+	}
+
+	return env.printer.print(result, func(t *table) {
+		t.section("", "CREATED", "UPDATED", "SKIPPED")
+		for _, counts := range []struct {
+			name string
+			client.ImportCounts
+		}{{"teams", result.Teams}, {"users", result.Users}, {"pull requests", result.PullRequests}} {
+			t.row(counts.name, counts.Created, counts.Updated, counts.Skipped)
+		}
+	})
+}
+
+func runProfileList(env *env, fs *flag.FlagSet, args []string) error {
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	type profileInfo struct {
+		Name    string `json:"name"`
+		URL     string `json:"url"`
+		Current bool   `json:"current"`
+	}
+	profiles := []profileInfo{}
+	for _, name := range env.profiles.names() {
+		profiles = append(profiles, profileInfo{
+			Name:    name,
+			URL:     env.profiles.Profiles[name].URL,
+			Current: name == env.profiles.Current,
+		})
+	}
+
+	return env.printer.print(profiles, func(t *table) {
+		t.section("PROFILE", "URL", "CURRENT")
+		for _, p := range profiles {
+			current := ""
+			if p.Current {
+				current = "*"
+			}
+			t.row(p.Name, p.URL, current)
+		}
+	})
+}
+
+func printTeam(t *table, team *client.Team) {
+	t.section("TEAM", "USER_ID", "USERNAME", "ACTIVE")
+	for _, member := range team.Members {
+		t.row(team.TeamName, member.UserID, member.Username, member.IsActive)
+	}
+}
+
+func printUser(t *table, user *client.User) {
+	t.section("USER_ID", "USERNAME", "TEAM", "ACTIVE")
+	t.row(user.UserID, user.Username, user.TeamName, user.IsActive)
+}
+
+func printUserDetails(t *table, users ...*client.UserDetails) {
+	t.section("USER_ID", "USERNAME", "TEAM", "ACTIVE", "OPEN_REVIEWS")
+	for _, user := range users {
+		t.row(user.UserID, user.Username, user.TeamName, user.IsActive, user.OpenReviewCount)
+	}
+}
+
+// printPR prints a pull request in the response format of the API.
+func printPR(env *env, pr *client.PullRequest) error {
+	return env.printer.print(dto.PRResponse{PR: pr}, func(t *table) { prRows(t, pr) })
+}
+
+func prRows(t *table, pr *client.PullRequest) {
+	t.section("PULL_REQUEST", "NAME", "AUTHOR", "STATUS", "REVIEWERS", "VERSION")
+	t.row(pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.Status, pr.AssignedReviewers, pr.Version)
+}
+
+func printDeactivation(t *table, resp *client.BulkDeactivateResponse) {
+	t.section("DEACTIVATED_USER")
+	for _, userID := range resp.DeactivatedUsers {
+		t.row(userID)
+	}
+	printReassignments(t, resp.ReassignedPRs, resp.SkippedPRs)
+}
+
+func printReassignments(t *table, reassigned []client.ReassignedPRInfo, skipped []client.SkippedPRInfo) {
+	if len(reassigned) > 0 {
+		t.section("PULL_REQUEST", "OLD_REVIEWER", "NEW_REVIEWER")
+		for _, pr := range reassigned {
+			t.row(pr.PullRequestID, pr.OldReviewerID, pr.NewReviewerID)
+		}
+	}
+	if len(skipped) > 0 {
+		t.section("SKIPPED_PULL_REQUEST", "REASON")
+		for _, pr := range skipped {
+			t.row(pr.PullRequestID, pr.Reason)
+		}
+	}
+}
+
+func ifMatchOption(version int) []client.CallOption {
+	if version == 0 {
+		return nil
+	}
+	return []client.CallOption{client.IfMatch(version)}
+}
+
+// seconds formats a duration given in seconds, rounded to the second.
+func seconds(s float64) string {
+	return (time.Duration(s * float64(time.Second))).Round(time.Second).String()
+}
+
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+// Command prctl is a command-line client for the PR reviewer service.
+//
+//	prctl [-profile NAME] [-url URL] [-api-key KEY] [-output table|json|yaml] <command> [flags] [args]
+//
+// Run prctl without arguments for the list of commands. Connection settings
+// come from the flags, PRCTL_* environment variables or a profile in the
+// config file, in that order of priority; see profile.go.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Raisondetr3/Avito-test-assignment/pkg/client"
+)
+
+// Exit codes other than these come from the error code of the service, see
+// exitCodes in client.go.
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	err := execute(args, stdout, stderr)
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+
+	fmt.Fprintf(stderr, "prctl: %v\n", err)
+
+	var usageErr *usageError
+	if errors.As(err, &usageErr) {
+		return exitUsage
+	}
+	var apiErr *client.Error
+	if errors.As(err, &apiErr) {
+		return exitCode(apiErr)
+	}
+	return exitError
+}
+
+// usageError reports a malformed command line.
+type usageError struct {
+	message string
+}
+
+func (e *usageError) Error() string {
+	return e.message
+}
+
+func usagef(format string, args ...any) error {
+	return &usageError{message: fmt.Sprintf(format, args...)}
+}
+
+// env is what a command needs to talk to the service and print the result.
+type env struct {
+	ctx      context.Context
+	client   *client.Client
+	printer  *printer
+	profiles *profileSet
+	stdout   io.Writer
+}
+
+type command struct {
+	// name is one or two words, e.g. "export" or "team add".
+	name    string
+	args    string
+	summary string
+	run     func(env *env, fs *flag.FlagSet, args []string) error
+}
+
+func execute(args []string, stdout, stderr io.Writer) error {
+	global := flag.NewFlagSet("prctl", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	profileName := global.String("profile", "", "profile from the config file (env PRCTL_PROFILE)")
+	baseURL := global.String("url", "", "service base URL (env PRCTL_URL, default "+defaultURL+")")
+	apiKey := global.String("api-key", "", "value of the X-API-Key header (env PRCTL_API_KEY)")
+	output := global.String("output", "", "output format: table, json or yaml (env PRCTL_OUTPUT)")
+	global.Usage = func() { printUsage(global) }
+	if err := global.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &usageError{message: err.Error()}
+	}
+
+	cmd, rest := findCommand(global.Args())
+	if cmd == nil {
+		global.Usage()
+		if global.NArg() == 0 {
+			return usagef("no command given")
+		}
+		return usagef("unknown command %q", strings.Join(global.Args(), " "))
+	}
+
+	profiles, err := loadProfiles()
+	if err != nil {
+		return err
+	}
+	settings, err := profiles.resolve(overrides{
+		profile: *profileName,
+		url:     *baseURL,
+		apiKey:  *apiKey,
+		output:  *output,
+	})
+	if err != nil {
+		return err
+	}
+
+	c, err := newClient(settings)
+	if err != nil {
+		return err
+	}
+	p, err := newPrinter(settings.Output, stdout)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fs := newFlagSet(cmd, stderr)
+	return cmd.run(&env{ctx: ctx, client: c, printer: p, profiles: profiles, stdout: stdout}, fs, rest)
+}
+
+// findCommand returns the command with the longest name the arguments start
+// with, so that "stats workload" wins over "stats".
+func findCommand(args []string) (*command, []string) {
+	var found *command
+	var words int
+	for i := range commands {
+		name := strings.Fields(commands[i].name)
+		if len(name) > words && len(args) >= len(name) && strings.Join(args[:len(name)], " ") == commands[i].name {
+			found, words = &commands[i], len(name)
+		}
+	}
+	if found == nil {
+		return nil, nil
+	}
+	return found, args[words:]
+}
+
+func printUsage(global *flag.FlagSet) {
+	out := global.Output()
+	fmt.Fprintln(out, "Usage: prctl [flags] <command> [command flags] [args]")
+	fmt.Fprintln(out, "\nCommands:")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s\t%s\n", cmd.name, cmd.summary)
+	}
+	w.Flush()
+	fmt.Fprintln(out, "\nRun prctl <command> -h for the arguments of a command.")
+	fmt.Fprintln(out, "\nFlags:")
+	global.PrintDefaults()
+}
+
+// newFlagSet returns the flag set of a command; its usage line lists the
+// positional arguments.
+func newFlagSet(cmd *command, output io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: prctl %s %s\n", cmd.name, cmd.args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseArgs parses the command flags and checks that exactly the given
+// positional arguments follow them.
+func parseArgs(fs *flag.FlagSet, args []string, names ...string) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, err
+		}
+		return nil, &usageError{message: err.Error()}
+	}
+	if fs.NArg() != len(names) {
+		fs.Usage()
+		if len(names) == 0 {
+			return nil, usagef("%s takes no arguments", fs.Name())
+		}
+		return nil, usagef("%s takes %s", fs.Name(), strings.Join(names, " "))
+	}
+	return fs.Args(), nil
+}
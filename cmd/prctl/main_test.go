@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/transport/http/dto"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/transport/http/middleware"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /pullRequest/get", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(apiKeyHeader) != "secret" {
+			middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "missing api key")
+			return
+		}
+		if r.URL.Query().Get("pull_request_id") != "pr-1" {
+			middleware.WriteJSONError(w, http.StatusNotFound, "NOT_FOUND", "pull request not found")
+			return
+		}
+		middleware.WriteJSON(w, http.StatusOK, dto.PRResponse{PR: &dto.PullRequest{
+			PullRequestID:     "pr-1",
+			PullRequestName:   "Add search",
+			AuthorID:          "u1",
+			Status:            "OPEN",
+			AssignedReviewers: []string{"u2", "u3"},
+			Version:           1,
+		}})
+	})
+	mux.HandleFunc("GET /team/get", func(w http.ResponseWriter, r *http.Request) {
+		// Unlike the other endpoints, /team/get returns the team unwrapped.
+		middleware.WriteJSON(w, http.StatusOK, &dto.Team{
+			TeamName: r.URL.Query().Get("team_name"),
+			Members: []*dto.TeamMember{
+				{UserID: "u1", Username: "Alice", IsActive: true},
+				{UserID: "u2", Username: "Bob", IsActive: false},
+			},
+		})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// writeConfig points PRCTL_CONFIG at a config file with a profile for the
+// server and clears the other PRCTL_* variables.
+func writeConfig(t *testing.T, server *httptest.Server) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	config := "current: test\nprofiles:\n  test:\n    url: " + server.URL + "\n    api_key: secret\n"
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	t.Setenv("PRCTL_CONFIG", path)
+	for _, key := range []string{"PRCTL_PROFILE", "PRCTL_URL", "PRCTL_API_KEY", "PRCTL_OUTPUT"} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestOutputFormats(t *testing.T) {
+	writeConfig(t, newTestServer(t))
+
+	tests := []struct {
+		output string
+		want   []string
+	}{
+		{"table", []string{"PULL_REQUEST  NAME        AUTHOR  STATUS  REVIEWERS  VERSION", "pr-1          Add search  u1      OPEN    u2,u3      1"}},
+		{"json", []string{`"pull_request_id": "pr-1"`, `"assigned_reviewers": [`}},
+		{"yaml", []string{"pr:\n  pull_request_id: pr-1\n  pull_request_name: Add search", "  assigned_reviewers:\n    - u2\n    - u3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run([]string{"-output", tt.output, "pr", "get", "pr-1"}, &stdout, &stderr)
+			if code != exitOK {
+				t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(stdout.String(), want) {
+					t.Fatalf("Expected output to contain %q, got:\n%s", want, stdout.String())
+				}
+			}
+		})
+	}
+}
+
+func TestTeamGet(t *testing.T) {
+	writeConfig(t, newTestServer(t))
+
+	tests := []struct {
+		output string
+		want   []string
+	}{
+		{"table", []string{"TEAM     USER_ID  USERNAME  ACTIVE", "backend  u1       Alice     true", "backend  u2       Bob       false"}},
+		{"json", []string{`"team_name": "backend"`, `"username": "Alice"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run([]string{"-output", tt.output, "team", "get", "backend"}, &stdout, &stderr)
+			if code != exitOK {
+				t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(stdout.String(), want) {
+					t.Fatalf("Expected output to contain %q, got:\n%s", want, stdout.String())
+				}
+			}
+		})
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	server := newTestServer(t)
+	writeConfig(t, server)
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"success", []string{"pr", "get", "pr-1"}, exitOK},
+		{"service error", []string{"pr", "get", "pr-2"}, exitCodes["NOT_FOUND"]},
+		{"flag overrides profile", []string{"-api-key", "wrong", "pr", "get", "pr-1"}, exitCodes["INVALID_REQUEST"]},
+		{"unknown command", []string{"pr", "close", "pr-1"}, exitUsage},
+		{"missing argument", []string{"pr", "get"}, exitUsage},
+		{"unknown profile", []string{"-profile", "prod", "pr", "get", "pr-1"}, exitUsage},
+		{"unreachable service", []string{"-url", "http://127.0.0.1:1", "pr", "get", "pr-1"}, exitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tt.args, &stdout, &stderr); code != tt.want {
+				t.Fatalf("Expected exit code %d, got %d: %s", tt.want, code, stderr.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// printer writes command results as a table for people or as JSON or YAML,
+// with the field names of the API, for scripts.
+type printer struct {
+	format string
+	out    io.Writer
+}
+
+func newPrinter(format string, out io.Writer) (*printer, error) {
+	switch format {
+	case "table", "json", "yaml":
+		return &printer{format: format, out: out}, nil
+	default:
+		return nil, usagef("unknown output format %q, want table, json or yaml", format)
+	}
+}
+
+// print writes v in the chosen format; fill builds the table view.
+func (p *printer) print(v any, fill func(t *table)) error {
+	switch p.format {
+	case "json":
+		encoder := json.NewEncoder(p.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case "yaml":
+		return writeYAML(p.out, v)
+	default:
+		t := &table{w: tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)}
+		fill(t)
+		return t.w.Flush()
+	}
+}
+
+// note prints a message in the table view only; JSON and YAML output stays
+// empty for responses without a body.
+func (p *printer) note(message string) {
+	if p.format == "table" {
+		fmt.Fprintln(p.out, message)
+	}
+}
+
+// writeYAML converts v through its JSON form, so that field names and order
+// match the JSON output.
+func writeYAML(out io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+
+	encoder := yaml.NewEncoder(out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// blockStyle drops the flow style YAML keeps from the JSON input, leaving
+// quoting of string values as the encoder sees fit.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// table is a tab-aligned text table. Sections are separated by a blank line.
+type table struct {
+	w        *tabwriter.Writer
+	sections int
+}
+
+// section starts a table with the given column headers.
+func (t *table) section(headers ...string) {
+	if t.sections > 0 {
+		fmt.Fprintln(t.w)
+	}
+	t.sections++
+	fmt.Fprintln(t.w, strings.Join(headers, "\t"))
+}
+
+// note ends the table with a line of text.
+func (t *table) note(message string) {
+	fmt.Fprintln(t.w)
+	fmt.Fprintln(t.w, message)
+}
+
+func (t *table) row(values ...any) {
+	cells := make([]string, len(values))
+	for i, value := range values {
+		cells[i] = formatCell(value)
+	}
+	fmt.Fprintln(t.w, strings.Join(cells, "\t"))
+}
+
+func formatCell(value any) string {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return "-"
+		}
+		return v
+	case []string:
+		if len(v) == 0 {
+			return "-"
+		}
+		return strings.Join(v, ",")
+	case float64:
+		return fmt.Sprintf("%.2f", v)
+	case *float64:
+		if v == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%.2f", *v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
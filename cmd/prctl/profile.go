@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultURL     = "http://localhost:8080"
+	defaultOutput  = "table"
+	defaultProfile = "default"
+)
+
+// profileSet is the prctl config file, by default
+// $XDG_CONFIG_HOME/prctl/config.yaml, or the file named by PRCTL_CONFIG:
+//
+//	current: staging
+//	profiles:
+//	  local:
+//	    url: http://localhost:8080
+//	  staging:
+//	    url: https://reviewer.staging.example.com
+//	    api_key: secret
+//	    cert_file: client.pem
+//	    key_file: client-key.pem
+//	    ca_file: ca.pem
+//	    output: yaml
+type profileSet struct {
+	Current  string              `yaml:"current"`
+	Profiles map[string]*profile `yaml:"profiles"`
+
+	path string
+}
+
+// profile holds the connection settings for one deployment. CertFile and
+// KeyFile authenticate the client when the service requires mTLS, CAFile
+// verifies a server certificate signed by a private CA.
+type profile struct {
+	URL      string `yaml:"url"`
+	APIKey   string `yaml:"api_key"`
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	CAFile   string `yaml:"ca_file"`
+	Output   string `yaml:"output"`
+}
+
+// overrides are the values given on the command line.
+type overrides struct {
+	profile string
+	url     string
+	apiKey  string
+	output  string
+}
+
+func configPath() (string, error) {
+	if path := os.Getenv("PRCTL_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "prctl", "config.yaml"), nil
+}
+
+// loadProfiles reads the config file. A missing file is the same as an
+// empty one.
+func loadProfiles() (*profileSet, error) {
+	set := &profileSet{}
+
+	path, err := configPath()
+	if err != nil {
+		// Without a home directory only flags and the environment apply.
+		return set, nil
+	}
+	set.path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return set, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(set); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return set, nil
+}
+
+// resolve picks the profile named on the command line, in PRCTL_PROFILE or
+// as current in the file, falling back to the profile named default if
+// there is one, and applies the command line and PRCTL_* overrides to it.
+func (s *profileSet) resolve(o overrides) (*profile, error) {
+	name := firstOf(o.profile, os.Getenv("PRCTL_PROFILE"), s.Current)
+
+	resolved := profile{}
+	if name != "" {
+		p, ok := s.Profiles[name]
+		if !ok {
+			return nil, usagef("profile %q is not defined in %s", name, s.path)
+		}
+		resolved = *p
+	} else if p, ok := s.Profiles[defaultProfile]; ok {
+		resolved = *p
+	}
+
+	resolved.URL = firstOf(o.url, os.Getenv("PRCTL_URL"), resolved.URL, defaultURL)
+	resolved.APIKey = firstOf(o.apiKey, os.Getenv("PRCTL_API_KEY"), resolved.APIKey)
+	resolved.Output = firstOf(o.output, os.Getenv("PRCTL_OUTPUT"), resolved.Output, defaultOutput)
+
+	return &resolved, nil
+}
+
+func (s *profileSet) names() []string {
+	names := make([]string, 0, len(s.Profiles))
+	for name := range s.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func firstOf(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -1,20 +1,21 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
 	"math/rand"
-	"net/http"
 	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/pkg/client"
 )
 
 type Result struct {
-	Duration   time.Duration
+	Duration time.Duration
+	// StatusCode is the status of an error response, 0 on success or a
+	// network error.
 	StatusCode int
 	Success    bool
 	Error      error
@@ -42,8 +43,13 @@ const (
 	testDuration    = 60 * time.Second
 	sliResponseTime = 300 * time.Millisecond
 	sliSuccessRate  = 99.9
+	requestTimeout  = 5 * time.Second
 )
 
+// api sends every request once: a retry would hide failures from the
+// success rate and stretch the response times.
+var api = client.New(baseURL, client.WithRetry(client.NoRetry))
+
 var (
 	prCounter   atomic.Int64
 	teamCounter atomic.Int64
@@ -59,7 +65,8 @@ func main() {
 	setupTestData()
 
 	fmt.Println("Starting realistic load test...")
-	fmt.Println("Simulating real usage patterns:\n")
+	fmt.Println("Simulating real usage patterns:")
+	fmt.Println()
 	results := runLoadTest()
 
 	stats := calculateStats(results)
@@ -70,20 +77,27 @@ func main() {
 func setupTestData() {
 	fmt.Println("Setting up test data...")
 
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
 	for i := 1; i <= 3; i++ {
-		teamPayload := map[string]any{
-			"team_name": fmt.Sprintf("team-%d", i),
-			"members": []map[string]any{
-				{"user_id": fmt.Sprintf("u%d-1", i), "username": fmt.Sprintf("User%d-1", i), "is_active": true},
-				{"user_id": fmt.Sprintf("u%d-2", i), "username": fmt.Sprintf("User%d-2", i), "is_active": true},
-				{"user_id": fmt.Sprintf("u%d-3", i), "username": fmt.Sprintf("User%d-3", i), "is_active": true},
-			},
+		members := make([]*client.TeamMember, 0, 3)
+		for j := 1; j <= 3; j++ {
+			members = append(members, &client.TeamMember{
+				UserID:   fmt.Sprintf("u%d-%d", i, j),
+				Username: fmt.Sprintf("User%d-%d", i, j),
+				IsActive: true,
+			})
 		}
 
-		makeRequest("POST", "/team/add", teamPayload)
+		_, err := api.CreateTeam(ctx, client.CreateTeamRequest{TeamName: fmt.Sprintf("team-%d", i), Members: members})
+		if err != nil && !client.IsCode(err, client.ErrCodeTeamExists) {
+			fmt.Printf("Warning: Failed to create team: %v\n", err)
+		}
 	}
 
-	fmt.Println("Test data setup complete\n")
+	fmt.Println("Test data setup complete")
+	fmt.Println()
 }
 
 func runLoadTest() []Result {
@@ -146,26 +160,32 @@ func createPROperation() Result {
 	teamNum := (prNum % 3) + 1
 	authorNum := (prNum % 3) + 1
 
-	body := map[string]any{
-		"pull_request_id":   fmt.Sprintf("pr-%d", prNum),
-		"pull_request_name": fmt.Sprintf("Feature PR %d", prNum),
-		"author_id":         fmt.Sprintf("u%d-%d", teamNum, authorNum),
+	req := client.CreatePRRequest{
+		PullRequestID:   fmt.Sprintf("pr-%d", prNum),
+		PullRequestName: fmt.Sprintf("Feature PR %d", prNum),
+		AuthorID:        fmt.Sprintf("u%d-%d", teamNum, authorNum),
 	}
 
-	return executeRequest("POST", "/pullRequest/create", body, "createPR")
+	return executeRequest("createPR", func(ctx context.Context) error {
+		_, err := api.CreatePR(ctx, req)
+		return err
+	})
 }
 
 func getTeamOperation() Result {
-	teamNum := (rand.Intn(3) + 1)
-	path := fmt.Sprintf("/team/get?team_name=team-%d", teamNum)
-	return executeRequest("GET", path, nil, "getTeam")
+	teamName := fmt.Sprintf("team-%d", rand.Intn(3)+1)
+	return executeRequest("getTeam", func(ctx context.Context) error {
+		_, err := api.GetTeam(ctx, teamName)
+		return err
+	})
 }
 
 func getUserReviewsOperation() Result {
-	teamNum := (rand.Intn(3) + 1)
-	userNum := (rand.Intn(3) + 1)
-	path := fmt.Sprintf("/users/getReview?user_id=u%d-%d", teamNum, userNum)
-	return executeRequest("GET", path, nil, "getUserReviews")
+	userID := fmt.Sprintf("u%d-%d", rand.Intn(3)+1, rand.Intn(3)+1)
+	return executeRequest("getUserReviews", func(ctx context.Context) error {
+		_, err := api.GetUserReviews(ctx, userID)
+		return err
+	})
 }
 
 func mergePROperation() Result {
@@ -174,62 +194,28 @@ func mergePROperation() Result {
 		return getTeamOperation()
 	}
 
-	prNum := rand.Int63n(currentPRCount-5) + 1
-
-	body := map[string]any{
-		"pull_request_id": fmt.Sprintf("pr-%d", prNum),
-	}
-
-	return executeRequest("POST", "/pullRequest/merge", body, "mergePR")
+	prID := fmt.Sprintf("pr-%d", rand.Int63n(currentPRCount-5)+1)
+	return executeRequest("mergePR", func(ctx context.Context) error {
+		_, err := api.MergePR(ctx, prID)
+		return err
+	})
 }
 
-func executeRequest(method, path string, body any, endpoint string) Result {
+func executeRequest(endpoint string, call func(ctx context.Context) error) Result {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
 	start := time.Now()
-	resp, err := makeRequest(method, path, body)
+	err := call(ctx)
 	duration := time.Since(start)
 
-	result := Result{
-		Duration: duration,
-		Error:    err,
-		Endpoint: endpoint,
-	}
-
-	if err != nil {
-		result.Success = false
-		result.StatusCode = 0
-	} else {
-		result.StatusCode = resp.StatusCode
-		result.Success = resp.StatusCode >= 200 && resp.StatusCode < 300
-	}
-
-	return result
-}
-
-func makeRequest(method, path string, body any) (*http.Response, error) {
-	var bodyReader io.Reader
-
-	if body != nil {
-		jsonData, err := json.Marshal(body)
-		if err != nil {
-			return nil, err
-		}
-		bodyReader = bytes.NewBuffer(jsonData)
+	return Result{
+		Duration:   duration,
+		StatusCode: client.StatusCode(err),
+		Success:    err == nil,
+		Error:      err,
+		Endpoint:   endpoint,
 	}
-
-	req, err := http.NewRequest(method, baseURL+path, bodyReader)
-	if err != nil {
-		return nil, err
-	}
-
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
-
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-	}
-
-	return client.Do(req)
 }
 
 func calculateStats(results []Result) Stats {
@@ -248,7 +234,7 @@ func calculateStats(results []Result) Stats {
 		if r.Success {
 			successCount++
 		} else {
-			if r.Error != nil {
+			if r.StatusCode == 0 {
 				errorsByType["network_error"]++
 			} else {
 				errorsByType[fmt.Sprintf("http_%d", r.StatusCode)]++
@@ -3,7 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,15 +11,29 @@ import (
 	"time"
 
 	"github.com/Raisondetr3/Avito-test-assignment/internal/config"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/directory"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/logger"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/ratelimit"
 	"github.com/Raisondetr3/Avito-test-assignment/internal/repository/postgres"
 	"github.com/Raisondetr3/Avito-test-assignment/internal/service"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/tlsconfig"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/tracing"
 	httpTransport "github.com/Raisondetr3/Avito-test-assignment/internal/transport/http"
 	"github.com/Raisondetr3/Avito-test-assignment/internal/transport/http/handlers"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/transport/http/middleware"
+)
+
+const migrationsPath = "migrations"
+
+const (
+	purgeInterval        = time.Minute
+	rateLimitIdleTimeout = 10 * time.Minute
 )
 
 func main() {
 	if err := run(); err != nil {
-		log.Fatal(err)
+		slog.Error("service stopped with error", slog.String("error", err.Error()))
+		os.Exit(1)
 	}
 }
 
@@ -29,73 +43,231 @@ func run() error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	db, err := postgres.NewDB(cfg.Database.DSN())
+	appLogger, err := logger.New(cfg.Log, os.Stdout)
+	if err != nil {
+		return fmt.Errorf("failed to create logger: %w", err)
+	}
+	slog.SetDefault(appLogger)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
 	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+		return fmt.Errorf("failed to set up tracing: %w", err)
 	}
-	defer db.Close()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("failed to flush traces", slog.String("error", err.Error()))
+		}
+	}()
 
-	if err := db.RunMigrations("migrations"); err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
+	store, err := openStorage(cfg)
+	if err != nil {
+		return err
 	}
+	defer store.close() //nolint:errcheck
 
-	log.Println("Database connected and migrations applied")
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
 
-	userRepo := postgres.NewUserRepository(db)
-	teamRepo := postgres.NewTeamRepository(db)
-	prRepo := postgres.NewPRRepository(db)
-	statsRepo := postgres.NewStatsRepository(db)
+	store.withCache(backgroundCtx, cfg.Cache)
 
 	reviewerAssigner := service.NewReviewerAssigner()
+	reviewerAssigner.SetReviewersPerPR(cfg.Assignment.ReviewersPerPR)
+
+	userService := service.NewUserService(store.users)
+	teamService := service.NewTeamService(store.teams)
+	prService := service.NewPRService(store.prs, store.users, reviewerAssigner)
+	statsService := service.NewStatsService(store.stats)
+	bulkDeactivationService := service.NewBulkDeactivationService(
+		store.users, store.teams, store.prs, store.txManager, reviewerAssigner,
+	)
 
-	userService := service.NewUserService(userRepo)
-	teamService := service.NewTeamService(teamRepo)
-	prService := service.NewPRService(prRepo, userRepo, reviewerAssigner)
-	statsService := service.NewStatsService(statsRepo)
-	bulkDeactivationService := service.NewBulkDeactivationService(userRepo, teamRepo, prRepo, reviewerAssigner)
+	deletionService := service.NewDeletionService(
+		store.users, store.teams, store.prs, store.txManager, bulkDeactivationService,
+	)
+	snapshotService := service.NewSnapshotService(store.snapshots, store.txManager)
+	rosterService := service.NewRosterService(store.users, store.teams, store.txManager, bulkDeactivationService)
 
-	teamHandler := handlers.NewTeamHandler(teamService, bulkDeactivationService)
-	userHandler := handlers.NewUserHandler(userService, prService)
-	prHandler := handlers.NewPRHandler(prService)
+	teamHandler := handlers.NewTeamHandler(teamService, bulkDeactivationService, deletionService, rosterService)
+	userHandler := handlers.NewUserHandler(userService, prService, deletionService)
+	prHandler := handlers.NewPRHandler(prService, deletionService)
 	statsHandler := handlers.NewStatsHandler(statsService)
+	adminHandler := handlers.NewAdminHandler(snapshotService)
+
+	healthHandler := handlers.NewHealthHandler(cfg.Server.ReadinessTimeout, store.healthChecks...)
 
-	router := httpTransport.NewRouter(teamHandler, userHandler, prHandler, statsHandler)
+	var rateLimiter *middleware.RateLimiter
+	if cfg.RateLimit.Enabled {
+		var limiter ratelimit.Limiter = ratelimit.NewMemoryLimiter()
+		if cfg.RateLimit.Backend == "postgres" {
+			limiter = postgres.NewRateLimiter(store.db)
+		}
+		rateLimiter = middleware.NewRateLimiter(limiter, ratelimit.PolicyFromConfig(cfg.RateLimit))
+
+		go runPeriodically(backgroundCtx, "purge rate limit buckets", purgeInterval, func(ctx context.Context) error {
+			return limiter.Purge(ctx, rateLimitIdleTimeout)
+		})
+	}
+
+	idempotencyMiddleware := middleware.NewIdempotency(store.idempotency, cfg.Idempotency.LockTimeout)
+	go runPeriodically(backgroundCtx, "purge idempotency keys", purgeInterval, func(ctx context.Context) error {
+		return store.idempotency.Purge(ctx, cfg.Idempotency.TTL)
+	})
+
+	if cfg.Archive.AfterDays > 0 {
+		olderThan := time.Duration(cfg.Archive.AfterDays) * 24 * time.Hour
+		go runPeriodically(backgroundCtx, "archive merged pull requests", cfg.Archive.Interval, func(ctx context.Context) error {
+			_, err := deletionService.ArchiveMergedPRs(ctx, olderThan)
+			return err
+		})
+	}
+
+	if cfg.Directory.Enabled() {
+		directorySyncService := service.NewDirectorySyncService(directory.NewLDAPSource(cfg.Directory), rosterService)
+		syncDirectory := func(ctx context.Context) error {
+			result, err := directorySyncService.Sync(ctx)
+			if err != nil {
+				return err
+			}
+			slog.Info("directory synced",
+				slog.Int("new_teams", len(result.Diff.NewTeams)),
+				slog.Int("new_users", len(result.Diff.NewUsers)),
+				slog.Int("moved_users", len(result.Diff.MovedUsers)),
+				slog.Int("deactivated_users", len(result.Deactivation.DeactivatedUsers)),
+			)
+			return nil
+		}
+
+		// The first sync runs right away instead of one interval after start.
+		go func() {
+			if err := syncDirectory(backgroundCtx); err != nil {
+				slog.Error("directory sync failed", slog.String("error", err.Error()))
+			}
+			runPeriodically(backgroundCtx, "sync directory", cfg.Directory.Interval, syncDirectory)
+		}()
+	}
+
+	router := httpTransport.NewRouter(
+		teamHandler, userHandler, prHandler, statsHandler, healthHandler, adminHandler,
+		rateLimiter, idempotencyMiddleware,
+	)
 
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
 		Handler:      router,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+
+	tlsEnabled := cfg.Server.TLS.Enabled()
+	if tlsEnabled {
+		reloader, err := tlsconfig.NewReloader(cfg.Server.TLS)
+		if err != nil {
+			return fmt.Errorf("failed to load TLS certificates: %w", err)
+		}
+		server.TLSConfig = reloader.ServerConfig()
+
+		watchCtx, stopWatch := context.WithCancel(context.Background())
+		defer stopWatch()
+		go reloader.Watch(watchCtx, cfg.Server.TLS.ReloadInterval)
 	}
 
 	serverErrors := make(chan error, 1)
 
 	go func() {
-		log.Printf("Server starting on port %d", cfg.Server.Port)
+		slog.Info("server starting",
+			slog.Int("port", cfg.Server.Port),
+			slog.Bool("tls", tlsEnabled),
+			slog.Bool("mtls", cfg.Server.TLS.ClientCAFile != ""),
+		)
+		if tlsEnabled {
+			serverErrors <- server.ListenAndServeTLS("", "")
+			return
+		}
 		serverErrors <- server.ListenAndServe()
 	}()
 
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
 
-	select {
-	case err := <-serverErrors:
-		return fmt.Errorf("server error: %w", err)
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
 
-	case sig := <-shutdown:
-		log.Printf("Received signal %v, starting graceful shutdown", sig)
+	for {
+		select {
+		case err := <-serverErrors:
+			return fmt.Errorf("server error: %w", err)
 
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
+		case <-reload:
+			cfg = reloadConfig(cfg, reviewerAssigner, rateLimiter)
+
+		case sig := <-shutdown:
+			slog.Info("starting graceful shutdown", slog.String("signal", sig.String()))
+
+			healthHandler.SetShuttingDown()
+			time.Sleep(cfg.Server.ShutdownDrainDelay)
+
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+			defer cancel()
+
+			if err := server.Shutdown(ctx); err != nil {
+				_ = server.Close()
+				return fmt.Errorf("graceful shutdown failed: %w", err)
+			}
+
+			slog.Info("server stopped gracefully")
+			return nil
+		}
+	}
+}
+
+// runPeriodically calls task every interval until ctx is cancelled, logging
+// failures.
+func runPeriodically(ctx context.Context, name string, interval time.Duration, task func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-		if err := server.Shutdown(ctx); err != nil {
-			_ = server.Close()
-			return fmt.Errorf("graceful shutdown failed: %w", err)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := task(ctx); err != nil {
+				slog.Error("periodic task failed", slog.String("task", name), slog.String("error", err.Error()))
+			}
 		}
+	}
+}
+
+func reloadConfig(
+	current *config.Config, reviewerAssigner *service.ReviewerAssigner, rateLimiter *middleware.RateLimiter,
+) *config.Config {
+	next, err := config.Load()
+	if err != nil {
+		slog.Error("config reload failed, keeping current configuration", slog.String("error", err.Error()))
+		return current
+	}
+
+	reloaded, restartRequired := current.Reloaded(next)
+
+	if err := logger.SetLevel(reloaded.Log.Level); err != nil {
+		slog.Error("failed to apply log level", slog.String("error", err.Error()))
+	}
+	reviewerAssigner.SetReviewersPerPR(reloaded.Assignment.ReviewersPerPR)
+	if rateLimiter != nil {
+		rateLimiter.SetPolicy(ratelimit.PolicyFromConfig(reloaded.RateLimit))
+	}
 
-		log.Println("Server stopped gracefully")
+	if len(restartRequired) > 0 {
+		slog.Warn("config changes ignored until restart", slog.Any("sections", restartRequired))
 	}
 
-	return nil
+	slog.Info("configuration reloaded",
+		slog.String("log_level", reloaded.Log.Level),
+		slog.Int("reviewers_per_pr", reloaded.Assignment.ReviewersPerPR),
+	)
+
+	return reloaded
 }
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/cache"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/config"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/idempotency"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/metrics"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/repository/cached"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/repository/memory"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/repository/postgres"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/repository/sqlite"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/service"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/transport/http/handlers"
+)
+
+// storage is the set of repositories of the configured backend.
+type storage struct {
+	users        service.UserRepository
+	teams        service.TeamRepository
+	prs          service.PRRepository
+	stats        service.StatsRepository
+	snapshots    service.SnapshotRepository
+	txManager    service.TxManager
+	idempotency  idempotency.Store
+	healthChecks []handlers.HealthCheck
+	// db is nil unless the backend is postgres.
+	db    *postgres.DB
+	close func() error
+}
+
+func openStorage(cfg *config.Config) (*storage, error) {
+	switch cfg.Storage {
+	case "memory":
+		slog.Warn("using in-memory storage, data will be lost on restart")
+		return openMemoryStorage(), nil
+	case "sqlite":
+		return openSQLiteStorage(cfg)
+	default:
+		return openPostgresStorage(cfg)
+	}
+}
+
+func openMemoryStorage() *storage {
+	store := memory.NewStore()
+	return &storage{
+		users:       memory.NewUserRepository(store),
+		teams:       memory.NewTeamRepository(store),
+		prs:         memory.NewPRRepository(store),
+		stats:       memory.NewStatsRepository(store),
+		snapshots:   memory.NewSnapshotRepository(store),
+		txManager:   memory.NewTxManager(store),
+		idempotency: memory.NewIdempotencyStore(),
+		close:       func() error { return nil },
+	}
+}
+
+func openSQLiteStorage(cfg *config.Config) (*storage, error) {
+	db, err := sqlite.NewDB(cfg.SQLite.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := db.RunMigrations(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	expectedVersion, err := sqlite.LatestMigrationVersion()
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	slog.Info("database opened and migrations applied", slog.String("path", cfg.SQLite.Path))
+
+	return &storage{
+		users:       sqlite.NewUserRepository(db),
+		teams:       sqlite.NewTeamRepository(db),
+		prs:         sqlite.NewPRRepository(db),
+		stats:       sqlite.NewStatsRepository(db),
+		snapshots:   sqlite.NewSnapshotRepository(db),
+		txManager:   sqlite.NewTxManager(db),
+		idempotency: sqlite.NewIdempotencyRepository(db),
+		healthChecks: []handlers.HealthCheck{
+			{Name: "database", Check: db.PingContext},
+			{Name: "migrations", Check: func(ctx context.Context) error {
+				return db.CheckMigrationVersion(ctx, expectedVersion)
+			}},
+		},
+		close: db.Close,
+	}, nil
+}
+
+func openPostgresStorage(cfg *config.Config) (*storage, error) {
+	db, err := postgres.NewDB(cfg.Database.DSN(), postgres.PoolConfig{
+		MaxConns:          cfg.Database.MaxOpenConns,
+		MinConns:          cfg.Database.MinConns,
+		MaxConnLifetime:   cfg.Database.ConnMaxLifetime,
+		MaxConnIdleTime:   cfg.Database.ConnMaxIdleTime,
+		HealthCheckPeriod: cfg.Database.HealthCheckPeriod,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	if err := db.RunMigrations(migrationsPath); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	expectedVersion, err := postgres.LatestMigrationVersion(migrationsPath)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	slog.Info("database connected and migrations applied")
+
+	if err := metrics.RegisterPoolStats(db.Pool, cfg.Database.Name); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to register database metrics: %w", err)
+	}
+
+	return &storage{
+		users:       postgres.NewUserRepository(db),
+		teams:       postgres.NewTeamRepository(db),
+		prs:         postgres.NewPRRepository(db),
+		stats:       postgres.NewStatsRepository(db),
+		snapshots:   postgres.NewSnapshotRepository(db),
+		txManager:   postgres.NewTxManager(db),
+		idempotency: postgres.NewIdempotencyRepository(db),
+		healthChecks: []handlers.HealthCheck{
+			{Name: "database", Check: db.Ping},
+			{Name: "migrations", Check: func(ctx context.Context) error {
+				return db.CheckMigrationVersion(ctx, expectedVersion)
+			}},
+		},
+		db:    db,
+		close: db.Close,
+	}, nil
+}
+
+// withCache puts the read-through cache in front of the user and team
+// repositories. With postgres storage, invalidations reach the other replicas
+// over LISTEN/NOTIFY until ctx is cancelled.
+func (s *storage) withCache(ctx context.Context, cfg config.CacheConfig) {
+	if cfg.Backend == "none" {
+		return
+	}
+
+	c := cache.NewLRU(cfg.Size, cfg.TTL)
+
+	var invalidator *cache.Invalidator
+	if s.db != nil {
+		notifier := postgres.NewCacheNotifier(s.db)
+		invalidator = cache.NewInvalidator(c, notifier)
+		go notifier.Listen(ctx, invalidator.Apply)
+	} else {
+		invalidator = cache.NewInvalidator(c, nil)
+	}
+
+	users := s.users
+	s.users = cached.NewUserRepository(users, c, invalidator)
+	s.teams = cached.NewTeamRepository(s.teams, users, c, invalidator)
+	s.snapshots = cached.NewSnapshotRepository(s.snapshots, users, c, invalidator)
+	s.txManager = cached.NewTxManager(s.txManager, invalidator)
+}
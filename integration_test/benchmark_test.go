@@ -0,0 +1,101 @@
+package integration_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Raisondetr3/Avito-test-assignment/pkg/client"
+)
+
+// The benchmarks drive the test server through pkg/client, the same way the
+// load test does, so their ms/op compares directly with the per-request
+// latencies of the load test in the README.
+
+func BenchmarkCreateTeam(b *testing.B) {
+	ts := setupTestSuite(b)
+	defer ts.cleanup(b)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		members := make([]*client.TeamMember, 0, 5)
+		for j := 0; j < 5; j++ {
+			members = append(members, member(fmt.Sprintf("bench-%d-%d", i, j), fmt.Sprintf("BenchUser-%d-%d", i, j)))
+		}
+
+		_, err := ts.api.CreateTeam(ctx, client.CreateTeamRequest{
+			TeamName: fmt.Sprintf("bench-team-%d", i),
+			Members:  members,
+		})
+		if err != nil {
+			b.Fatalf("Failed to create team: %v", err)
+		}
+	}
+	reportMilliseconds(b)
+}
+
+func BenchmarkGetTeam(b *testing.B) {
+	ts := setupTestSuite(b)
+	defer ts.cleanup(b)
+	createBenchmarkTeam(b, ts)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ts.api.GetTeam(ctx, "bench-team"); err != nil {
+			b.Fatalf("Failed to get team: %v", err)
+		}
+	}
+	reportMilliseconds(b)
+}
+
+func BenchmarkCreatePR(b *testing.B) {
+	ts := setupTestSuite(b)
+	defer ts.cleanup(b)
+	createBenchmarkTeam(b, ts)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := ts.api.CreatePR(ctx, client.CreatePRRequest{
+			PullRequestID:   fmt.Sprintf("bench-pr-%d", i),
+			PullRequestName: "Benchmark",
+			AuthorID:        "bench-0",
+		})
+		if err != nil {
+			b.Fatalf("Failed to create PR: %v", err)
+		}
+	}
+	reportMilliseconds(b)
+}
+
+func BenchmarkGetPR(b *testing.B) {
+	ts := setupTestSuite(b)
+	defer ts.cleanup(b)
+	createBenchmarkTeam(b, ts)
+	ts.createPR(b, "bench-pr", "Benchmark", "bench-0")
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ts.api.GetPR(ctx, "bench-pr"); err != nil {
+			b.Fatalf("Failed to get PR: %v", err)
+		}
+	}
+	reportMilliseconds(b)
+}
+
+func createBenchmarkTeam(b *testing.B, ts *TestSuite) {
+	b.Helper()
+
+	members := make([]*client.TeamMember, 0, 5)
+	for j := 0; j < 5; j++ {
+		members = append(members, member(fmt.Sprintf("bench-%d", j), fmt.Sprintf("BenchUser-%d", j)))
+	}
+	ts.createTeam(b, "bench-team", members...)
+}
+
+func reportMilliseconds(b *testing.B) {
+	b.ReportMetric(float64(b.Elapsed().Microseconds())/1000/float64(b.N), "ms/op")
+}
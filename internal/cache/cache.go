@@ -0,0 +1,159 @@
+// Package cache holds the read-through cache that sits in front of the user
+// and team repositories.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Cache stores values by key. Implementations must be safe for concurrent
+// use. Values are shared, so callers copy them rather than modify them.
+type Cache interface {
+	Get(key string) (any, bool)
+	Set(key string, value any)
+	Delete(keys ...string)
+	Clear()
+}
+
+// Broadcaster tells the other replicas which keys to drop.
+type Broadcaster interface {
+	Publish(ctx context.Context, keys []string) error
+}
+
+// Invalidator drops keys from the local cache and, if a broadcaster is set,
+// from the caches of the other replicas.
+type Invalidator struct {
+	cache       Cache
+	broadcaster Broadcaster
+}
+
+func NewInvalidator(cache Cache, broadcaster Broadcaster) *Invalidator {
+	return &Invalidator{cache: cache, broadcaster: broadcaster}
+}
+
+// Invalidate never fails: a lost broadcast only leaves other replicas with
+// stale entries until they expire.
+func (i *Invalidator) Invalidate(ctx context.Context, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	i.cache.Delete(keys...)
+
+	if i.broadcaster == nil {
+		return
+	}
+	if err := i.broadcaster.Publish(ctx, keys); err != nil {
+		slog.WarnContext(ctx, "failed to broadcast cache invalidation", slog.String("error", err.Error()))
+	}
+}
+
+// Apply handles keys received from another replica. An empty list means the
+// sender could not say which keys changed, so everything is dropped.
+func (i *Invalidator) Apply(keys []string) {
+	if len(keys) == 0 {
+		i.cache.Clear()
+		return
+	}
+	i.cache.Delete(keys...)
+}
+
+// LRU keeps up to size entries in process memory, evicting the least recently
+// used one when full. Entries expire ttl after they were set, which bounds
+// how stale a value can get if an invalidation is missed.
+type LRU struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries map[string]*list.Element
+	order   *list.List
+	now     func() time.Time
+}
+
+type entry struct {
+	key     string
+	value   any
+	expires time.Time
+}
+
+func NewLRU(size int, ttl time.Duration) *LRU {
+	return &LRU{
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[string]*list.Element, size),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+func (c *LRU) Get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	e := elem.Value.(*entry)
+	if !c.now().Before(e.expires) {
+		c.remove(elem)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	return e.value, true
+}
+
+func (c *LRU) Set(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := c.now().Add(c.ttl)
+	if elem, ok := c.entries[key]; ok {
+		e := elem.Value.(*entry)
+		e.value = value
+		e.expires = expires
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&entry{key: key, value: value, expires: expires})
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *LRU) Delete(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := c.entries[key]; ok {
+			c.remove(elem)
+		}
+	}
+}
+
+func (c *LRU) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]*list.Element, c.size)
+	c.order.Init()
+}
+
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRU) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*entry).key)
+}
@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU(2, time.Minute)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	c.Set("c", 3)
+
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("Expected b to be evicted")
+	}
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("Expected a to be kept, got %v, %v", v, ok)
+	}
+	if c.Len() != 2 {
+		t.Fatalf("Expected 2 entries, got %d", c.Len())
+	}
+}
+
+func TestLRUExpiresEntries(t *testing.T) {
+	now := time.Now()
+	c := NewLRU(10, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1)
+
+	now = now.Add(59 * time.Second)
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("Expected a to be cached before the TTL")
+	}
+
+	now = now.Add(time.Second)
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("Expected a to expire after the TTL")
+	}
+	if c.Len() != 0 {
+		t.Fatalf("Expected the expired entry to be removed, got %d entries", c.Len())
+	}
+}
+
+type recordingBroadcaster struct {
+	published [][]string
+}
+
+func (b *recordingBroadcaster) Publish(_ context.Context, keys []string) error {
+	b.published = append(b.published, keys)
+	return nil
+}
+
+func TestInvalidator(t *testing.T) {
+	c := NewLRU(10, time.Minute)
+	broadcaster := &recordingBroadcaster{}
+	invalidator := NewInvalidator(c, broadcaster)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+
+	invalidator.Invalidate(context.Background(), "a")
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("Expected a to be invalidated")
+	}
+	if len(broadcaster.published) != 1 || broadcaster.published[0][0] != "a" {
+		t.Fatalf("Expected a to be broadcast, got %v", broadcaster.published)
+	}
+
+	invalidator.Invalidate(context.Background())
+	if len(broadcaster.published) != 1 {
+		t.Fatal("Expected nothing to be broadcast without keys")
+	}
+
+	invalidator.Apply([]string{"b"})
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("Expected b to be dropped by a remote invalidation")
+	}
+
+	invalidator.Apply(nil)
+	if c.Len() != 0 {
+		t.Fatalf("Expected an empty remote invalidation to clear the cache, got %d entries", c.Len())
+	}
+}
@@ -1,51 +1,489 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
 )
 
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
+	// Storage selects the repository backend: postgres, sqlite, or memory
+	// for tests and demos.
+	Storage     string            `yaml:"storage"`
+	Database    DatabaseConfig    `yaml:"database"`
+	SQLite      SQLiteConfig      `yaml:"sqlite"`
+	Server      ServerConfig      `yaml:"server"`
+	Log         LogConfig         `yaml:"log"`
+	Tracing     TracingConfig     `yaml:"tracing"`
+	Assignment  AssignmentConfig  `yaml:"assignment"`
+	RateLimit   RateLimitConfig   `yaml:"rate_limit"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	Cache       CacheConfig       `yaml:"cache"`
+	Archive     ArchiveConfig     `yaml:"archive"`
+	Directory   DirectoryConfig   `yaml:"directory"`
 }
 
 type DatabaseConfig struct {
-	Host     string
-	Port     int
-	User     string
-	Password string
-	Name     string
+	Host         string `yaml:"host"`
+	Port         int    `yaml:"port"`
+	User         string `yaml:"user"`
+	Password     string `yaml:"password"`
+	Name         string `yaml:"name"`
+	MaxOpenConns int    `yaml:"max_open_conns"`
+	// MinConns is the number of connections the pool keeps open while idle.
+	MinConns          int           `yaml:"min_conns"`
+	ConnMaxLifetime   time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime   time.Duration `yaml:"conn_max_idle_time"`
+	HealthCheckPeriod time.Duration `yaml:"health_check_period"`
+}
+
+type SQLiteConfig struct {
+	Path string `yaml:"path"`
 }
 
 type ServerConfig struct {
-	Port int
+	Port               int           `yaml:"port"`
+	ReadTimeout        time.Duration `yaml:"read_timeout"`
+	WriteTimeout       time.Duration `yaml:"write_timeout"`
+	IdleTimeout        time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout    time.Duration `yaml:"shutdown_timeout"`
+	ShutdownDrainDelay time.Duration `yaml:"shutdown_drain_delay"`
+	ReadinessTimeout   time.Duration `yaml:"readiness_timeout"`
+	TLS                TLSConfig     `yaml:"tls"`
 }
 
-func Load() (*Config, error) {
-	dbPort, err := strconv.Atoi(getEnvOrDefault("DB_PORT", "5432"))
-	if err != nil {
-		return nil, fmt.Errorf("invalid DB_PORT: %w", err)
-	}
+// TLSConfig enables HTTPS when CertFile is set. Setting ClientCAFile
+// additionally requires clients to present a certificate signed by that CA.
+type TLSConfig struct {
+	CertFile       string        `yaml:"cert_file"`
+	KeyFile        string        `yaml:"key_file"`
+	ClientCAFile   string        `yaml:"client_ca_file"`
+	MinVersion     string        `yaml:"min_version"`
+	ReloadInterval time.Duration `yaml:"reload_interval"`
+}
 
-	serverPort, err := strconv.Atoi(getEnvOrDefault("SERVER_PORT", "8080"))
-	if err != nil {
-		return nil, fmt.Errorf("invalid SERVER_PORT: %w", err)
-	}
+type LogConfig struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter"`
+	ServiceName string  `yaml:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+type AssignmentConfig struct {
+	ReviewersPerPR int `yaml:"reviewers_per_pr"`
+}
+
+// RateLimitConfig limits requests per caller. Routes are keyed by route
+// template, e.g. "/pullRequest/create", and override Default. Callers that
+// send one of APIKeys get their own buckets; everyone else is limited by
+// client certificate or IP.
+type RateLimitConfig struct {
+	Enabled bool                 `yaml:"enabled"`
+	Backend string               `yaml:"backend"`
+	Default RateLimit            `yaml:"default"`
+	Routes  map[string]RateLimit `yaml:"routes"`
+	APIKeys []string             `yaml:"api_keys"`
+}
+
+// IdempotencyConfig controls how long Idempotency-Key responses are kept and
+// how long an unfinished request holds its key.
+type IdempotencyConfig struct {
+	TTL         time.Duration `yaml:"ttl"`
+	LockTimeout time.Duration `yaml:"lock_timeout"`
+}
 
+// CacheConfig controls the read-through cache in front of user and team
+// lookups. Backend is lru or none.
+type CacheConfig struct {
+	Backend string        `yaml:"backend"`
+	Size    int           `yaml:"size"`
+	TTL     time.Duration `yaml:"ttl"`
+}
+
+// ArchiveConfig controls the job that moves merged pull requests older than
+// AfterDays to the archive tables. AfterDays 0 disables archival.
+type ArchiveConfig struct {
+	AfterDays int           `yaml:"after_days"`
+	Interval  time.Duration `yaml:"interval"`
+}
+
+// DirectoryConfig controls the job that mirrors team membership from an
+// LDAP directory. Each group matched by GroupFilter under BaseDN is a team,
+// its members are looked up among the entries matched by UserFilter. An
+// empty URL disables the sync.
+type DirectoryConfig struct {
+	URL          string              `yaml:"url"`
+	BindDN       string              `yaml:"bind_dn"`
+	BindPassword string              `yaml:"bind_password"`
+	BaseDN       string              `yaml:"base_dn"`
+	GroupFilter  string              `yaml:"group_filter"`
+	UserFilter   string              `yaml:"user_filter"`
+	Attributes   DirectoryAttributes `yaml:"attributes"`
+	Interval     time.Duration       `yaml:"interval"`
+	Timeout      time.Duration       `yaml:"timeout"`
+}
+
+// DirectoryAttributes maps LDAP attributes to teams and users. Member holds
+// either member DNs (groupOfNames) or user IDs (posixGroup's memberUid).
+type DirectoryAttributes struct {
+	Team     string `yaml:"team"`
+	Member   string `yaml:"member"`
+	UserID   string `yaml:"user_id"`
+	Username string `yaml:"username"`
+}
+
+// Enabled reports whether the directory sync is configured.
+func (c *DirectoryConfig) Enabled() bool {
+	return c.URL != ""
+}
+
+type RateLimit struct {
+	RequestsPerSecond float64 `yaml:"requests_per_second"`
+	Burst             int     `yaml:"burst"`
+}
+
+func Default() *Config {
 	return &Config{
+		Storage: "postgres",
 		Database: DatabaseConfig{
-			Host:     getEnvOrDefault("DB_HOST", "localhost"),
-			Port:     dbPort,
-			User:     getEnvOrDefault("DB_USER", "reviewer_user"),
-			Password: getEnvOrDefault("DB_PASSWORD", "reviewer_pass"),
-			Name:     getEnvOrDefault("DB_NAME", "pr_reviewer"),
+			Host:              "localhost",
+			Port:              5432,
+			User:              "reviewer_user",
+			Password:          "reviewer_pass",
+			Name:              "pr_reviewer",
+			MaxOpenConns:      25,
+			MinConns:          5,
+			ConnMaxLifetime:   time.Hour,
+			ConnMaxIdleTime:   30 * time.Minute,
+			HealthCheckPeriod: time.Minute,
+		},
+		SQLite: SQLiteConfig{
+			Path: "pr_reviewer.db",
 		},
 		Server: ServerConfig{
-			Port: serverPort,
+			Port:               8080,
+			ReadTimeout:        10 * time.Second,
+			WriteTimeout:       10 * time.Second,
+			IdleTimeout:        60 * time.Second,
+			ShutdownTimeout:    30 * time.Second,
+			ShutdownDrainDelay: 5 * time.Second,
+			ReadinessTimeout:   2 * time.Second,
+			TLS: TLSConfig{
+				MinVersion:     "1.2",
+				ReloadInterval: 30 * time.Second,
+			},
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			ServiceName: "pr-reviewer-service",
+			SampleRatio: 1,
+		},
+		Assignment: AssignmentConfig{
+			ReviewersPerPR: domain.MaxReviewers,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: false,
+			Backend: "memory",
+			Default: RateLimit{
+				RequestsPerSecond: 10,
+				Burst:             20,
+			},
+		},
+		Idempotency: IdempotencyConfig{
+			TTL:         24 * time.Hour,
+			LockTimeout: time.Minute,
 		},
-	}, nil
+		Cache: CacheConfig{
+			Backend: "lru",
+			Size:    10000,
+			TTL:     30 * time.Second,
+		},
+		Archive: ArchiveConfig{
+			AfterDays: 0,
+			Interval:  time.Hour,
+		},
+		Directory: DirectoryConfig{
+			GroupFilter: "(objectClass=groupOfNames)",
+			UserFilter:  "(objectClass=inetOrgPerson)",
+			Attributes: DirectoryAttributes{
+				Team:     "cn",
+				Member:   "member",
+				UserID:   "uid",
+				Username: "cn",
+			},
+			Interval: 15 * time.Minute,
+			Timeout:  30 * time.Second,
+		},
+	}
+}
+
+// Load builds the configuration from defaults, the optional YAML file named
+// by CONFIG_FILE and environment variables, in increasing order of priority.
+func Load() (*Config, error) {
+	cfg := Default()
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	file, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *Config) applyEnv() error {
+	var errs []error
+
+	overrideString(&c.Storage, "STORAGE")
+	overrideString(&c.SQLite.Path, "SQLITE_PATH")
+
+	overrideString(&c.Database.Host, "DB_HOST")
+	errs = append(errs, overrideInt(&c.Database.Port, "DB_PORT"))
+	overrideString(&c.Database.User, "DB_USER")
+	overrideString(&c.Database.Password, "DB_PASSWORD")
+	overrideString(&c.Database.Name, "DB_NAME")
+	errs = append(errs, overrideInt(&c.Database.MaxOpenConns, "DB_MAX_OPEN_CONNS"))
+	errs = append(errs, overrideInt(&c.Database.MinConns, "DB_MIN_CONNS"))
+	errs = append(errs, overrideDuration(&c.Database.ConnMaxLifetime, "DB_CONN_MAX_LIFETIME"))
+	errs = append(errs, overrideDuration(&c.Database.ConnMaxIdleTime, "DB_CONN_MAX_IDLE_TIME"))
+	errs = append(errs, overrideDuration(&c.Database.HealthCheckPeriod, "DB_HEALTH_CHECK_PERIOD"))
+
+	errs = append(errs, overrideInt(&c.Server.Port, "SERVER_PORT"))
+	errs = append(errs, overrideDuration(&c.Server.ReadTimeout, "SERVER_READ_TIMEOUT"))
+	errs = append(errs, overrideDuration(&c.Server.WriteTimeout, "SERVER_WRITE_TIMEOUT"))
+	errs = append(errs, overrideDuration(&c.Server.IdleTimeout, "SERVER_IDLE_TIMEOUT"))
+	errs = append(errs, overrideDuration(&c.Server.ShutdownTimeout, "SERVER_SHUTDOWN_TIMEOUT"))
+	errs = append(errs, overrideDuration(&c.Server.ShutdownDrainDelay, "SHUTDOWN_DRAIN_DELAY"))
+	errs = append(errs, overrideDuration(&c.Server.ReadinessTimeout, "READINESS_TIMEOUT"))
+
+	overrideString(&c.Server.TLS.CertFile, "TLS_CERT_FILE")
+	overrideString(&c.Server.TLS.KeyFile, "TLS_KEY_FILE")
+	overrideString(&c.Server.TLS.ClientCAFile, "TLS_CLIENT_CA_FILE")
+	overrideString(&c.Server.TLS.MinVersion, "TLS_MIN_VERSION")
+	errs = append(errs, overrideDuration(&c.Server.TLS.ReloadInterval, "TLS_RELOAD_INTERVAL"))
+
+	overrideString(&c.Log.Level, "LOG_LEVEL")
+	overrideString(&c.Log.Format, "LOG_FORMAT")
+
+	overrideString(&c.Tracing.Exporter, "TRACING_EXPORTER")
+	overrideString(&c.Tracing.ServiceName, "TRACING_SERVICE_NAME")
+	errs = append(errs, overrideFloat(&c.Tracing.SampleRatio, "TRACING_SAMPLE_RATIO"))
+
+	errs = append(errs, overrideInt(&c.Assignment.ReviewersPerPR, "REVIEWERS_PER_PR"))
+
+	errs = append(errs, overrideBool(&c.RateLimit.Enabled, "RATE_LIMIT_ENABLED"))
+	overrideString(&c.RateLimit.Backend, "RATE_LIMIT_BACKEND")
+	errs = append(errs, overrideFloat(&c.RateLimit.Default.RequestsPerSecond, "RATE_LIMIT_RPS"))
+	errs = append(errs, overrideInt(&c.RateLimit.Default.Burst, "RATE_LIMIT_BURST"))
+	overrideStrings(&c.RateLimit.APIKeys, "RATE_LIMIT_API_KEYS")
+
+	errs = append(errs, overrideDuration(&c.Idempotency.TTL, "IDEMPOTENCY_TTL"))
+	errs = append(errs, overrideDuration(&c.Idempotency.LockTimeout, "IDEMPOTENCY_LOCK_TIMEOUT"))
+	overrideString(&c.Cache.Backend, "CACHE_BACKEND")
+	errs = append(errs, overrideInt(&c.Cache.Size, "CACHE_SIZE"))
+	errs = append(errs, overrideDuration(&c.Cache.TTL, "CACHE_TTL"))
+	errs = append(errs, overrideInt(&c.Archive.AfterDays, "ARCHIVE_AFTER_DAYS"))
+	errs = append(errs, overrideDuration(&c.Archive.Interval, "ARCHIVE_INTERVAL"))
+
+	overrideString(&c.Directory.URL, "LDAP_URL")
+	overrideString(&c.Directory.BindDN, "LDAP_BIND_DN")
+	overrideString(&c.Directory.BindPassword, "LDAP_BIND_PASSWORD")
+	overrideString(&c.Directory.BaseDN, "LDAP_BASE_DN")
+	overrideString(&c.Directory.GroupFilter, "LDAP_GROUP_FILTER")
+	overrideString(&c.Directory.UserFilter, "LDAP_USER_FILTER")
+	overrideString(&c.Directory.Attributes.Team, "LDAP_TEAM_ATTRIBUTE")
+	overrideString(&c.Directory.Attributes.Member, "LDAP_MEMBER_ATTRIBUTE")
+	overrideString(&c.Directory.Attributes.UserID, "LDAP_USER_ID_ATTRIBUTE")
+	overrideString(&c.Directory.Attributes.Username, "LDAP_USERNAME_ATTRIBUTE")
+	errs = append(errs, overrideDuration(&c.Directory.Interval, "LDAP_SYNC_INTERVAL"))
+	errs = append(errs, overrideDuration(&c.Directory.Timeout, "LDAP_TIMEOUT"))
+
+	return errors.Join(errs...)
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, field, message string) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", field, message))
+		}
+	}
+
+	check(isOneOf(c.Storage, "postgres", "sqlite", "memory"), "storage", "must be postgres, sqlite or memory")
+	check(c.Storage != "sqlite" || c.SQLite.Path != "", "sqlite.path", "must not be empty")
+
+	check(c.Database.Host != "", "database.host", "must not be empty")
+	check(c.Database.Port > 0 && c.Database.Port <= 65535, "database.port", "must be between 1 and 65535")
+	check(c.Database.User != "", "database.user", "must not be empty")
+	check(c.Database.Name != "", "database.name", "must not be empty")
+	check(c.Database.MaxOpenConns > 0, "database.max_open_conns", "must be positive")
+	check(c.Database.MinConns >= 0, "database.min_conns", "must not be negative")
+	check(c.Database.MinConns <= c.Database.MaxOpenConns, "database.min_conns", "must not exceed database.max_open_conns")
+	check(c.Database.ConnMaxLifetime >= 0, "database.conn_max_lifetime", "must not be negative")
+	check(c.Database.ConnMaxIdleTime >= 0, "database.conn_max_idle_time", "must not be negative")
+	check(c.Database.HealthCheckPeriod >= 0, "database.health_check_period", "must not be negative")
+
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port", "must be between 1 and 65535")
+	check(c.Server.ReadTimeout > 0, "server.read_timeout", "must be positive")
+	check(c.Server.WriteTimeout > 0, "server.write_timeout", "must be positive")
+	check(c.Server.IdleTimeout > 0, "server.idle_timeout", "must be positive")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout", "must be positive")
+	check(c.Server.ShutdownDrainDelay >= 0, "server.shutdown_drain_delay", "must not be negative")
+	check(c.Server.ReadinessTimeout > 0, "server.readiness_timeout", "must be positive")
+	check((c.Server.TLS.CertFile == "") == (c.Server.TLS.KeyFile == ""), "server.tls", "cert_file and key_file must be set together")
+	check(c.Server.TLS.ClientCAFile == "" || c.Server.TLS.Enabled(), "server.tls.client_ca_file", "requires cert_file and key_file")
+	check(isOneOf(c.Server.TLS.MinVersion, "1.2", "1.3"), "server.tls.min_version", "must be 1.2 or 1.3")
+	check(c.Server.TLS.ReloadInterval > 0, "server.tls.reload_interval", "must be positive")
+
+	check(isOneOf(strings.ToLower(c.Log.Level), "debug", "info", "warn", "warning", "error"), "log.level", "must be debug, info, warn or error")
+	check(isOneOf(c.Log.Format, "json", "text"), "log.format", "must be json or text")
+
+	check(isOneOf(c.Tracing.Exporter, "none", "otlp", "stdout"), "tracing.exporter", "must be none, otlp or stdout")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio", "must be between 0 and 1")
+
+	check(c.Assignment.ReviewersPerPR >= 1 && c.Assignment.ReviewersPerPR <= domain.MaxReviewers,
+		"assignment.reviewers_per_pr", fmt.Sprintf("must be between 1 and %d", domain.MaxReviewers))
+
+	check(isOneOf(c.RateLimit.Backend, "memory", "postgres"), "rate_limit.backend", "must be memory or postgres")
+	check(c.RateLimit.Backend != "postgres" || c.Storage == "postgres", "rate_limit.backend", "postgres requires postgres storage")
+	errs = append(errs, validateRateLimit("rate_limit.default", c.RateLimit.Default)...)
+	for route, limit := range c.RateLimit.Routes {
+		field := fmt.Sprintf("rate_limit.routes[%s]", route)
+		check(strings.HasPrefix(route, "/"), field, "must be a route template starting with /")
+		errs = append(errs, validateRateLimit(field, limit)...)
+	}
+	for i, key := range c.RateLimit.APIKeys {
+		check(key != "", fmt.Sprintf("rate_limit.api_keys[%d]", i), "must not be empty")
+	}
+
+	check(c.Idempotency.TTL > 0, "idempotency.ttl", "must be positive")
+	check(c.Idempotency.LockTimeout > 0, "idempotency.lock_timeout", "must be positive")
+	check(c.Idempotency.LockTimeout < c.Idempotency.TTL, "idempotency.lock_timeout", "must be less than idempotency.ttl")
+	check(isOneOf(c.Cache.Backend, "lru", "none"), "cache.backend", "must be lru or none")
+	check(c.Cache.Size > 0, "cache.size", "must be positive")
+	check(c.Cache.TTL > 0, "cache.ttl", "must be positive")
+	check(c.Archive.AfterDays >= 0, "archive.after_days", "must not be negative")
+	check(c.Archive.Interval > 0, "archive.interval", "must be positive")
+
+	if c.Directory.Enabled() {
+		check(strings.HasPrefix(c.Directory.URL, "ldap://") || strings.HasPrefix(c.Directory.URL, "ldaps://"),
+			"directory.url", "must start with ldap:// or ldaps://")
+		check(c.Directory.BaseDN != "", "directory.base_dn", "must not be empty")
+		check(c.Directory.GroupFilter != "", "directory.group_filter", "must not be empty")
+		check(c.Directory.UserFilter != "", "directory.user_filter", "must not be empty")
+		check(c.Directory.Attributes.Team != "", "directory.attributes.team", "must not be empty")
+		check(c.Directory.Attributes.Member != "", "directory.attributes.member", "must not be empty")
+		check(c.Directory.Attributes.UserID != "", "directory.attributes.user_id", "must not be empty")
+		check(c.Directory.Attributes.Username != "", "directory.attributes.username", "must not be empty")
+		check(c.Directory.Interval > 0, "directory.interval", "must be positive")
+		check(c.Directory.Timeout > 0, "directory.timeout", "must be positive")
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// Reloaded returns a copy of c with the values that are safe to change at
+// runtime taken from next, along with the sections that differ but only
+// take effect after a restart.
+func (c *Config) Reloaded(next *Config) (*Config, []string) {
+	reloaded := *c
+	reloaded.Log.Level = next.Log.Level
+	reloaded.Assignment = next.Assignment
+	reloaded.RateLimit.Default = next.RateLimit.Default
+	reloaded.RateLimit.Routes = next.RateLimit.Routes
+	reloaded.RateLimit.APIKeys = next.RateLimit.APIKeys
+
+	var restartRequired []string
+	if c.Storage != next.Storage || c.SQLite != next.SQLite {
+		restartRequired = append(restartRequired, "storage")
+	}
+	if c.Database != next.Database {
+		restartRequired = append(restartRequired, "database")
+	}
+	if c.Server != next.Server {
+		restartRequired = append(restartRequired, "server")
+	}
+	if c.Log.Format != next.Log.Format {
+		restartRequired = append(restartRequired, "log.format")
+	}
+	if c.Tracing != next.Tracing {
+		restartRequired = append(restartRequired, "tracing")
+	}
+	if c.RateLimit.Enabled != next.RateLimit.Enabled || c.RateLimit.Backend != next.RateLimit.Backend {
+		restartRequired = append(restartRequired, "rate_limit")
+	}
+	if c.Idempotency != next.Idempotency {
+		restartRequired = append(restartRequired, "idempotency")
+	}
+	if c.Cache != next.Cache {
+		restartRequired = append(restartRequired, "cache")
+	}
+	if c.Archive != next.Archive {
+		restartRequired = append(restartRequired, "archive")
+	}
+	if c.Directory != next.Directory {
+		restartRequired = append(restartRequired, "directory")
+	}
+
+	return &reloaded, restartRequired
+}
+
+func validateRateLimit(field string, limit RateLimit) []error {
+	var errs []error
+	if limit.RequestsPerSecond < 0 {
+		errs = append(errs, fmt.Errorf("%s.requests_per_second: must not be negative", field))
+	}
+	if limit.RequestsPerSecond > 0 && limit.Burst < 1 {
+		errs = append(errs, fmt.Errorf("%s.burst: must be at least 1", field))
+	}
+	return errs
+}
+
+func (c *TLSConfig) Enabled() bool {
+	return c.CertFile != ""
 }
 
 func (c *DatabaseConfig) DSN() string {
@@ -59,9 +497,93 @@ func (c *DatabaseConfig) DSN() string {
 	)
 }
 
-func getEnvOrDefault(key, defaultValue string) string {
+func isOneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
+
+func overrideString(target *string, key string) {
 	if value := os.Getenv(key); value != "" {
-		return value
+		*target = value
+	}
+}
+
+// overrideStrings reads a comma-separated list, skipping empty items.
+func overrideStrings(target *[]string, key string) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*target = items
+}
+
+func overrideInt(target *int, key string) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	*target = parsed
+	return nil
+}
+
+func overrideBool(target *bool, key string) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
 	}
-	return defaultValue
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	*target = parsed
+	return nil
+}
+
+func overrideFloat(target *float64, key string) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	*target = parsed
+	return nil
+}
+
+func overrideDuration(target *time.Duration, key string) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	*target = parsed
+	return nil
 }
@@ -0,0 +1,240 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(c *Config)
+		field  string
+	}{
+		{name: "defaults", mutate: func(c *Config) {}},
+		{name: "unknown storage", mutate: func(c *Config) { c.Storage = "mysql" }, field: "storage"},
+		{
+			name:   "sqlite without a path",
+			mutate: func(c *Config) { c.Storage = "sqlite"; c.SQLite.Path = "" },
+			field:  "sqlite.path",
+		},
+		{name: "zero pool size", mutate: func(c *Config) { c.Database.MaxOpenConns = 0 }, field: "database.max_open_conns"},
+		{name: "negative min conns", mutate: func(c *Config) { c.Database.MinConns = -1 }, field: "database.min_conns"},
+		{
+			name:   "min conns over the pool size",
+			mutate: func(c *Config) { c.Database.MinConns = c.Database.MaxOpenConns + 1 },
+			field:  "database.min_conns",
+		},
+		{
+			name:   "negative connection lifetime",
+			mutate: func(c *Config) { c.Database.ConnMaxLifetime = -time.Second },
+			field:  "database.conn_max_lifetime",
+		},
+		{
+			name:   "negative rate",
+			mutate: func(c *Config) { c.RateLimit.Default.RequestsPerSecond = -1 },
+			field:  "rate_limit.default.requests_per_second",
+		},
+		{
+			name:   "rate without a burst",
+			mutate: func(c *Config) { c.RateLimit.Default.Burst = 0 },
+			field:  "rate_limit.default.burst",
+		},
+		{
+			name: "route that is not a template",
+			mutate: func(c *Config) {
+				c.RateLimit.Routes = map[string]RateLimit{"team/add": {RequestsPerSecond: 1, Burst: 1}}
+			},
+			field: "rate_limit.routes[team/add]",
+		},
+		{
+			name:   "postgres rate limiter on sqlite",
+			mutate: func(c *Config) { c.Storage = "sqlite"; c.RateLimit.Backend = "postgres" },
+			field:  "rate_limit.backend",
+		},
+		{name: "empty API key", mutate: func(c *Config) { c.RateLimit.APIKeys = []string{""} }, field: "rate_limit.api_keys[0]"},
+		{name: "TLS cert without a key", mutate: func(c *Config) { c.Server.TLS.CertFile = "cert.pem" }, field: "server.tls"},
+		{name: "TLS key without a cert", mutate: func(c *Config) { c.Server.TLS.KeyFile = "key.pem" }, field: "server.tls"},
+		{
+			name:   "client CA without TLS",
+			mutate: func(c *Config) { c.Server.TLS.ClientCAFile = "ca.pem" },
+			field:  "server.tls.client_ca_file",
+		},
+		{
+			name: "TLS fully configured",
+			mutate: func(c *Config) {
+				c.Server.TLS.CertFile = "cert.pem"
+				c.Server.TLS.KeyFile = "key.pem"
+				c.Server.TLS.ClientCAFile = "ca.pem"
+			},
+		},
+		{
+			name:   "lock timeout over the TTL",
+			mutate: func(c *Config) { c.Idempotency.LockTimeout = c.Idempotency.TTL },
+			field:  "idempotency.lock_timeout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.mutate(cfg)
+
+			err := cfg.Validate()
+			switch {
+			case tt.field == "" && err != nil:
+				t.Fatalf("Expected a valid config, got %v", err)
+			case tt.field != "" && err == nil:
+				t.Fatalf("Expected an error for %s, got nil", tt.field)
+			case tt.field != "" && !strings.Contains(err.Error(), tt.field+":"):
+				t.Fatalf("Expected an error for %s, got %v", tt.field, err)
+			}
+		})
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		bad   bool
+	}{
+		{name: "int", key: "DB_PORT", value: "5433"},
+		{name: "bad int", key: "DB_PORT", value: "five", bad: true},
+		{name: "bad bool", key: "RATE_LIMIT_ENABLED", value: "maybe", bad: true},
+		{name: "bad float", key: "TRACING_SAMPLE_RATIO", value: "half", bad: true},
+		{name: "bad duration", key: "CACHE_TTL", value: "30", bad: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(tt.key, tt.value)
+
+			cfg := Default()
+			err := cfg.applyEnv()
+			if !tt.bad {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "invalid "+tt.key) {
+				t.Fatalf("Expected an error naming %s, got %v", tt.key, err)
+			}
+		})
+	}
+
+	t.Run("comma-separated list", func(t *testing.T) {
+		t.Setenv("RATE_LIMIT_API_KEYS", " a, ,b ")
+
+		cfg := Default()
+		if err := cfg.applyEnv(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(cfg.RateLimit.APIKeys, []string{"a", "b"}) {
+			t.Fatalf("Expected [a b], got %v", cfg.RateLimit.APIKeys)
+		}
+	})
+}
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "known keys", content: "server:\n  port: 9090\n"},
+		{name: "unknown key", content: "server:\n  prot: 9090\n", wantErr: "prot"},
+		{name: "unknown section", content: "servers:\n  port: 9090\n", wantErr: "servers"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+
+			cfg := Default()
+			err := cfg.loadFile(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if cfg.Server.Port != 9090 {
+					t.Fatalf("Expected port 9090, got %d", cfg.Server.Port)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Expected an error naming %s, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestReloaded(t *testing.T) {
+	tests := []struct {
+		name            string
+		mutate          func(c *Config)
+		restartRequired []string
+	}{
+		{name: "no changes", mutate: func(c *Config) {}},
+		{name: "log level", mutate: func(c *Config) { c.Log.Level = "debug" }},
+		{name: "reviewers per PR", mutate: func(c *Config) { c.Assignment.ReviewersPerPR = 1 }},
+		{
+			name: "rate limits",
+			mutate: func(c *Config) {
+				c.RateLimit.Default = RateLimit{RequestsPerSecond: 1, Burst: 2}
+				c.RateLimit.Routes = map[string]RateLimit{"/team/add": {RequestsPerSecond: 3, Burst: 4}}
+				c.RateLimit.APIKeys = []string{"key"}
+			},
+		},
+		{name: "rate limiter switched on", mutate: func(c *Config) { c.RateLimit.Enabled = true }, restartRequired: []string{"rate_limit"}},
+		{name: "storage", mutate: func(c *Config) { c.Storage = "memory" }, restartRequired: []string{"storage"}},
+		{name: "sqlite path", mutate: func(c *Config) { c.SQLite.Path = "other.db" }, restartRequired: []string{"storage"}},
+		{name: "database", mutate: func(c *Config) { c.Database.MaxOpenConns = 50 }, restartRequired: []string{"database"}},
+		{name: "server TLS", mutate: func(c *Config) { c.Server.TLS.MinVersion = "1.3" }, restartRequired: []string{"server"}},
+		{name: "log format", mutate: func(c *Config) { c.Log.Format = "text" }, restartRequired: []string{"log.format"}},
+		{
+			name: "several sections",
+			mutate: func(c *Config) {
+				c.Log.Level = "debug"
+				c.Tracing.Exporter = "stdout"
+				c.Idempotency.TTL = time.Hour
+				c.Cache.Size = 10
+				c.Archive.AfterDays = 30
+				c.Directory.URL = "ldap://ldap"
+			},
+			restartRequired: []string{"tracing", "idempotency", "cache", "archive", "directory"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := Default()
+			next := Default()
+			tt.mutate(next)
+
+			reloaded, restartRequired := current.Reloaded(next)
+			if !reflect.DeepEqual(restartRequired, tt.restartRequired) {
+				t.Fatalf("Expected restart-required sections %v, got %v", tt.restartRequired, restartRequired)
+			}
+
+			want := *current
+			want.Log.Level = next.Log.Level
+			want.Assignment = next.Assignment
+			want.RateLimit.Default = next.RateLimit.Default
+			want.RateLimit.Routes = next.RateLimit.Routes
+			want.RateLimit.APIKeys = next.RateLimit.APIKeys
+			if !reflect.DeepEqual(*reloaded, want) {
+				t.Fatalf("Expected only the hot-reloadable values to change, got %+v", reloaded)
+			}
+		})
+	}
+}
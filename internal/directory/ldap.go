@@ -0,0 +1,179 @@
+package directory
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/config"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+)
+
+// pageSize is how many entries the server returns per search request.
+const pageSize = 500
+
+// LDAPSource reads teams and their members from an LDAP directory.
+type LDAPSource struct {
+	cfg config.DirectoryConfig
+}
+
+func NewLDAPSource(cfg config.DirectoryConfig) *LDAPSource {
+	return &LDAPSource{cfg: cfg}
+}
+
+// Groups returns the groups under the base DN sorted by team name. Members
+// are matched against the user entries by DN or, failing that, by user ID;
+// members that match no user are skipped. A user listed in several groups
+// joins the first one.
+func (s *LDAPSource) Groups(ctx context.Context) ([]domain.DirectoryGroup, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	conn, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// The client has no context support, so closing the connection is what
+	// interrupts a request in flight.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	groups, err := s.readGroups(conn)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, fmt.Errorf("ldap sync interrupted: %w", ctxErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+func (s *LDAPSource) connect() (*ldap.Conn, error) {
+	conn, err := ldap.DialURL(s.cfg.URL, ldap.DialWithDialer(&net.Dialer{Timeout: s.cfg.Timeout}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", s.cfg.URL, err)
+	}
+	conn.SetTimeout(s.cfg.Timeout)
+
+	if s.cfg.BindDN != "" {
+		if err := conn.Bind(s.cfg.BindDN, s.cfg.BindPassword); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to bind as %s: %w", s.cfg.BindDN, err)
+		}
+	}
+
+	return conn, nil
+}
+
+func (s *LDAPSource) readGroups(conn *ldap.Conn) ([]domain.DirectoryGroup, error) {
+	attrs := s.cfg.Attributes
+
+	userEntries, err := s.search(conn, s.cfg.UserFilter, attrs.UserID, attrs.Username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
+	}
+
+	byDN := make(map[string]domain.DirectoryMember, len(userEntries))
+	byID := make(map[string]domain.DirectoryMember, len(userEntries))
+	for _, entry := range userEntries {
+		member := domain.DirectoryMember{
+			UserID:   entry.GetEqualFoldAttributeValue(attrs.UserID),
+			Username: entry.GetEqualFoldAttributeValue(attrs.Username),
+		}
+		if member.UserID == "" || member.Username == "" {
+			slog.Warn("skipping directory user without id or username", slog.String("dn", entry.DN))
+			continue
+		}
+		byDN[normalizeDN(entry.DN)] = member
+		byID[member.UserID] = member
+	}
+
+	groupEntries, err := s.search(conn, s.cfg.GroupFilter, attrs.Team, attrs.Member)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search groups: %w", err)
+	}
+
+	groups := make([]domain.DirectoryGroup, 0, len(groupEntries))
+	values := make(map[string][]string, len(groupEntries))
+	for _, entry := range groupEntries {
+		teamName := entry.GetEqualFoldAttributeValue(attrs.Team)
+		if teamName == "" {
+			slog.Warn("skipping directory group without team name", slog.String("dn", entry.DN))
+			continue
+		}
+		if _, ok := values[teamName]; ok {
+			slog.Warn("skipping duplicate directory group", slog.String("dn", entry.DN), slog.String("team", teamName))
+			continue
+		}
+		groups = append(groups, domain.DirectoryGroup{TeamName: teamName, Members: []domain.DirectoryMember{}})
+		values[teamName] = entry.GetEqualFoldAttributeValues(attrs.Member)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].TeamName < groups[j].TeamName })
+
+	assigned := make(map[string]string)
+	for i := range groups {
+		group := &groups[i]
+		for _, value := range values[group.TeamName] {
+			member, ok := byDN[normalizeDN(value)]
+			if !ok {
+				member, ok = byID[value]
+			}
+			if !ok {
+				slog.Warn("skipping unknown directory group member",
+					slog.String("team", group.TeamName), slog.String("member", value))
+				continue
+			}
+			if teamName, ok := assigned[member.UserID]; ok {
+				if teamName != group.TeamName {
+					slog.Warn("directory user belongs to several groups",
+						slog.String("user_id", member.UserID),
+						slog.String("team", teamName),
+						slog.String("ignored_team", group.TeamName),
+					)
+				}
+				continue
+			}
+			assigned[member.UserID] = group.TeamName
+			group.Members = append(group.Members, member)
+		}
+		sort.Slice(group.Members, func(i, j int) bool { return group.Members[i].UserID < group.Members[j].UserID })
+	}
+
+	return groups, nil
+}
+
+func (s *LDAPSource) search(conn *ldap.Conn, filter string, attributes ...string) ([]*ldap.Entry, error) {
+	req := ldap.NewSearchRequest(
+		s.cfg.BaseDN,
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		0, 0, false,
+		filter,
+		attributes,
+		nil,
+	)
+
+	result, err := conn.SearchWithPaging(req, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Entries, nil
+}
+
+// normalizeDN makes DNs that differ only in case or spacing compare equal.
+func normalizeDN(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil {
+		return strings.ToLower(dn)
+	}
+	return strings.ToLower(parsed.String())
+}
@@ -0,0 +1,259 @@
+package directory
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/config"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+)
+
+type stubEntry struct {
+	dn    string
+	attrs map[string][]string
+}
+
+// stubServer answers binds and searches with a fixed set of entries. It only
+// understands equality filters, which is all the default configuration uses.
+type stubServer struct {
+	t        *testing.T
+	listener net.Listener
+	bindDN   string
+	password string
+	entries  []stubEntry
+}
+
+func newStubServer(t *testing.T, entries []stubEntry) *stubServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	s := &stubServer{
+		t:        t,
+		listener: listener,
+		bindDN:   "cn=sync,dc=example,dc=com",
+		password: "secret",
+		entries:  entries,
+	}
+	go s.serve()
+	return s
+}
+
+func (s *stubServer) url() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+func (s *stubServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *stubServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		messageID := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			code := ldap.LDAPResultSuccess
+			if op.Children[1].Value.(string) != s.bindDN || op.Children[2].Data.String() != s.password {
+				code = ldap.LDAPResultInvalidCredentials
+			}
+			s.write(conn, messageID, result(ldap.ApplicationBindResponse, code))
+		case ldap.ApplicationSearchRequest:
+			filter, err := ldap.DecompileFilter(op.Children[6])
+			if err != nil {
+				s.t.Errorf("Failed to decode filter: %v", err)
+				return
+			}
+			for _, entry := range s.entries {
+				if matches(entry, filter) {
+					s.write(conn, messageID, searchEntry(entry))
+				}
+			}
+			s.write(conn, messageID, result(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess))
+		default:
+			return
+		}
+	}
+}
+
+func (s *stubServer) write(conn net.Conn, messageID int64, op *ber.Packet) {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "MessageID"))
+	packet.AppendChild(op)
+	conn.Write(packet.Bytes())
+}
+
+func result(tag ber.Tag, code int) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "Result Code"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+	return op
+}
+
+func searchEntry(entry stubEntry) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.dn, "DN"))
+
+	attrs := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for name, values := range entry.attrs {
+		attr := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		attr.AppendChild(set)
+		attrs.AppendChild(attr)
+	}
+	op.AppendChild(attrs)
+	return op
+}
+
+func matches(entry stubEntry, filter string) bool {
+	name, value, ok := strings.Cut(strings.Trim(filter, "()"), "=")
+	if !ok {
+		return false
+	}
+	for _, v := range entry.attrs[name] {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func person(uid, name string) stubEntry {
+	return stubEntry{
+		dn: "uid=" + uid + ",ou=people,dc=example,dc=com",
+		attrs: map[string][]string{
+			"objectClass": {"inetOrgPerson"},
+			"uid":         {uid},
+			"cn":          {name},
+		},
+	}
+}
+
+func group(name string, members ...string) stubEntry {
+	return stubEntry{
+		dn: "cn=" + name + ",ou=groups,dc=example,dc=com",
+		attrs: map[string][]string{
+			"objectClass": {"groupOfNames"},
+			"cn":          {name},
+			"member":      members,
+		},
+	}
+}
+
+func newTestConfig(server *stubServer) config.DirectoryConfig {
+	cfg := config.Default().Directory
+	cfg.URL = server.url()
+	cfg.BindDN = server.bindDN
+	cfg.BindPassword = server.password
+	cfg.BaseDN = "dc=example,dc=com"
+	cfg.Timeout = 5 * time.Second
+	return cfg
+}
+
+func TestLDAPSourceGroups(t *testing.T) {
+	server := newStubServer(t, []stubEntry{
+		person("alice", "Alice"),
+		person("bob", "Bob"),
+		person("carol", "Carol"),
+		group("frontend",
+			"uid=bob,ou=people,dc=example,dc=com",
+			"uid=carol,ou=people,dc=example,dc=com",
+			"uid=ghost,ou=people,dc=example,dc=com",
+		),
+		group("backend",
+			"uid=alice,ou=people,dc=example,dc=com",
+			"UID=Bob, OU=People, DC=example, DC=com",
+		),
+		group("empty"),
+	})
+
+	groups, err := NewLDAPSource(newTestConfig(server)).Groups(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []domain.DirectoryGroup{
+		{TeamName: "backend", Members: []domain.DirectoryMember{{UserID: "alice", Username: "Alice"}, {UserID: "bob", Username: "Bob"}}},
+		{TeamName: "empty", Members: []domain.DirectoryMember{}},
+		// bob already joined backend and ghost has no user entry.
+		{TeamName: "frontend", Members: []domain.DirectoryMember{{UserID: "carol", Username: "Carol"}}},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("Expected %d groups, got %+v", len(want), groups)
+	}
+	for i := range want {
+		if groups[i].TeamName != want[i].TeamName || len(groups[i].Members) != len(want[i].Members) {
+			t.Fatalf("Expected %+v, got %+v", want[i], groups[i])
+		}
+		for j := range want[i].Members {
+			if groups[i].Members[j] != want[i].Members[j] {
+				t.Fatalf("Expected %+v, got %+v", want[i], groups[i])
+			}
+		}
+	}
+}
+
+func TestLDAPSourceResolvesMemberUIDs(t *testing.T) {
+	server := newStubServer(t, []stubEntry{
+		person("alice", "Alice"),
+		{
+			dn: "cn=backend,ou=groups,dc=example,dc=com",
+			attrs: map[string][]string{
+				"objectClass": {"posixGroup"},
+				"cn":          {"backend"},
+				"memberUid":   {"alice"},
+			},
+		},
+	})
+	cfg := newTestConfig(server)
+	cfg.GroupFilter = "(objectClass=posixGroup)"
+	cfg.Attributes.Member = "memberUid"
+
+	groups, err := NewLDAPSource(cfg).Groups(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(groups) != 1 || len(groups[0].Members) != 1 || groups[0].Members[0].UserID != "alice" {
+		t.Fatalf("Expected alice in backend, got %+v", groups)
+	}
+}
+
+func TestLDAPSourceRejectsInvalidCredentials(t *testing.T) {
+	server := newStubServer(t, []stubEntry{person("alice", "Alice")})
+	cfg := newTestConfig(server)
+	cfg.BindPassword = "wrong"
+
+	_, err := NewLDAPSource(cfg).Groups(context.Background())
+	if !ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		t.Fatalf("Expected invalid credentials, got %v", err)
+	}
+}
@@ -1,5 +1,11 @@
 package domain
 
+type BulkDeactivationOptions struct {
+	// BestEffort commits each reassignment separately and reports failures
+	// as skipped PRs instead of rolling back the whole operation.
+	BestEffort bool
+}
+
 type BulkDeactivationResult struct {
 	DeactivatedUsers []string       `json:"deactivated_users"`
 	ReassignedPRs    []ReassignedPR `json:"reassigned_prs"`
@@ -0,0 +1,12 @@
+package domain
+
+// DirectoryGroup is a team as listed in the company directory.
+type DirectoryGroup struct {
+	TeamName string
+	Members  []DirectoryMember
+}
+
+type DirectoryMember struct {
+	UserID   string
+	Username string
+}
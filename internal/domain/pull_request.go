@@ -4,6 +4,8 @@ import "time"
 
 type PRStatus string
 
+const MaxReviewers = 2
+
 const (
 	PRStatusOpen   PRStatus = "OPEN"
 	PRStatusMerged PRStatus = "MERGED"
@@ -17,15 +19,32 @@ func (s PRStatus) IsValid() bool {
 	return s == PRStatusOpen || s == PRStatusMerged
 }
 
+type ReviewerAssignment struct {
+	ReviewerID string
+	AssignedAt time.Time
+}
+
+// PRReviewerInfo is one reviewer assignment on an open pull request, together
+// with the reviewer's team.
+type PRReviewerInfo struct {
+	PullRequestID string
+	ReviewerID    string
+	ReviewerTeam  string
+}
+
 type PullRequest struct {
-	PullRequestID     string
-	PullRequestName   string
-	AuthorID          string
-	Status            PRStatus
-	AssignedReviewers []string
-	CreatedAt         time.Time
-	UpdatedAt         time.Time
-	MergedAt          *time.Time
+	PullRequestID       string
+	PullRequestName     string
+	AuthorID            string
+	Status              PRStatus
+	AssignedReviewers   []string
+	ReviewerAssignments []ReviewerAssignment
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	MergedAt            *time.Time
+	// Version is incremented on every change and used for optimistic
+	// concurrency control.
+	Version int
 }
 
 func NewPullRequest(pullRequestID, pullRequestName, authorID string) *PullRequest {
@@ -35,10 +54,11 @@ func NewPullRequest(pullRequestID, pullRequestName, authorID string) *PullReques
 		PullRequestName:   pullRequestName,
 		AuthorID:          authorID,
 		Status:            PRStatusOpen,
-		AssignedReviewers: make([]string, 0, 2),
+		AssignedReviewers: make([]string, 0, MaxReviewers),
 		CreatedAt:         now,
 		UpdatedAt:         now,
 		MergedAt:          nil,
+		Version:           1,
 	}
 }
 
@@ -66,8 +86,8 @@ func (pr *PullRequest) Merge() {
 }
 
 func (pr *PullRequest) AssignReviewers(reviewerIDs []string) {
-	if len(reviewerIDs) > 2 {
-		reviewerIDs = reviewerIDs[:2]
+	if len(reviewerIDs) > MaxReviewers {
+		reviewerIDs = reviewerIDs[:MaxReviewers]
 	}
 	pr.AssignedReviewers = reviewerIDs
 	pr.UpdatedAt = time.Now()
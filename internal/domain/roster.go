@@ -0,0 +1,49 @@
+package domain
+
+// RosterEntry is one row of a team roster uploaded from an HR system.
+type RosterEntry struct {
+	TeamName string
+	UserID   string
+	Username string
+	IsActive bool
+}
+
+// Reasons a roster import deactivates a user.
+const (
+	RosterReasonRemoved  = "removed"
+	RosterReasonInactive = "inactive"
+)
+
+type RosterMove struct {
+	UserID   string
+	FromTeam string
+	ToTeam   string
+}
+
+type RosterDeactivation struct {
+	UserID   string
+	TeamName string
+	Reason   string
+}
+
+// RosterDiff is what importing a roster changes. Only teams named in the
+// roster are compared: their active members missing from the roster are
+// deactivated, users of other teams are left alone unless the roster moves
+// them.
+type RosterDiff struct {
+	NewTeams []string
+	NewUsers []*User
+	// MovedUsers switch teams, UpdatedUsers stay in their team but change
+	// username or are reactivated.
+	MovedUsers    []RosterMove
+	UpdatedUsers  []*User
+	Deactivations []RosterDeactivation
+}
+
+// RosterImportResult carries the diff and, unless it was a dry run, the
+// reviews moved away from deactivated users.
+type RosterImportResult struct {
+	DryRun       bool
+	Diff         *RosterDiff
+	Deactivation *BulkDeactivationResult
+}
@@ -0,0 +1,105 @@
+package domain
+
+import "time"
+
+// SnapshotVersion is the version of the export format. Imports of other
+// versions are rejected.
+const SnapshotVersion = 1
+
+// Snapshot is a full copy of the teams with their members and the pull
+// requests with their reviewer assignments. Deleted and archived records are
+// not part of it.
+type Snapshot struct {
+	Version      int
+	ExportedAt   time.Time
+	Teams        []*Team
+	PullRequests []*PullRequest
+}
+
+// ConflictPolicy decides what an import does with records whose ID is already
+// taken.
+type ConflictPolicy string
+
+const (
+	ConflictSkip      ConflictPolicy = "skip"
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	ConflictFail      ConflictPolicy = "fail"
+)
+
+func (p ConflictPolicy) IsValid() bool {
+	return p == ConflictSkip || p == ConflictOverwrite || p == ConflictFail
+}
+
+// ExistingRecords holds the IDs from a snapshot that are already taken,
+// including by deleted records. Archived pull requests are listed separately
+// because they cannot be overwritten. UsernameOwners maps the usernames of
+// the snapshot that are taken to the users holding them.
+type ExistingRecords struct {
+	Teams                map[string]bool
+	Users                map[string]bool
+	PullRequests         map[string]bool
+	ArchivedPullRequests map[string]bool
+	UsernameOwners       map[string]string
+}
+
+type ImportCounts struct {
+	Created int
+	Updated int
+	Skipped int
+}
+
+type ImportResult struct {
+	Teams        ImportCounts
+	Users        ImportCounts
+	PullRequests ImportCounts
+}
+
+// IDs returns the team names, user IDs and pull request IDs the snapshot
+// refers to, including pull request authors and reviewers.
+func (s *Snapshot) IDs() (teamNames, userIDs, prIDs []string) {
+	seen := make(map[string]bool)
+	addUser := func(userID string) {
+		if !seen[userID] {
+			seen[userID] = true
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	for _, team := range s.Teams {
+		teamNames = append(teamNames, team.TeamName)
+		for _, member := range team.Members {
+			addUser(member.UserID)
+		}
+	}
+
+	for _, pr := range s.PullRequests {
+		prIDs = append(prIDs, pr.PullRequestID)
+		addUser(pr.AuthorID)
+		for _, reviewerID := range pr.AssignedReviewers {
+			addUser(reviewerID)
+		}
+	}
+
+	return teamNames, userIDs, prIDs
+}
+
+func NewExistingRecords() *ExistingRecords {
+	return &ExistingRecords{
+		Teams:                make(map[string]bool),
+		Users:                make(map[string]bool),
+		PullRequests:         make(map[string]bool),
+		ArchivedPullRequests: make(map[string]bool),
+		UsernameOwners:       make(map[string]string),
+	}
+}
+
+// Usernames returns the usernames of the members of the snapshot's teams.
+func (s *Snapshot) Usernames() []string {
+	var usernames []string
+	for _, team := range s.Teams {
+		for _, member := range team.Members {
+			usernames = append(usernames, member.Username)
+		}
+	}
+	return usernames
+}
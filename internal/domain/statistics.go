@@ -1,10 +1,25 @@
 package domain
 
+import "time"
+
+type StatsFilter struct {
+	From     *time.Time
+	To       *time.Time
+	TeamName string
+}
+
 type Statistics struct {
+	Period       *StatsPeriod     `json:"period,omitempty"`
 	PullRequests PullRequestStats `json:"pull_requests"`
 	Users        UserStats        `json:"users"`
 	Teams        TeamStats        `json:"teams"`
 	TopReviewers []ReviewerStat   `json:"top_reviewers"`
+	ByTeam       []TeamBreakdown  `json:"by_team"`
+}
+
+type StatsPeriod struct {
+	From *time.Time `json:"from,omitempty"`
+	To   *time.Time `json:"to,omitempty"`
 }
 
 type PullRequestStats struct {
@@ -28,3 +43,82 @@ type ReviewerStat struct {
 	Username    string `json:"username"`
 	ReviewCount int    `json:"review_count"`
 }
+
+type TeamBreakdown struct {
+	TeamName          string           `json:"team_name"`
+	PullRequests      PullRequestStats `json:"pull_requests"`
+	Users             UserStats        `json:"users"`
+	ReviewAssignments int              `json:"review_assignments"`
+}
+
+type MergedPRTiming struct {
+	PullRequestID string
+	AuthorID      string
+	TeamName      string
+	CreatedAt     time.Time
+	MergedAt      time.Time
+}
+
+func (t MergedPRTiming) TimeToMerge() time.Duration {
+	return t.MergedAt.Sub(t.CreatedAt)
+}
+
+type CycleTimeStats struct {
+	Period      *StatsPeriod      `json:"period,omitempty"`
+	TimeToMerge DurationSummary   `json:"time_to_merge"`
+	ByTeam      []TeamCycleTime   `json:"by_team"`
+	ByAuthor    []AuthorCycleTime `json:"by_author"`
+}
+
+type TeamCycleTime struct {
+	TeamName    string          `json:"team_name"`
+	TimeToMerge DurationSummary `json:"time_to_merge"`
+}
+
+type AuthorCycleTime struct {
+	AuthorID    string          `json:"author_id"`
+	TeamName    string          `json:"team_name"`
+	TimeToMerge DurationSummary `json:"time_to_merge"`
+}
+
+type DurationSummary struct {
+	Count      int               `json:"count"`
+	P50Seconds float64           `json:"p50_seconds"`
+	P90Seconds float64           `json:"p90_seconds"`
+	P99Seconds float64           `json:"p99_seconds"`
+	Histogram  []HistogramBucket `json:"histogram"`
+}
+
+type HistogramBucket struct {
+	UpperBound string `json:"upper_bound"`
+	Count      int    `json:"count"`
+}
+
+type ReviewerWorkload struct {
+	UserID          string `json:"user_id"`
+	Username        string `json:"username"`
+	TeamName        string `json:"team_name"`
+	OpenReviews     int    `json:"open_reviews"`
+	AssignedReviews int    `json:"assigned_reviews"`
+}
+
+type WorkloadStats struct {
+	Period *StatsPeriod       `json:"period,omitempty"`
+	Users  []ReviewerWorkload `json:"users"`
+	Teams  []TeamWorkload     `json:"teams"`
+}
+
+type TeamWorkload struct {
+	TeamName        string        `json:"team_name"`
+	ActiveUsers     int           `json:"active_users"`
+	OpenReviews     FairnessStats `json:"open_reviews"`
+	AssignedReviews FairnessStats `json:"assigned_reviews"`
+}
+
+type FairnessStats struct {
+	Total       int      `json:"total"`
+	Min         int      `json:"min"`
+	Max         int      `json:"max"`
+	Gini        float64  `json:"gini"`
+	MaxMinRatio *float64 `json:"max_min_ratio"`
+}
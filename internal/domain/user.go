@@ -11,6 +11,16 @@ type User struct {
 	UpdatedAt time.Time
 }
 
+type UserDetails struct {
+	User            *User
+	OpenReviewCount int
+}
+
+type UserFilter struct {
+	TeamName string
+	IsActive *bool
+}
+
 func NewUser(userID, username, teamName string, isActive bool) *User {
 	now := time.Now()
 	return &User{
@@ -1,6 +1,9 @@
 package errors
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type ErrorCode string
 
@@ -16,6 +19,18 @@ const (
 	ErrCodeNoCandidate ErrorCode = "NO_CANDIDATE"
 
 	ErrCodeNotFound ErrorCode = "NOT_FOUND"
+
+	ErrCodeConflict ErrorCode = "CONFLICT"
+
+	ErrCodeRateLimited ErrorCode = "RATE_LIMITED"
+
+	ErrCodeIdempotencyConflict ErrorCode = "IDEMPOTENCY_CONFLICT"
+
+	ErrCodeInvalidSnapshot ErrorCode = "INVALID_SNAPSHOT"
+
+	ErrCodeImportConflict ErrorCode = "IMPORT_CONFLICT"
+
+	ErrCodeInvalidRoster ErrorCode = "INVALID_ROSTER"
 )
 
 type AppError struct {
@@ -58,6 +73,38 @@ func ErrNotFound(resourceType, identifier string) *AppError {
 	return NewAppError(ErrCodeNotFound, fmt.Sprintf("%s '%s' not found", resourceType, identifier))
 }
 
+func ErrConcurrentModification(prID string) *AppError {
+	return NewAppError(ErrCodeConflict, fmt.Sprintf("pull request '%s' was modified concurrently, reload and retry", prID))
+}
+
+func ErrVersionMismatch(prID string, expected, actual int) *AppError {
+	return NewAppError(ErrCodeConflict, fmt.Sprintf("pull request '%s' is at version %d, expected %d", prID, actual, expected))
+}
+
+func ErrRateLimited() *AppError {
+	return NewAppError(ErrCodeRateLimited, "rate limit exceeded, retry later")
+}
+
+func ErrIdempotencyKeyReused(key string) *AppError {
+	return NewAppError(ErrCodeIdempotencyConflict, fmt.Sprintf("idempotency key '%s' was already used with a different request", key))
+}
+
+func ErrIdempotencyInProgress(key string) *AppError {
+	return NewAppError(ErrCodeIdempotencyConflict, fmt.Sprintf("request with idempotency key '%s' is still being processed", key))
+}
+
+func ErrInvalidSnapshot(message string) *AppError {
+	return NewAppError(ErrCodeInvalidSnapshot, message)
+}
+
+func ErrImportConflict(conflicts []string) *AppError {
+	return NewAppError(ErrCodeImportConflict, fmt.Sprintf("already exist: %s", strings.Join(conflicts, ", ")))
+}
+
+func ErrInvalidRoster(message string) *AppError {
+	return NewAppError(ErrCodeInvalidRoster, message)
+}
+
 func ErrTeamNotFound(teamName string) *AppError {
 	return ErrNotFound("team", teamName)
 }
@@ -0,0 +1,52 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Record is the stored outcome of the first request made with a key.
+// Completed is false while that request is still being processed.
+type Record struct {
+	RequestHash string
+	Completed   bool
+	StatusCode  int
+	ContentType string
+	// ETag is kept so that a replay hands out the version the client needs
+	// for its next If-Match.
+	ETag string
+	Body []byte
+}
+
+type Response struct {
+	StatusCode  int
+	ContentType string
+	ETag        string
+	Body        []byte
+}
+
+type Store interface {
+	// Reserve claims the key for a new request. It returns nil if the key was
+	// free (or its earlier request was abandoned for longer than lockTimeout)
+	// and the existing record otherwise.
+	Reserve(ctx context.Context, scope, key, requestHash string, lockTimeout time.Duration) (*Record, error)
+	Complete(ctx context.Context, scope, key string, response Response) error
+	// Release frees a reserved key so the request can be retried.
+	Release(ctx context.Context, scope, key string) error
+	// Purge deletes keys created more than ttl ago.
+	Purge(ctx context.Context, ttl time.Duration) error
+}
+
+// HashRequest identifies a request by method, route and body, so a key reused
+// for a different operation is detected.
+func HashRequest(method, route string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(route))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
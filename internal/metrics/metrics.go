@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "pr_reviewer"
+
+const (
+	SourceManual           = "manual"
+	SourceBulkDeactivation = "bulk_deactivation"
+)
+
+var registry = prometheus.NewRegistry()
+
+var factory = promauto.With(registry)
+
+var (
+	HTTPRequestsTotal = factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests by route, method and status code.",
+		},
+		[]string{"route", "method", "code"},
+	)
+
+	HTTPRequestDuration = factory.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route and method.",
+			Buckets:   []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+		},
+		[]string{"route", "method"},
+	)
+
+	ReviewerAssignmentsTotal = factory.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reviewer_assignments_total",
+			Help:      "Number of reviewers assigned to newly created pull requests.",
+		},
+	)
+
+	ReviewerReassignmentsTotal = factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reviewer_reassignments_total",
+			Help:      "Number of reviewer replacements by source.",
+		},
+		[]string{"source"},
+	)
+
+	NoCandidateTotal = factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "no_candidate_total",
+			Help:      "Number of reassignments that found no active replacement candidate, by source.",
+		},
+		[]string{"source"},
+	)
+
+	RateLimitedTotal = factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rate_limited_total",
+			Help:      "Number of requests rejected by the rate limiter, by route.",
+		},
+		[]string{"route"},
+	)
+
+	CacheRequestsTotal = factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_requests_total",
+			Help:      "Number of repository cache lookups by entity and result (hit or miss).",
+		},
+		[]string{"entity", "result"},
+	)
+
+	ArchivedPRsTotal = factory.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "archived_prs_total",
+			Help:      "Number of merged pull requests moved to the archive tables.",
+		},
+	)
+
+	DirectorySyncsTotal = factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "directory_syncs_total",
+			Help:      "Number of directory syncs by result (success or error).",
+		},
+		[]string{"result"},
+	)
+
+	DirectoryLastSyncTimestamp = factory.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "directory_last_sync_timestamp_seconds",
+			Help:      "Unix time of the last successful directory sync.",
+		},
+	)
+
+	BulkDeactivationDuration = factory.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "bulk_deactivation_duration_seconds",
+			Help:      "Duration of bulk user deactivation including PR reassignment.",
+			Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+		},
+	)
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+func RegisterPoolStats(pool *pgxpool.Pool, dbName string) error {
+	return registry.Register(newPoolStatsCollector(pool, dbName))
+}
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolStatsCollector exports pgxpool statistics, taking the place of the
+// go_sql_* collector for database/sql.
+type poolStatsCollector struct {
+	pool *pgxpool.Pool
+
+	maxConns         *prometheus.Desc
+	totalConns       *prometheus.Desc
+	acquiredConns    *prometheus.Desc
+	idleConns        *prometheus.Desc
+	acquireCount     *prometheus.Desc
+	acquireDuration  *prometheus.Desc
+	emptyAcquire     *prometheus.Desc
+	canceledAcquire  *prometheus.Desc
+	newConns         *prometheus.Desc
+	lifetimeDestroys *prometheus.Desc
+	idleDestroys     *prometheus.Desc
+}
+
+func newPoolStatsCollector(pool *pgxpool.Pool, dbName string) *poolStatsCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "db_pool", name),
+			help, nil, prometheus.Labels{"db_name": dbName},
+		)
+	}
+
+	return &poolStatsCollector{
+		pool:             pool,
+		maxConns:         desc("max_conns", "Maximum size of the pool."),
+		totalConns:       desc("total_conns", "Number of open connections."),
+		acquiredConns:    desc("acquired_conns", "Number of connections in use."),
+		idleConns:        desc("idle_conns", "Number of idle connections."),
+		acquireCount:     desc("acquire_total", "Number of successful connection acquisitions."),
+		acquireDuration:  desc("acquire_duration_seconds_total", "Total time spent acquiring connections."),
+		emptyAcquire:     desc("empty_acquire_total", "Number of acquisitions that had to wait for a connection."),
+		canceledAcquire:  desc("canceled_acquire_total", "Number of acquisitions canceled by their context."),
+		newConns:         desc("new_conns_total", "Number of connections opened."),
+		lifetimeDestroys: desc("max_lifetime_destroy_total", "Number of connections closed for exceeding the maximum lifetime."),
+		idleDestroys:     desc("max_idle_destroy_total", "Number of connections closed for exceeding the maximum idle time."),
+	}
+}
+
+func (c *poolStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *poolStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	gauge := func(desc *prometheus.Desc, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value)
+	}
+	counter := func(desc *prometheus.Desc, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value)
+	}
+
+	gauge(c.maxConns, float64(stat.MaxConns()))
+	gauge(c.totalConns, float64(stat.TotalConns()))
+	gauge(c.acquiredConns, float64(stat.AcquiredConns()))
+	gauge(c.idleConns, float64(stat.IdleConns()))
+	counter(c.acquireCount, float64(stat.AcquireCount()))
+	counter(c.acquireDuration, stat.AcquireDuration().Seconds())
+	counter(c.emptyAcquire, float64(stat.EmptyAcquireCount()))
+	counter(c.canceledAcquire, float64(stat.CanceledAcquireCount()))
+	counter(c.newConns, float64(stat.NewConnsCount()))
+	counter(c.lifetimeDestroys, float64(stat.MaxLifetimeDestroyCount()))
+	counter(c.idleDestroys, float64(stat.MaxIdleDestroyCount()))
+}
@@ -0,0 +1,137 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/config"
+)
+
+// Limit describes a token bucket: RequestsPerSecond tokens are added every
+// second up to Burst. A zero rate means the route is not limited.
+type Limit struct {
+	RequestsPerSecond float64
+	Burst             int
+}
+
+func (l Limit) Unlimited() bool {
+	return l.RequestsPerSecond <= 0
+}
+
+type Decision struct {
+	Allowed    bool
+	RetryAfter time.Duration
+}
+
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Decision, error)
+	// Purge drops buckets that have not been used for longer than idle.
+	Purge(ctx context.Context, idle time.Duration) error
+}
+
+// Policy maps route templates to limits, falling back to Default. Only
+// callers presenting one of APIKeys are limited by API key.
+type Policy struct {
+	Default Limit
+	Routes  map[string]Limit
+	APIKeys map[string]struct{}
+}
+
+func PolicyFromConfig(cfg config.RateLimitConfig) Policy {
+	policy := Policy{
+		Default: Limit{RequestsPerSecond: cfg.Default.RequestsPerSecond, Burst: cfg.Default.Burst},
+		Routes:  make(map[string]Limit, len(cfg.Routes)),
+		APIKeys: make(map[string]struct{}, len(cfg.APIKeys)),
+	}
+	for route, limit := range cfg.Routes {
+		policy.Routes[route] = Limit{RequestsPerSecond: limit.RequestsPerSecond, Burst: limit.Burst}
+	}
+	for _, key := range cfg.APIKeys {
+		policy.APIKeys[key] = struct{}{}
+	}
+	return policy
+}
+
+// KnownAPIKey reports whether key is one of the configured API keys.
+func (p Policy) KnownAPIKey(key string) bool {
+	_, ok := p.APIKeys[key]
+	return ok
+}
+
+func (p Policy) For(route string) Limit {
+	if limit, ok := p.Routes[route]; ok {
+		return limit
+	}
+	return p.Default
+}
+
+type Bucket struct {
+	Tokens  float64
+	Updated time.Time
+}
+
+func NewBucket(limit Limit, now time.Time) Bucket {
+	return Bucket{Tokens: float64(limit.Burst), Updated: now}
+}
+
+// Take refills the bucket for the time elapsed since its last update and
+// consumes one token if one is available.
+func (b *Bucket) Take(limit Limit, now time.Time) Decision {
+	if elapsed := now.Sub(b.Updated); elapsed > 0 {
+		b.Tokens = math.Min(float64(limit.Burst), b.Tokens+elapsed.Seconds()*limit.RequestsPerSecond)
+		b.Updated = now
+	}
+
+	if b.Tokens >= 1 {
+		b.Tokens--
+		return Decision{Allowed: true}
+	}
+
+	wait := (1 - b.Tokens) / limit.RequestsPerSecond
+	return Decision{RetryAfter: time.Duration(wait * float64(time.Second))}
+}
+
+// MemoryLimiter keeps buckets in process memory, so every replica enforces
+// its own limit.
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*Bucket
+	now     func() time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets: make(map[string]*Bucket),
+		now:     time.Now,
+	}
+}
+
+func (l *MemoryLimiter) Allow(_ context.Context, key string, limit Limit) (Decision, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	bucket, ok := l.buckets[key]
+	if !ok {
+		b := NewBucket(limit, now)
+		bucket = &b
+		l.buckets[key] = bucket
+	}
+
+	return bucket.Take(limit, now), nil
+}
+
+func (l *MemoryLimiter) Purge(_ context.Context, idle time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := l.now().Add(-idle)
+	for key, bucket := range l.buckets {
+		if bucket.Updated.Before(cutoff) {
+			delete(l.buckets, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+// Package cached wraps the user and team repositories of any backend with a
+// read-through cache. Writes made through the wrappers invalidate the entries
+// they affect, on this replica and, through the invalidator, on the others.
+package cached
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/cache"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/metrics"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/service"
+)
+
+func userKey(userID string) string {
+	return "user:" + userID
+}
+
+func teamKey(teamName string) string {
+	return "team:" + teamName
+}
+
+func activeMembersKey(teamName string) string {
+	return "active:" + teamName
+}
+
+// teamKeys are the entries that change whenever a member of teamName does.
+func teamKeys(teamName string) []string {
+	return []string{teamKey(teamName), activeMembersKey(teamName)}
+}
+
+type pendingKey struct{}
+
+// pending collects the keys written inside a transaction. They are
+// invalidated once the transaction has finished, since dropping them earlier
+// would let a concurrent read cache the data the transaction is replacing.
+type pending struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func pendingFrom(ctx context.Context) *pending {
+	p, _ := ctx.Value(pendingKey{}).(*pending)
+	return p
+}
+
+// TxManager marks the transaction's context so that the repositories bypass
+// the cache inside it: cached values would hide the transaction's own writes,
+// and values read inside it may never be committed.
+type TxManager struct {
+	inner       service.TxManager
+	invalidator *cache.Invalidator
+}
+
+func NewTxManager(inner service.TxManager, invalidator *cache.Invalidator) *TxManager {
+	return &TxManager{inner: inner, invalidator: invalidator}
+}
+
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if pendingFrom(ctx) != nil {
+		return m.inner.WithinTx(ctx, fn)
+	}
+
+	p := &pending{}
+	err := m.inner.WithinTx(context.WithValue(ctx, pendingKey{}, p), fn)
+
+	// Invalidating after a rollback is harmless, so the outcome is ignored.
+	m.invalidator.Invalidate(ctx, p.keys...)
+
+	return err
+}
+
+// WithinReadOnlyTx bypasses the cache like WithinTx, so fn reads only from the
+// transaction's snapshot.
+func (m *TxManager) WithinReadOnlyTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if pendingFrom(ctx) != nil {
+		return m.inner.WithinReadOnlyTx(ctx, fn)
+	}
+	return m.inner.WithinReadOnlyTx(context.WithValue(ctx, pendingKey{}, &pending{}), fn)
+}
+
+// store reads and invalidates entries, deferring invalidation to the end of
+// the transaction when ctx carries one.
+type store struct {
+	cache       cache.Cache
+	invalidator *cache.Invalidator
+}
+
+func (s *store) get(ctx context.Context, entity, key string) (any, bool) {
+	if pendingFrom(ctx) != nil {
+		return nil, false
+	}
+
+	value, ok := s.cache.Get(key)
+	result := "miss"
+	if ok {
+		result = "hit"
+	}
+	metrics.CacheRequestsTotal.WithLabelValues(entity, result).Inc()
+
+	return value, ok
+}
+
+func (s *store) set(ctx context.Context, key string, value any) {
+	if pendingFrom(ctx) != nil {
+		return
+	}
+	s.cache.Set(key, value)
+}
+
+func (s *store) invalidate(ctx context.Context, keys ...string) {
+	if p := pendingFrom(ctx); p != nil {
+		p.mu.Lock()
+		p.keys = append(p.keys, keys...)
+		p.mu.Unlock()
+		return
+	}
+	s.invalidator.Invalidate(ctx, keys...)
+}
+
+func copyUser(user *domain.User) *domain.User {
+	c := *user
+	return &c
+}
+
+func copyUsers(users []*domain.User) []*domain.User {
+	copied := make([]*domain.User, len(users))
+	for i, user := range users {
+		copied[i] = copyUser(user)
+	}
+	return copied
+}
+
+func copyTeam(team *domain.Team) *domain.Team {
+	c := *team
+	c.Members = copyUsers(team.Members)
+	return &c
+}
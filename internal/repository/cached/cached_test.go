@@ -0,0 +1,220 @@
+package cached
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/cache"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/repository/memory"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/repository/repotest"
+)
+
+type recordingBroadcaster struct {
+	published [][]string
+}
+
+func (b *recordingBroadcaster) Publish(_ context.Context, keys []string) error {
+	b.published = append(b.published, keys)
+	return nil
+}
+
+// fixture wraps a memory store. The inner repositories let tests change data
+// behind the cache's back.
+type fixture struct {
+	innerUsers  *memory.UserRepository
+	innerTeams  *memory.TeamRepository
+	users       *UserRepository
+	teams       *TeamRepository
+	tx          *TxManager
+	broadcaster *recordingBroadcaster
+}
+
+func newFixture() *fixture {
+	store := memory.NewStore()
+	c := cache.NewLRU(100, time.Minute)
+	broadcaster := &recordingBroadcaster{}
+	invalidator := cache.NewInvalidator(c, broadcaster)
+
+	f := &fixture{
+		innerUsers:  memory.NewUserRepository(store),
+		innerTeams:  memory.NewTeamRepository(store),
+		broadcaster: broadcaster,
+	}
+	f.users = NewUserRepository(f.innerUsers, c, invalidator)
+	f.teams = NewTeamRepository(f.innerTeams, f.innerUsers, c, invalidator)
+	f.tx = NewTxManager(memory.NewTxManager(store), invalidator)
+	return f
+}
+
+func (f *fixture) createTeam(t *testing.T, teamName string, userIDs ...string) {
+	t.Helper()
+	members := make([]*domain.User, len(userIDs))
+	for i, id := range userIDs {
+		members[i] = domain.NewUser(id, "name-"+id, teamName, true)
+	}
+	if err := f.teams.Create(context.Background(), domain.NewTeam(teamName, members)); err != nil {
+		t.Fatalf("Failed to create team %s: %v", teamName, err)
+	}
+}
+
+func ids(users []*domain.User) []string {
+	result := make([]string, len(users))
+	for i, u := range users {
+		result[i] = u.UserID
+	}
+	sort.Strings(result)
+	return result
+}
+
+func expectIDs(t *testing.T, what string, got []string, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("Expected %s %v, got %v", what, want, got)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("Expected %s %v, got %v", what, want, got)
+		}
+	}
+}
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		store := memory.NewStore()
+		c := cache.NewLRU(100, time.Minute)
+		invalidator := cache.NewInvalidator(c, nil)
+		users := memory.NewUserRepository(store)
+		return repotest.Repositories{
+			Users:     NewUserRepository(users, c, invalidator),
+			Teams:     NewTeamRepository(memory.NewTeamRepository(store), users, c, invalidator),
+			PRs:       memory.NewPRRepository(store),
+			Stats:     memory.NewStatsRepository(store),
+			Snapshots: NewSnapshotRepository(memory.NewSnapshotRepository(store), users, c, invalidator),
+			Tx:        NewTxManager(memory.NewTxManager(store), invalidator),
+		}
+	})
+}
+
+func TestGetByIDIsCached(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	f.createTeam(t, "backend", "u1")
+
+	user, err := f.users.GetByID(ctx, "u1")
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	user.Username = "modified"
+
+	if err := f.innerUsers.SetActive(ctx, "u1", false); err != nil {
+		t.Fatalf("Failed to deactivate user: %v", err)
+	}
+
+	user, _ = f.users.GetByID(ctx, "u1")
+	if !user.IsActive {
+		t.Fatal("Expected the cached user, which is still active")
+	}
+	if user.Username != "name-u1" {
+		t.Fatalf("Expected callers not to modify cached values, got %q", user.Username)
+	}
+
+	if err := f.users.SetActive(ctx, "u1", false); err != nil {
+		t.Fatalf("Failed to deactivate user: %v", err)
+	}
+
+	user, _ = f.users.GetByID(ctx, "u1")
+	if user.IsActive {
+		t.Fatal("Expected SetActive to invalidate the cached user")
+	}
+}
+
+func TestActiveMembersInvalidation(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	f.createTeam(t, "backend", "u1", "u2", "u3")
+
+	active, err := f.users.GetActiveByTeamExcluding(ctx, "backend", "u1")
+	if err != nil {
+		t.Fatalf("Failed to get active members: %v", err)
+	}
+	expectIDs(t, "candidates", ids(active), "u2", "u3")
+
+	active, _ = f.users.GetActiveByTeamExcluding(ctx, "backend", "u2")
+	expectIDs(t, "candidates", ids(active), "u1", "u3")
+
+	if err := f.users.BulkDeactivate(ctx, []string{"u3"}); err != nil {
+		t.Fatalf("Failed to deactivate users: %v", err)
+	}
+
+	active, _ = f.users.GetActiveByTeamExcluding(ctx, "backend", "u1")
+	expectIDs(t, "candidates", ids(active), "u2")
+
+	team, _ := f.teams.GetByName(ctx, "backend")
+	for _, member := range team.Members {
+		if member.UserID == "u3" && member.IsActive {
+			t.Fatal("Expected BulkDeactivate to invalidate the cached team")
+		}
+	}
+}
+
+func TestTeamUpsertInvalidatesPreviousTeam(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	f.createTeam(t, "backend", "u1", "u2")
+
+	team, _ := f.teams.GetByName(ctx, "backend")
+	expectIDs(t, "members", ids(team.Members), "u1", "u2")
+	f.users.GetByID(ctx, "u2") //nolint:errcheck
+
+	f.createTeam(t, "frontend", "u2")
+
+	team, _ = f.teams.GetByName(ctx, "backend")
+	expectIDs(t, "members", ids(team.Members), "u1")
+
+	active, _ := f.users.GetActiveByTeamExcluding(ctx, "backend", "")
+	expectIDs(t, "active members", ids(active), "u1")
+
+	user, _ := f.users.GetByID(ctx, "u2")
+	if user.TeamName != "frontend" {
+		t.Fatalf("Expected u2 to be in frontend, got %s", user.TeamName)
+	}
+}
+
+func TestTransactionBypassesCache(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	f.createTeam(t, "backend", "u1", "u2", "u3")
+	f.broadcaster.published = nil
+
+	f.users.GetActiveByTeamExcluding(ctx, "backend", "u1") //nolint:errcheck
+
+	err := f.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := f.users.BulkDeactivate(ctx, []string{"u2"}); err != nil {
+			return err
+		}
+
+		active, err := f.users.GetActiveByTeamExcluding(ctx, "backend", "u1")
+		if err != nil {
+			return err
+		}
+		expectIDs(t, "candidates inside the transaction", ids(active), "u3")
+
+		if len(f.broadcaster.published) != 0 {
+			t.Fatal("Expected invalidation to wait for the end of the transaction")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Transaction failed: %v", err)
+	}
+
+	if len(f.broadcaster.published) != 1 {
+		t.Fatalf("Expected one invalidation after the transaction, got %v", f.broadcaster.published)
+	}
+
+	active, _ := f.users.GetActiveByTeamExcluding(ctx, "backend", "u1")
+	expectIDs(t, "candidates", ids(active), "u3")
+}
@@ -0,0 +1,54 @@
+package cached
+
+import (
+	"context"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/cache"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/service"
+)
+
+// SnapshotRepository invalidates the users and teams an import writes. It
+// caches nothing itself. users is the uncached user repository of the same
+// backend, used to find the teams that imported users move away from.
+type SnapshotRepository struct {
+	service.SnapshotRepository
+	users service.UserRepository
+	store store
+}
+
+func NewSnapshotRepository(inner service.SnapshotRepository, users service.UserRepository, c cache.Cache, invalidator *cache.Invalidator) *SnapshotRepository {
+	return &SnapshotRepository{
+		SnapshotRepository: inner,
+		users:              users,
+		store:              store{cache: c, invalidator: invalidator},
+	}
+}
+
+func (r *SnapshotRepository) Import(ctx context.Context, snapshot *domain.Snapshot) error {
+	var members []*domain.User
+	for _, team := range snapshot.Teams {
+		members = append(members, team.Members...)
+	}
+
+	memberIDs := make([]string, len(members))
+	for i, member := range members {
+		memberIDs[i] = member.UserID
+	}
+
+	previous, err := r.users.GetUsersByIDs(ctx, memberIDs)
+	if err != nil {
+		return err
+	}
+
+	if err := r.SnapshotRepository.Import(ctx, snapshot); err != nil {
+		return err
+	}
+
+	keys := usersKeys(append(previous, members...))
+	for _, team := range snapshot.Teams {
+		keys = append(keys, teamKeys(team.TeamName)...)
+	}
+	r.store.invalidate(ctx, keys...)
+	return nil
+}
@@ -0,0 +1,92 @@
+package cached
+
+import (
+	"context"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/cache"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/service"
+)
+
+// TeamRepository caches GetByName. users is the uncached user repository of
+// the same backend, used to find the teams that members move away from.
+type TeamRepository struct {
+	service.TeamRepository
+	users service.UserRepository
+	store store
+}
+
+func NewTeamRepository(inner service.TeamRepository, users service.UserRepository, c cache.Cache, invalidator *cache.Invalidator) *TeamRepository {
+	return &TeamRepository{
+		TeamRepository: inner,
+		users:          users,
+		store:          store{cache: c, invalidator: invalidator},
+	}
+}
+
+func (r *TeamRepository) GetByName(ctx context.Context, teamName string) (*domain.Team, error) {
+	if cached, ok := r.store.get(ctx, "team", teamKey(teamName)); ok {
+		return copyTeam(cached.(*domain.Team)), nil
+	}
+
+	team, err := r.TeamRepository.GetByName(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store.set(ctx, teamKey(teamName), copyTeam(team))
+	return team, nil
+}
+
+// Create upserts the team's members, so besides the new team it invalidates
+// the members and the teams they previously belonged to.
+func (r *TeamRepository) Create(ctx context.Context, team *domain.Team) error {
+	memberIDs := make([]string, len(team.Members))
+	for i, member := range team.Members {
+		memberIDs[i] = member.UserID
+	}
+
+	previous, err := r.users.GetUsersByIDs(ctx, memberIDs)
+	if err != nil {
+		return err
+	}
+
+	if err := r.TeamRepository.Create(ctx, team); err != nil {
+		return err
+	}
+
+	keys := usersKeys(append(previous, team.Members...))
+	r.store.invalidate(ctx, append(keys, teamKeys(team.TeamName)...)...)
+	return nil
+}
+
+// Delete also deletes the members, so their entries are invalidated too.
+func (r *TeamRepository) Delete(ctx context.Context, teamName string) error {
+	team, err := r.TeamRepository.GetByName(ctx, teamName)
+	if err != nil {
+		return err
+	}
+
+	if err := r.TeamRepository.Delete(ctx, teamName); err != nil {
+		return err
+	}
+
+	r.store.invalidate(ctx, append(usersKeys(team.Members), teamKeys(teamName)...)...)
+	return nil
+}
+
+// Restore looks the team up after the write, since the members of a deleted
+// team cannot be read.
+func (r *TeamRepository) Restore(ctx context.Context, teamName string) error {
+	if err := r.TeamRepository.Restore(ctx, teamName); err != nil {
+		return err
+	}
+
+	team, err := r.TeamRepository.GetByName(ctx, teamName)
+	if err != nil {
+		return err
+	}
+
+	r.store.invalidate(ctx, append(usersKeys(team.Members), teamKeys(teamName)...)...)
+	return nil
+}
@@ -0,0 +1,153 @@
+package cached
+
+import (
+	"context"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/cache"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/service"
+)
+
+// UserRepository caches GetByID and GetActiveByTeamExcluding. The remaining
+// methods go straight to the wrapped repository.
+type UserRepository struct {
+	service.UserRepository
+	store store
+}
+
+func NewUserRepository(inner service.UserRepository, c cache.Cache, invalidator *cache.Invalidator) *UserRepository {
+	return &UserRepository{
+		UserRepository: inner,
+		store:          store{cache: c, invalidator: invalidator},
+	}
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, userID string) (*domain.User, error) {
+	if cached, ok := r.store.get(ctx, "user", userKey(userID)); ok {
+		return copyUser(cached.(*domain.User)), nil
+	}
+
+	user, err := r.UserRepository.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store.set(ctx, userKey(userID), copyUser(user))
+	return user, nil
+}
+
+// GetActiveByTeamExcluding caches the team's full list of active members, so
+// one entry serves every excluded user.
+func (r *UserRepository) GetActiveByTeamExcluding(ctx context.Context, teamName, excludeUserID string) ([]*domain.User, error) {
+	var active []*domain.User
+	if cached, ok := r.store.get(ctx, "active_members", activeMembersKey(teamName)); ok {
+		active = cached.([]*domain.User)
+	} else {
+		users, err := r.UserRepository.GetActiveByTeamExcluding(ctx, teamName, "")
+		if err != nil {
+			return nil, err
+		}
+		active = copyUsers(users)
+		r.store.set(ctx, activeMembersKey(teamName), active)
+	}
+
+	users := make([]*domain.User, 0, len(active))
+	for _, user := range active {
+		if user.UserID != excludeUserID {
+			users = append(users, copyUser(user))
+		}
+	}
+	return users, nil
+}
+
+func (r *UserRepository) SetActive(ctx context.Context, userID string, isActive bool) error {
+	user, err := r.UserRepository.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := r.UserRepository.SetActive(ctx, userID, isActive); err != nil {
+		return err
+	}
+
+	r.store.invalidate(ctx, append(teamKeys(user.TeamName), userKey(userID))...)
+	return nil
+}
+
+func (r *UserRepository) BulkDeactivate(ctx context.Context, userIDs []string) error {
+	users, err := r.UserRepository.GetUsersByIDs(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+
+	if err := r.UserRepository.BulkDeactivate(ctx, userIDs); err != nil {
+		return err
+	}
+
+	r.store.invalidate(ctx, usersKeys(users)...)
+	return nil
+}
+
+// Upsert invalidates both the teams the users leave and the teams they join.
+func (r *UserRepository) Upsert(ctx context.Context, users []*domain.User) error {
+	userIDs := make([]string, len(users))
+	for i, user := range users {
+		userIDs[i] = user.UserID
+	}
+
+	previous, err := r.UserRepository.GetUsersByIDs(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+
+	if err := r.UserRepository.Upsert(ctx, users); err != nil {
+		return err
+	}
+
+	r.store.invalidate(ctx, usersKeys(append(previous, users...))...)
+	return nil
+}
+
+func (r *UserRepository) Delete(ctx context.Context, userID string) error {
+	user, err := r.UserRepository.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := r.UserRepository.Delete(ctx, userID); err != nil {
+		return err
+	}
+
+	r.store.invalidate(ctx, usersKeys([]*domain.User{user})...)
+	return nil
+}
+
+// Restore looks the user up after the write, since a deleted user cannot be
+// read.
+func (r *UserRepository) Restore(ctx context.Context, userID string) error {
+	if err := r.UserRepository.Restore(ctx, userID); err != nil {
+		return err
+	}
+
+	user, err := r.UserRepository.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	r.store.invalidate(ctx, usersKeys([]*domain.User{user})...)
+	return nil
+}
+
+// usersKeys are the entries that change when any of users does.
+func usersKeys(users []*domain.User) []string {
+	keys := make([]string, 0, len(users)*3)
+	teams := make(map[string]bool)
+	for _, user := range users {
+		keys = append(keys, userKey(user.UserID))
+		if !teams[user.TeamName] {
+			teams[user.TeamName] = true
+			keys = append(keys, teamKeys(user.TeamName)...)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/idempotency"
+)
+
+type idempotencyKey struct {
+	scope string
+	key   string
+}
+
+type idempotencyEntry struct {
+	record    idempotency.Record
+	createdAt time.Time
+}
+
+// IdempotencyStore keeps Idempotency-Key records in process memory, so keys
+// are only deduplicated within one replica.
+type IdempotencyStore struct {
+	mu      sync.Mutex
+	entries map[idempotencyKey]idempotencyEntry
+	now     func() time.Time
+}
+
+func NewIdempotencyStore() *IdempotencyStore {
+	return &IdempotencyStore{
+		entries: make(map[idempotencyKey]idempotencyEntry),
+		now:     time.Now,
+	}
+}
+
+func (s *IdempotencyStore) Reserve(
+	_ context.Context,
+	scope, key, requestHash string,
+	lockTimeout time.Duration,
+) (*idempotency.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	id := idempotencyKey{scope: scope, key: key}
+
+	entry, ok := s.entries[id]
+	abandoned := ok && !entry.record.Completed &&
+		entry.record.RequestHash == requestHash &&
+		entry.createdAt.Before(now.Add(-lockTimeout))
+	if !ok || abandoned {
+		s.entries[id] = idempotencyEntry{
+			record:    idempotency.Record{RequestHash: requestHash},
+			createdAt: now,
+		}
+		return nil, nil
+	}
+
+	record := entry.record
+	return &record, nil
+}
+
+func (s *IdempotencyStore) Complete(_ context.Context, scope, key string, response idempotency.Response) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := idempotencyKey{scope: scope, key: key}
+	entry, ok := s.entries[id]
+	if !ok {
+		return nil
+	}
+
+	entry.record.Completed = true
+	entry.record.StatusCode = response.StatusCode
+	entry.record.ContentType = response.ContentType
+	entry.record.ETag = response.ETag
+	entry.record.Body = response.Body
+	s.entries[id] = entry
+	return nil
+}
+
+func (s *IdempotencyStore) Release(_ context.Context, scope, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := idempotencyKey{scope: scope, key: key}
+	if entry, ok := s.entries[id]; ok && !entry.record.Completed {
+		delete(s.entries, id)
+	}
+	return nil
+}
+
+func (s *IdempotencyStore) Purge(_ context.Context, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := s.now().Add(-ttl)
+	for id, entry := range s.entries {
+		if entry.createdAt.Before(cutoff) {
+			delete(s.entries, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/idempotency"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/repository/repotest"
+)
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		store := NewStore()
+		return repotest.Repositories{
+			Users:     NewUserRepository(store),
+			Teams:     NewTeamRepository(store),
+			PRs:       NewPRRepository(store),
+			Stats:     NewStatsRepository(store),
+			Snapshots: NewSnapshotRepository(store),
+			Tx:        NewTxManager(store),
+		}
+	})
+}
+
+func TestIdempotencyStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := NewIdempotencyStore()
+	store.now = func() time.Time { return now }
+
+	record, err := store.Reserve(ctx, "caller", "key", "hash", time.Minute)
+	if err != nil || record != nil {
+		t.Fatalf("Expected a free key, got %+v, %v", record, err)
+	}
+
+	record, err = store.Reserve(ctx, "caller", "key", "hash", time.Minute)
+	if err != nil || record == nil || record.Completed {
+		t.Fatalf("Expected an in-progress record, got %+v, %v", record, err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	record, err = store.Reserve(ctx, "caller", "key", "hash", time.Minute)
+	if err != nil || record != nil {
+		t.Fatalf("Expected an abandoned key to be taken over, got %+v, %v", record, err)
+	}
+
+	response := idempotency.Response{StatusCode: 201, ContentType: "application/json", ETag: `"1"`, Body: []byte(`{}`)}
+	if err := store.Complete(ctx, "caller", "key", response); err != nil {
+		t.Fatalf("Failed to complete: %v", err)
+	}
+	if err := store.Release(ctx, "caller", "key"); err != nil {
+		t.Fatalf("Failed to release: %v", err)
+	}
+
+	record, err = store.Reserve(ctx, "caller", "key", "other", time.Minute)
+	if err != nil || record == nil || !record.Completed || record.StatusCode != 201 || record.ETag != `"1"` || record.RequestHash != "hash" {
+		t.Fatalf("Expected the completed record, got %+v, %v", record, err)
+	}
+
+	now = now.Add(time.Hour)
+	if err := store.Purge(ctx, 30*time.Minute); err != nil {
+		t.Fatalf("Failed to purge: %v", err)
+	}
+	record, err = store.Reserve(ctx, "caller", "key", "other", time.Minute)
+	if err != nil || record != nil {
+		t.Fatalf("Expected the key to be purged, got %+v, %v", record, err)
+	}
+}
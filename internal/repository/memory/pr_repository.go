@@ -0,0 +1,235 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
+)
+
+type PRRepository struct {
+	store *Store
+}
+
+func NewPRRepository(store *Store) *PRRepository {
+	return &PRRepository{store: store}
+}
+
+func (r *PRRepository) Create(ctx context.Context, pr *domain.PullRequest) error {
+	defer r.store.write(ctx)()
+
+	if _, ok := r.store.prs[pr.PullRequestID]; ok {
+		return fmt.Errorf("failed to create pull request: pull request %s already exists", pr.PullRequestID)
+	}
+	if _, ok := r.store.archive[pr.PullRequestID]; ok {
+		return fmt.Errorf("failed to create pull request: pull request %s is archived", pr.PullRequestID)
+	}
+	if _, ok := r.store.users[pr.AuthorID]; !ok {
+		return fmt.Errorf("failed to create pull request: author %s does not exist", pr.AuthorID)
+	}
+
+	now := time.Now()
+	reviewers := make([]domain.ReviewerAssignment, 0, len(pr.AssignedReviewers))
+	for _, reviewerID := range pr.AssignedReviewers {
+		if _, ok := r.store.users[reviewerID]; !ok {
+			return fmt.Errorf("failed to assign reviewer %s: user does not exist", reviewerID)
+		}
+		for _, assigned := range reviewers {
+			if assigned.ReviewerID == reviewerID {
+				return fmt.Errorf("failed to assign reviewer %s: already assigned", reviewerID)
+			}
+		}
+		reviewers = append(reviewers, domain.ReviewerAssignment{ReviewerID: reviewerID, AssignedAt: now})
+	}
+
+	stored := *pr
+	stored.AssignedReviewers = nil
+	stored.ReviewerAssignments = nil
+	r.store.prs[pr.PullRequestID] = prRow{pr: stored, reviewers: reviewers, seq: r.store.nextSeq()}
+	return nil
+}
+
+func (r *PRRepository) GetByID(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	defer r.store.read(ctx)()
+
+	row, ok := r.store.livePR(prID)
+	if !ok {
+		return nil, errors.ErrPRNotFound(prID)
+	}
+
+	return row.toDomain(), nil
+}
+
+// Update saves pr if it is still at pr.Version and increments the version.
+// It returns a CONFLICT error if the pull request was changed in the meantime.
+func (r *PRRepository) Update(ctx context.Context, pr *domain.PullRequest) error {
+	defer r.store.write(ctx)()
+
+	row, err := r.checkVersion(pr.PullRequestID, pr.Version)
+	if err != nil {
+		return err
+	}
+
+	row.pr.PullRequestName = pr.PullRequestName
+	row.pr.Status = pr.Status
+	row.pr.UpdatedAt = time.Now()
+	row.pr.MergedAt = pr.MergedAt
+	row.pr.Version++
+	r.store.prs[pr.PullRequestID] = row
+
+	pr.Version = row.pr.Version
+	return nil
+}
+
+// ReplaceReviewer swaps a reviewer if the pull request is still at
+// expectedVersion and increments the version.
+func (r *PRRepository) ReplaceReviewer(ctx context.Context, prID string, expectedVersion int, oldReviewerID, newReviewerID string) error {
+	defer r.store.write(ctx)()
+
+	row, err := r.checkVersion(prID, expectedVersion)
+	if err != nil {
+		return err
+	}
+
+	if !row.hasReviewer(oldReviewerID) {
+		return errors.ErrNotAssigned(oldReviewerID, prID)
+	}
+	if _, ok := r.store.users[newReviewerID]; !ok {
+		return fmt.Errorf("failed to insert new reviewer: user %s does not exist", newReviewerID)
+	}
+	if row.hasReviewer(newReviewerID) {
+		return fmt.Errorf("failed to insert new reviewer: %s is already assigned", newReviewerID)
+	}
+
+	now := time.Now()
+	reviewers := make([]domain.ReviewerAssignment, 0, len(row.reviewers))
+	for _, assignment := range row.reviewers {
+		if assignment.ReviewerID != oldReviewerID {
+			reviewers = append(reviewers, assignment)
+		}
+	}
+	row.reviewers = append(reviewers, domain.ReviewerAssignment{ReviewerID: newReviewerID, AssignedAt: now})
+	row.pr.UpdatedAt = now
+	row.pr.Version++
+	r.store.prs[prID] = row
+
+	return nil
+}
+
+// checkVersion returns the stored pull request if it is at expectedVersion.
+func (r *PRRepository) checkVersion(prID string, expectedVersion int) (prRow, error) {
+	row, ok := r.store.livePR(prID)
+	if !ok {
+		return prRow{}, errors.ErrPRNotFound(prID)
+	}
+	if row.pr.Version != expectedVersion {
+		return prRow{}, errors.ErrConcurrentModification(prID)
+	}
+	return row, nil
+}
+
+// GetByReviewer returns the pull requests the user reviews, newest first.
+// Like the postgres implementation it only fills in the summary fields.
+func (r *PRRepository) GetByReviewer(ctx context.Context, reviewerID string) ([]*domain.PullRequest, error) {
+	defer r.store.read(ctx)()
+
+	rows := r.store.prsByCreation()
+	prs := make([]*domain.PullRequest, 0)
+	for i := len(rows) - 1; i >= 0; i-- {
+		row := rows[i]
+		if !row.hasReviewer(reviewerID) {
+			continue
+		}
+		prs = append(prs, &domain.PullRequest{
+			PullRequestID:   row.pr.PullRequestID,
+			PullRequestName: row.pr.PullRequestName,
+			AuthorID:        row.pr.AuthorID,
+			Status:          row.pr.Status,
+		})
+	}
+
+	return prs, nil
+}
+
+// Exists reports whether the pull request ID is taken, including by a deleted
+// or archived pull request.
+func (r *PRRepository) Exists(ctx context.Context, prID string) (bool, error) {
+	defer r.store.read(ctx)()
+
+	_, live := r.store.prs[prID]
+	_, archived := r.store.archive[prID]
+	return live || archived, nil
+}
+
+func (r *PRRepository) GetOpenPRsWithReviewers(ctx context.Context, reviewerIDs []string) ([]domain.PRReviewerInfo, error) {
+	defer r.store.read(ctx)()
+
+	infos := make([]domain.PRReviewerInfo, 0)
+	for _, row := range r.store.prsByCreation() {
+		if !row.pr.IsOpen() {
+			continue
+		}
+		for _, assignment := range row.reviewers {
+			if !containsID(reviewerIDs, assignment.ReviewerID) {
+				continue
+			}
+			infos = append(infos, domain.PRReviewerInfo{
+				PullRequestID: row.pr.PullRequestID,
+				ReviewerID:    assignment.ReviewerID,
+				ReviewerTeam:  r.store.users[assignment.ReviewerID].user.TeamName,
+			})
+		}
+	}
+
+	return infos, nil
+}
+
+// Delete soft-deletes the pull request. Its reviewers no longer count as open
+// reviews and it drops out of the statistics.
+func (r *PRRepository) Delete(ctx context.Context, prID string) error {
+	defer r.store.write(ctx)()
+
+	row, ok := r.store.livePR(prID)
+	if !ok {
+		return errors.ErrPRNotFound(prID)
+	}
+
+	now := time.Now()
+	row.deletedAt = &now
+	r.store.prs[prID] = row
+	return nil
+}
+
+// Restore undoes Delete. Archived pull requests cannot be restored.
+func (r *PRRepository) Restore(ctx context.Context, prID string) error {
+	defer r.store.write(ctx)()
+
+	row, ok := r.store.prs[prID]
+	if !ok {
+		return errors.ErrPRNotFound(prID)
+	}
+
+	row.deletedAt = nil
+	r.store.prs[prID] = row
+	return nil
+}
+
+// ArchiveMerged moves pull requests merged before mergedBefore, deleted ones
+// included, to the archive and returns how many were moved.
+func (r *PRRepository) ArchiveMerged(ctx context.Context, mergedBefore time.Time) (int, error) {
+	defer r.store.write(ctx)()
+
+	archived := 0
+	for id, row := range r.store.prs {
+		if !row.pr.IsMerged() || row.pr.MergedAt == nil || !row.pr.MergedAt.Before(mergedBefore) {
+			continue
+		}
+		r.store.archive[id] = row
+		delete(r.store.prs, id)
+		archived++
+	}
+
+	return archived, nil
+}
@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+)
+
+type SnapshotRepository struct {
+	store *Store
+}
+
+func NewSnapshotRepository(store *Store) *SnapshotRepository {
+	return &SnapshotRepository{store: store}
+}
+
+func (r *SnapshotRepository) Export(ctx context.Context) (*domain.Snapshot, error) {
+	defer r.store.read(ctx)()
+
+	teams := make([]*domain.Team, 0, len(r.store.teams))
+	for _, row := range r.store.teams {
+		if row.deletedAt != nil {
+			continue
+		}
+		team := row.team
+		team.Members = r.store.usersWhere(func(u *domain.User) bool {
+			return u.TeamName == team.TeamName
+		})
+		teams = append(teams, &team)
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		if !teams[i].CreatedAt.Equal(teams[j].CreatedAt) {
+			return teams[i].CreatedAt.Before(teams[j].CreatedAt)
+		}
+		return teams[i].TeamName < teams[j].TeamName
+	})
+
+	rows := r.store.prsByCreation()
+	prs := make([]*domain.PullRequest, 0, len(rows))
+	for _, row := range rows {
+		prs = append(prs, row.toDomain())
+	}
+
+	return &domain.Snapshot{Teams: teams, PullRequests: prs}, nil
+}
+
+func (r *SnapshotRepository) FindExisting(ctx context.Context, snapshot *domain.Snapshot) (*domain.ExistingRecords, error) {
+	defer r.store.read(ctx)()
+
+	existing := domain.NewExistingRecords()
+	teamNames, userIDs, prIDs := snapshot.IDs()
+
+	for _, teamName := range teamNames {
+		if _, ok := r.store.teams[teamName]; ok {
+			existing.Teams[teamName] = true
+		}
+	}
+	for _, userID := range userIDs {
+		if _, ok := r.store.users[userID]; ok {
+			existing.Users[userID] = true
+		}
+	}
+	for _, prID := range prIDs {
+		if _, ok := r.store.prs[prID]; ok {
+			existing.PullRequests[prID] = true
+		}
+		if _, ok := r.store.archive[prID]; ok {
+			existing.ArchivedPullRequests[prID] = true
+		}
+	}
+
+	usernames := make(map[string]bool)
+	for _, username := range snapshot.Usernames() {
+		usernames[username] = true
+	}
+	for id, row := range r.store.users {
+		if usernames[row.user.Username] {
+			existing.UsernameOwners[row.user.Username] = id
+		}
+	}
+
+	return existing, nil
+}
+
+// Import upserts the snapshot. Like the SQL backends it checks the username
+// and user references, and on failure leaves the store as it was.
+func (r *SnapshotRepository) Import(ctx context.Context, snapshot *domain.Snapshot) (err error) {
+	defer r.store.write(ctx)()
+
+	snap := r.store.snapshot()
+	defer func() {
+		if err != nil {
+			r.store.restore(snap)
+		}
+	}()
+
+	for _, team := range snapshot.Teams {
+		r.store.teams[team.TeamName] = teamRow{team: domain.Team{TeamName: team.TeamName, CreatedAt: team.CreatedAt}}
+	}
+
+	for _, team := range snapshot.Teams {
+		for _, member := range team.Members {
+			if r.store.usernameTaken(member.Username, member.UserID) {
+				return fmt.Errorf("failed to import user %s: username %s is already taken", member.UserID, member.Username)
+			}
+
+			row, ok := r.store.users[member.UserID]
+			if !ok {
+				row.seq = r.store.nextSeq()
+			}
+			row.user = *member
+			row.deletedAt = nil
+			r.store.users[member.UserID] = row
+		}
+	}
+
+	for _, pr := range snapshot.PullRequests {
+		if _, ok := r.store.users[pr.AuthorID]; !ok {
+			return fmt.Errorf("failed to import pull request %s: author %s does not exist", pr.PullRequestID, pr.AuthorID)
+		}
+		for _, reviewerID := range pr.AssignedReviewers {
+			if _, ok := r.store.users[reviewerID]; !ok {
+				return fmt.Errorf("failed to import pull request %s: reviewer %s does not exist", pr.PullRequestID, reviewerID)
+			}
+		}
+
+		row, ok := r.store.prs[pr.PullRequestID]
+		if !ok {
+			row.seq = r.store.nextSeq()
+		}
+		row.pr = *pr
+		row.pr.AssignedReviewers = nil
+		row.pr.ReviewerAssignments = nil
+		row.reviewers = append([]domain.ReviewerAssignment(nil), pr.ReviewerAssignments...)
+		row.deletedAt = nil
+		r.store.prs[pr.PullRequestID] = row
+	}
+
+	return nil
+}
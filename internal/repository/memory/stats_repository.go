@@ -0,0 +1,231 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
+)
+
+const topReviewersLimit = 10
+
+type StatsRepository struct {
+	store *Store
+}
+
+func NewStatsRepository(store *Store) *StatsRepository {
+	return &StatsRepository{store: store}
+}
+
+func (r *StatsRepository) GetStatistics(ctx context.Context, filter domain.StatsFilter) (*domain.Statistics, error) {
+	defer r.store.read(ctx)()
+
+	stats := &domain.Statistics{}
+
+	if filter.From != nil || filter.To != nil {
+		stats.Period = &domain.StatsPeriod{
+			From: filter.From,
+			To:   filter.To,
+		}
+	}
+
+	breakdowns := r.getTeamBreakdowns(filter)
+	if filter.TeamName != "" && len(breakdowns) == 0 {
+		return nil, errors.ErrTeamNotFound(filter.TeamName)
+	}
+
+	for _, b := range breakdowns {
+		stats.PullRequests.Total += b.PullRequests.Total
+		stats.PullRequests.Open += b.PullRequests.Open
+		stats.PullRequests.Merged += b.PullRequests.Merged
+
+		stats.Users.Total += b.Users.Total
+		stats.Users.Active += b.Users.Active
+		stats.Users.Inactive += b.Users.Inactive
+	}
+	stats.Teams.Total = len(breakdowns)
+	stats.ByTeam = breakdowns
+	stats.TopReviewers = r.getTopReviewers(filter)
+
+	return stats, nil
+}
+
+// PRs are attributed to the author's team and review assignments to the
+// reviewer's team, as in the postgres implementation. Deleted teams and users
+// are not listed, but the PRs and reviews of deleted users still count for
+// their team.
+func (r *StatsRepository) getTeamBreakdowns(filter domain.StatsFilter) []domain.TeamBreakdown {
+	byTeam := make(map[string]*domain.TeamBreakdown)
+	for name, row := range r.store.teams {
+		if row.deletedAt == nil && (filter.TeamName == "" || name == filter.TeamName) {
+			byTeam[name] = &domain.TeamBreakdown{TeamName: name}
+		}
+	}
+
+	for _, row := range r.store.users {
+		b, ok := byTeam[row.user.TeamName]
+		if !ok || row.deletedAt != nil {
+			continue
+		}
+		b.Users.Total++
+		if row.user.IsActive {
+			b.Users.Active++
+		} else {
+			b.Users.Inactive++
+		}
+	}
+
+	for _, row := range r.store.statsPRs() {
+		if b, ok := byTeam[r.store.users[row.pr.AuthorID].user.TeamName]; ok {
+			if inRange(row.pr.CreatedAt, filter.From, filter.To) {
+				b.PullRequests.Total++
+				if row.pr.IsOpen() {
+					b.PullRequests.Open++
+				}
+			}
+			if row.pr.MergedAt != nil && inRange(*row.pr.MergedAt, filter.From, filter.To) {
+				b.PullRequests.Merged++
+			}
+		}
+
+		for _, assignment := range row.reviewers {
+			b, ok := byTeam[r.store.users[assignment.ReviewerID].user.TeamName]
+			if ok && inRange(assignment.AssignedAt, filter.From, filter.To) {
+				b.ReviewAssignments++
+			}
+		}
+	}
+
+	breakdowns := make([]domain.TeamBreakdown, 0, len(byTeam))
+	for _, b := range byTeam {
+		breakdowns = append(breakdowns, *b)
+	}
+	sort.Slice(breakdowns, func(i, j int) bool {
+		return breakdowns[i].TeamName < breakdowns[j].TeamName
+	})
+
+	return breakdowns
+}
+
+func (r *StatsRepository) getTopReviewers(filter domain.StatsFilter) []domain.ReviewerStat {
+	counts := make(map[string]int)
+	for _, row := range r.store.statsPRs() {
+		for _, assignment := range row.reviewers {
+			reviewer := r.store.users[assignment.ReviewerID]
+			user := reviewer.user
+			if reviewer.deletedAt == nil && inRange(assignment.AssignedAt, filter.From, filter.To) &&
+				(filter.TeamName == "" || user.TeamName == filter.TeamName) {
+				counts[user.UserID]++
+			}
+		}
+	}
+
+	reviewers := make([]domain.ReviewerStat, 0, len(counts))
+	for userID, count := range counts {
+		reviewers = append(reviewers, domain.ReviewerStat{
+			UserID:      userID,
+			Username:    r.store.users[userID].user.Username,
+			ReviewCount: count,
+		})
+	}
+	sort.Slice(reviewers, func(i, j int) bool {
+		if reviewers[i].ReviewCount != reviewers[j].ReviewCount {
+			return reviewers[i].ReviewCount > reviewers[j].ReviewCount
+		}
+		return reviewers[i].UserID < reviewers[j].UserID
+	})
+
+	if len(reviewers) > topReviewersLimit {
+		reviewers = reviewers[:topReviewersLimit]
+	}
+	return reviewers
+}
+
+func (r *StatsRepository) GetMergedPRTimings(ctx context.Context, filter domain.StatsFilter) ([]domain.MergedPRTiming, error) {
+	defer r.store.read(ctx)()
+
+	if err := r.ensureTeamExists(filter.TeamName); err != nil {
+		return nil, err
+	}
+
+	timings := make([]domain.MergedPRTiming, 0)
+	for _, row := range r.store.statsPRs() {
+		if row.pr.MergedAt == nil || !inRange(*row.pr.MergedAt, filter.From, filter.To) {
+			continue
+		}
+		teamName := r.store.users[row.pr.AuthorID].user.TeamName
+		if filter.TeamName != "" && teamName != filter.TeamName {
+			continue
+		}
+		timings = append(timings, domain.MergedPRTiming{
+			PullRequestID: row.pr.PullRequestID,
+			AuthorID:      row.pr.AuthorID,
+			TeamName:      teamName,
+			CreatedAt:     row.pr.CreatedAt,
+			MergedAt:      *row.pr.MergedAt,
+		})
+	}
+	sort.Slice(timings, func(i, j int) bool {
+		return timings[i].MergedAt.Before(timings[j].MergedAt)
+	})
+
+	return timings, nil
+}
+
+func (r *StatsRepository) ensureTeamExists(teamName string) error {
+	if teamName == "" {
+		return nil
+	}
+	if _, ok := r.store.liveTeam(teamName); !ok {
+		return errors.ErrTeamNotFound(teamName)
+	}
+	return nil
+}
+
+func (r *StatsRepository) GetReviewerWorkload(ctx context.Context, filter domain.StatsFilter) ([]domain.ReviewerWorkload, error) {
+	defer r.store.read(ctx)()
+
+	if err := r.ensureTeamExists(filter.TeamName); err != nil {
+		return nil, err
+	}
+
+	byUser := make(map[string]*domain.ReviewerWorkload)
+	for id, row := range r.store.users {
+		if row.user.IsActive && row.deletedAt == nil && (filter.TeamName == "" || row.user.TeamName == filter.TeamName) {
+			byUser[id] = &domain.ReviewerWorkload{
+				UserID:   id,
+				Username: row.user.Username,
+				TeamName: row.user.TeamName,
+			}
+		}
+	}
+
+	for _, row := range r.store.statsPRs() {
+		for _, assignment := range row.reviewers {
+			w, ok := byUser[assignment.ReviewerID]
+			if !ok {
+				continue
+			}
+			if row.pr.IsOpen() {
+				w.OpenReviews++
+			}
+			if inRange(assignment.AssignedAt, filter.From, filter.To) {
+				w.AssignedReviews++
+			}
+		}
+	}
+
+	workloads := make([]domain.ReviewerWorkload, 0, len(byUser))
+	for _, w := range byUser {
+		workloads = append(workloads, *w)
+	}
+	sort.Slice(workloads, func(i, j int) bool {
+		if workloads[i].TeamName != workloads[j].TeamName {
+			return workloads[i].TeamName < workloads[j].TeamName
+		}
+		return workloads[i].UserID < workloads[j].UserID
+	})
+
+	return workloads, nil
+}
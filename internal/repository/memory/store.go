@@ -0,0 +1,243 @@
+// Package memory implements the repositories on top of process memory. It
+// follows the semantics of the postgres package and is meant for tests and
+// demos; nothing survives a restart.
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+)
+
+type teamRow struct {
+	team      domain.Team
+	deletedAt *time.Time
+}
+
+type userRow struct {
+	user      domain.User
+	seq       int64
+	deletedAt *time.Time
+}
+
+type prRow struct {
+	// pr does not carry reviewers; they are kept in reviewers in assignment
+	// order.
+	pr        domain.PullRequest
+	reviewers []domain.ReviewerAssignment
+	seq       int64
+	deletedAt *time.Time
+}
+
+// Store holds the tables shared by the repositories of one backend. Rows are
+// stored by value and reviewer slices are never modified in place, so a
+// snapshot only has to copy the maps. Soft-deleted rows stay in the maps with
+// deletedAt set; archived pull requests move from prs to archive.
+type Store struct {
+	mu      sync.RWMutex
+	seq     int64
+	teams   map[string]teamRow
+	users   map[string]userRow
+	prs     map[string]prRow
+	archive map[string]prRow
+}
+
+func NewStore() *Store {
+	return &Store{
+		teams:   make(map[string]teamRow),
+		users:   make(map[string]userRow),
+		prs:     make(map[string]prRow),
+		archive: make(map[string]prRow),
+	}
+}
+
+type txKey struct{}
+
+func (s *Store) inTx(ctx context.Context) bool {
+	tx, _ := ctx.Value(txKey{}).(*Store)
+	return tx == s
+}
+
+// read and write lock the store unless ctx belongs to a transaction, which
+// already holds the write lock.
+func (s *Store) read(ctx context.Context) func() {
+	if s.inTx(ctx) {
+		return func() {}
+	}
+	s.mu.RLock()
+	return s.mu.RUnlock
+}
+
+func (s *Store) write(ctx context.Context) func() {
+	if s.inTx(ctx) {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+func (s *Store) nextSeq() int64 {
+	s.seq++
+	return s.seq
+}
+
+type snapshot struct {
+	teams   map[string]teamRow
+	users   map[string]userRow
+	prs     map[string]prRow
+	archive map[string]prRow
+}
+
+func (s *Store) snapshot() snapshot {
+	snap := snapshot{
+		teams:   make(map[string]teamRow, len(s.teams)),
+		users:   make(map[string]userRow, len(s.users)),
+		prs:     make(map[string]prRow, len(s.prs)),
+		archive: make(map[string]prRow, len(s.archive)),
+	}
+	for k, v := range s.teams {
+		snap.teams[k] = v
+	}
+	for k, v := range s.users {
+		snap.users[k] = v
+	}
+	for k, v := range s.prs {
+		snap.prs[k] = v
+	}
+	for k, v := range s.archive {
+		snap.archive[k] = v
+	}
+	return snap
+}
+
+func (s *Store) restore(snap snapshot) {
+	s.teams = snap.teams
+	s.users = snap.users
+	s.prs = snap.prs
+	s.archive = snap.archive
+}
+
+// liveTeam, liveUser and livePR look up a row that is not soft-deleted.
+func (s *Store) liveTeam(teamName string) (teamRow, bool) {
+	row, ok := s.teams[teamName]
+	return row, ok && row.deletedAt == nil
+}
+
+func (s *Store) liveUser(userID string) (userRow, bool) {
+	row, ok := s.users[userID]
+	return row, ok && row.deletedAt == nil
+}
+
+func (s *Store) livePR(prID string) (prRow, bool) {
+	row, ok := s.prs[prID]
+	return row, ok && row.deletedAt == nil
+}
+
+// usersWhere returns copies of the matching users that are not deleted,
+// ordered by creation time.
+func (s *Store) usersWhere(match func(u *domain.User) bool) []*domain.User {
+	rows := make([]userRow, 0)
+	for _, row := range s.users {
+		if row.deletedAt == nil && match(&row.user) {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].user.CreatedAt.Equal(rows[j].user.CreatedAt) {
+			return rows[i].user.CreatedAt.Before(rows[j].user.CreatedAt)
+		}
+		return rows[i].seq < rows[j].seq
+	})
+
+	users := make([]*domain.User, 0, len(rows))
+	for _, row := range rows {
+		user := row.user
+		users = append(users, &user)
+	}
+	return users
+}
+
+// prsByCreation returns the pull requests that are not deleted, ordered by
+// creation time.
+func (s *Store) prsByCreation() []prRow {
+	rows := make([]prRow, 0, len(s.prs))
+	for _, row := range s.prs {
+		if row.deletedAt == nil {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].pr.CreatedAt.Equal(rows[j].pr.CreatedAt) {
+			return rows[i].pr.CreatedAt.Before(rows[j].pr.CreatedAt)
+		}
+		return rows[i].seq < rows[j].seq
+	})
+	return rows
+}
+
+// statsPRs returns the live and archived pull requests that are not deleted.
+// Statistics read from both, so archiving does not change them.
+func (s *Store) statsPRs() []prRow {
+	rows := make([]prRow, 0, len(s.prs)+len(s.archive))
+	for _, table := range []map[string]prRow{s.prs, s.archive} {
+		for _, row := range table {
+			if row.deletedAt == nil {
+				rows = append(rows, row)
+			}
+		}
+	}
+	return rows
+}
+
+func (s *Store) usernameTaken(username, exceptUserID string) bool {
+	for id, row := range s.users {
+		if id != exceptUserID && row.user.Username == username {
+			return true
+		}
+	}
+	return false
+}
+
+func (row prRow) hasReviewer(userID string) bool {
+	for _, assignment := range row.reviewers {
+		if assignment.ReviewerID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func (row prRow) toDomain() *domain.PullRequest {
+	pr := row.pr
+	pr.AssignedReviewers = make([]string, 0, len(row.reviewers))
+	pr.ReviewerAssignments = make([]domain.ReviewerAssignment, 0, len(row.reviewers))
+	for _, assignment := range row.reviewers {
+		pr.AssignedReviewers = append(pr.AssignedReviewers, assignment.ReviewerID)
+		pr.ReviewerAssignments = append(pr.ReviewerAssignments, assignment)
+	}
+	return &pr
+}
+
+// inRange reports whether t falls into [from, to), treating nil bounds as
+// open.
+func inRange(t time.Time, from, to *time.Time) bool {
+	if from != nil && t.Before(*from) {
+		return false
+	}
+	if to != nil && !t.Before(*to) {
+		return false
+	}
+	return true
+}
+
+func containsID(ids []string, id string) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
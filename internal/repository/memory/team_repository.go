@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
+)
+
+type TeamRepository struct {
+	store *Store
+}
+
+func NewTeamRepository(store *Store) *TeamRepository {
+	return &TeamRepository{store: store}
+}
+
+// Create adds the team and upserts its members. Existing users keep their
+// creation time but take the member's name, team and status, and deleted ones
+// are restored.
+func (r *TeamRepository) Create(ctx context.Context, team *domain.Team) error {
+	defer r.store.write(ctx)()
+
+	if _, ok := r.store.teams[team.TeamName]; ok {
+		return fmt.Errorf("failed to create team: team %s already exists", team.TeamName)
+	}
+
+	usernames := make(map[string]string, len(team.Members))
+	for _, member := range team.Members {
+		if member.TeamName != team.TeamName {
+			if _, ok := r.store.teams[member.TeamName]; !ok {
+				return fmt.Errorf("failed to create/update user %s: team %s does not exist", member.UserID, member.TeamName)
+			}
+		}
+		if owner, ok := usernames[member.Username]; (ok && owner != member.UserID) || r.store.usernameTaken(member.Username, member.UserID) {
+			return fmt.Errorf("failed to create/update user %s: username %s is already taken", member.UserID, member.Username)
+		}
+		usernames[member.Username] = member.UserID
+	}
+
+	r.store.teams[team.TeamName] = teamRow{team: domain.Team{TeamName: team.TeamName, CreatedAt: team.CreatedAt}}
+
+	now := time.Now()
+	for _, member := range team.Members {
+		if row, ok := r.store.users[member.UserID]; ok {
+			row.user.Username = member.Username
+			row.user.TeamName = member.TeamName
+			row.user.IsActive = member.IsActive
+			row.user.UpdatedAt = now
+			row.deletedAt = nil
+			r.store.users[member.UserID] = row
+			continue
+		}
+		r.store.users[member.UserID] = userRow{user: *member, seq: r.store.nextSeq()}
+	}
+
+	return nil
+}
+
+func (r *TeamRepository) GetByName(ctx context.Context, teamName string) (*domain.Team, error) {
+	defer r.store.read(ctx)()
+
+	row, ok := r.store.liveTeam(teamName)
+	if !ok {
+		return nil, errors.ErrTeamNotFound(teamName)
+	}
+
+	team := row.team
+	team.Members = r.store.usersWhere(func(u *domain.User) bool {
+		return u.TeamName == teamName
+	})
+	return &team, nil
+}
+
+// Exists reports whether the team name is taken, including by a deleted team.
+func (r *TeamRepository) Exists(ctx context.Context, teamName string) (bool, error) {
+	defer r.store.read(ctx)()
+
+	_, ok := r.store.teams[teamName]
+	return ok, nil
+}
+
+// Delete soft-deletes the team together with its members. Members get the
+// team's deletedAt, which is how Restore tells them apart from users deleted
+// on their own.
+func (r *TeamRepository) Delete(ctx context.Context, teamName string) error {
+	defer r.store.write(ctx)()
+
+	row, ok := r.store.liveTeam(teamName)
+	if !ok {
+		return errors.ErrTeamNotFound(teamName)
+	}
+
+	now := time.Now()
+	row.deletedAt = &now
+	r.store.teams[teamName] = row
+
+	for id, user := range r.store.users {
+		if user.user.TeamName == teamName && user.deletedAt == nil {
+			user.deletedAt = &now
+			r.store.users[id] = user
+		}
+	}
+
+	return nil
+}
+
+// Restore undoes Delete, bringing back the members that were deleted with the
+// team.
+func (r *TeamRepository) Restore(ctx context.Context, teamName string) error {
+	defer r.store.write(ctx)()
+
+	row, ok := r.store.teams[teamName]
+	if !ok {
+		return errors.ErrTeamNotFound(teamName)
+	}
+	if row.deletedAt == nil {
+		return nil
+	}
+
+	deletedAt := *row.deletedAt
+	row.deletedAt = nil
+	r.store.teams[teamName] = row
+
+	for id, user := range r.store.users {
+		if user.user.TeamName == teamName && user.deletedAt != nil && user.deletedAt.Equal(deletedAt) {
+			user.deletedAt = nil
+			r.store.users[id] = user
+		}
+	}
+
+	return nil
+}
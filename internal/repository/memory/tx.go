@@ -0,0 +1,39 @@
+package memory
+
+import "context"
+
+// TxManager runs a function with exclusive access to the store and undoes
+// its changes if it fails.
+type TxManager struct {
+	store *Store
+}
+
+func NewTxManager(store *Store) *TxManager {
+	return &TxManager{store: store}
+}
+
+// WithinTx keeps the store locked while fn runs, so transactions are
+// serialized. Calls nested in an existing transaction join it.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	s := m.store
+	if s.inTx(ctx) {
+		return fn(ctx)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := s.snapshot()
+	if err := fn(context.WithValue(ctx, txKey{}, s)); err != nil {
+		s.restore(saved)
+		return err
+	}
+
+	return nil
+}
+
+// WithinReadOnlyTx runs fn under the same lock as WithinTx, so it sees no
+// concurrent writes. The store does not stop fn from writing.
+func (m *TxManager) WithinReadOnlyTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return m.WithinTx(ctx, fn)
+}
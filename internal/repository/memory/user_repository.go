@@ -0,0 +1,245 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
+)
+
+type UserRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) *UserRepository {
+	return &UserRepository{store: store}
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	defer r.store.write(ctx)()
+
+	if _, ok := r.store.users[user.UserID]; ok {
+		return fmt.Errorf("failed to create user: user %s already exists", user.UserID)
+	}
+	if _, ok := r.store.teams[user.TeamName]; !ok {
+		return fmt.Errorf("failed to create user: team %s does not exist", user.TeamName)
+	}
+	if r.store.usernameTaken(user.Username, "") {
+		return fmt.Errorf("failed to create user: username %s is already taken", user.Username)
+	}
+
+	r.store.users[user.UserID] = userRow{user: *user, seq: r.store.nextSeq()}
+	return nil
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
+	defer r.store.write(ctx)()
+
+	row, ok := r.store.liveUser(user.UserID)
+	if !ok {
+		return errors.ErrUserNotFound(user.UserID)
+	}
+	if _, ok := r.store.teams[user.TeamName]; !ok {
+		return fmt.Errorf("failed to update user: team %s does not exist", user.TeamName)
+	}
+	if r.store.usernameTaken(user.Username, user.UserID) {
+		return fmt.Errorf("failed to update user: username %s is already taken", user.Username)
+	}
+
+	row.user.Username = user.Username
+	row.user.TeamName = user.TeamName
+	row.user.IsActive = user.IsActive
+	row.user.UpdatedAt = time.Now()
+	r.store.users[user.UserID] = row
+	return nil
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, userID string) (*domain.User, error) {
+	defer r.store.read(ctx)()
+
+	row, ok := r.store.liveUser(userID)
+	if !ok {
+		return nil, errors.ErrUserNotFound(userID)
+	}
+
+	user := row.user
+	return &user, nil
+}
+
+func (r *UserRepository) GetByTeam(ctx context.Context, teamName string) ([]*domain.User, error) {
+	defer r.store.read(ctx)()
+
+	return r.store.usersWhere(func(u *domain.User) bool {
+		return u.TeamName == teamName
+	}), nil
+}
+
+func (r *UserRepository) GetActiveByTeamExcluding(ctx context.Context, teamName, excludeUserID string) ([]*domain.User, error) {
+	defer r.store.read(ctx)()
+
+	return r.store.usersWhere(func(u *domain.User) bool {
+		return u.TeamName == teamName && u.IsActive && u.UserID != excludeUserID
+	}), nil
+}
+
+func (r *UserRepository) SetActive(ctx context.Context, userID string, isActive bool) error {
+	defer r.store.write(ctx)()
+
+	row, ok := r.store.liveUser(userID)
+	if !ok {
+		return errors.ErrUserNotFound(userID)
+	}
+
+	row.user.IsActive = isActive
+	row.user.UpdatedAt = time.Now()
+	r.store.users[userID] = row
+	return nil
+}
+
+// Exists reports whether the user ID is taken, including by a deleted user.
+func (r *UserRepository) Exists(ctx context.Context, userID string) (bool, error) {
+	defer r.store.read(ctx)()
+
+	_, ok := r.store.users[userID]
+	return ok, nil
+}
+
+func (r *UserRepository) BulkDeactivate(ctx context.Context, userIDs []string) error {
+	defer r.store.write(ctx)()
+
+	now := time.Now()
+	for _, id := range userIDs {
+		row, ok := r.store.liveUser(id)
+		if !ok {
+			continue
+		}
+		row.user.IsActive = false
+		row.user.UpdatedAt = now
+		r.store.users[id] = row
+	}
+
+	return nil
+}
+
+// Upsert creates the users or updates the username, team and active flag of
+// existing ones, bringing deleted users back. CreatedAt of existing users is
+// kept.
+func (r *UserRepository) Upsert(ctx context.Context, users []*domain.User) error {
+	defer r.store.write(ctx)()
+
+	usernames := make(map[string]string, len(users))
+	for _, user := range users {
+		if _, ok := r.store.teams[user.TeamName]; !ok {
+			return fmt.Errorf("failed to upsert user %s: team %s does not exist", user.UserID, user.TeamName)
+		}
+		if owner, ok := usernames[user.Username]; (ok && owner != user.UserID) || r.store.usernameTaken(user.Username, user.UserID) {
+			return fmt.Errorf("failed to upsert user %s: username %s is already taken", user.UserID, user.Username)
+		}
+		usernames[user.Username] = user.UserID
+	}
+
+	for _, user := range users {
+		if row, ok := r.store.users[user.UserID]; ok {
+			row.user.Username = user.Username
+			row.user.TeamName = user.TeamName
+			row.user.IsActive = user.IsActive
+			row.user.UpdatedAt = user.UpdatedAt
+			row.deletedAt = nil
+			r.store.users[user.UserID] = row
+			continue
+		}
+		r.store.users[user.UserID] = userRow{user: *user, seq: r.store.nextSeq()}
+	}
+
+	return nil
+}
+
+func (r *UserRepository) GetUsersByIDs(ctx context.Context, userIDs []string) ([]*domain.User, error) {
+	defer r.store.read(ctx)()
+
+	return r.store.usersWhere(func(u *domain.User) bool {
+		return containsID(userIDs, u.UserID)
+	}), nil
+}
+
+func (r *UserRepository) List(ctx context.Context, filter domain.UserFilter) ([]*domain.User, error) {
+	defer r.store.read(ctx)()
+
+	users := r.store.usersWhere(func(u *domain.User) bool {
+		return (filter.TeamName == "" || u.TeamName == filter.TeamName) &&
+			(filter.IsActive == nil || u.IsActive == *filter.IsActive)
+	})
+	sort.SliceStable(users, func(i, j int) bool {
+		return users[i].TeamName < users[j].TeamName
+	})
+
+	return users, nil
+}
+
+func (r *UserRepository) GetOpenReviewCounts(ctx context.Context, userIDs []string) (map[string]int, error) {
+	defer r.store.read(ctx)()
+
+	counts := make(map[string]int, len(userIDs))
+	for _, row := range r.store.prs {
+		if !row.pr.IsOpen() || row.deletedAt != nil {
+			continue
+		}
+		for _, assignment := range row.reviewers {
+			if containsID(userIDs, assignment.ReviewerID) {
+				counts[assignment.ReviewerID]++
+			}
+		}
+	}
+
+	return counts, nil
+}
+
+func (r *UserRepository) GetUsernameOwners(ctx context.Context, usernames []string) (map[string]string, error) {
+	defer r.store.read(ctx)()
+
+	owners := make(map[string]string, len(usernames))
+	for id, row := range r.store.users {
+		if containsID(usernames, row.user.Username) {
+			owners[row.user.Username] = id
+		}
+	}
+
+	return owners, nil
+}
+
+// Delete soft-deletes the user. Their pull requests and review history stay in
+// place.
+func (r *UserRepository) Delete(ctx context.Context, userID string) error {
+	defer r.store.write(ctx)()
+
+	row, ok := r.store.liveUser(userID)
+	if !ok {
+		return errors.ErrUserNotFound(userID)
+	}
+
+	now := time.Now()
+	row.deletedAt = &now
+	r.store.users[userID] = row
+	return nil
+}
+
+// Restore undoes Delete. A user of a deleted team can only come back with the
+// team, so it fails with the team's NOT_FOUND error in that case.
+func (r *UserRepository) Restore(ctx context.Context, userID string) error {
+	defer r.store.write(ctx)()
+
+	row, ok := r.store.users[userID]
+	if !ok {
+		return errors.ErrUserNotFound(userID)
+	}
+	if _, ok := r.store.liveTeam(row.user.TeamName); !ok {
+		return errors.ErrTeamNotFound(row.user.TeamName)
+	}
+
+	row.deletedAt = nil
+	r.store.users[userID] = row
+	return nil
+}
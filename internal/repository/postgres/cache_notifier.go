@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	cacheChannel = "pr_reviewer_cache"
+	// maxPayload stays under the 8000 byte limit of NOTIFY payloads.
+	maxPayload       = 7000
+	listenRetryDelay = time.Second
+	listenStatement  = "LISTEN " + cacheChannel
+	notifyStatement  = "SELECT pg_notify('" + cacheChannel + "', $1)"
+)
+
+// CacheNotifier carries cache invalidations between replicas over
+// LISTEN/NOTIFY.
+type CacheNotifier struct {
+	db *DB
+}
+
+func NewCacheNotifier(db *DB) *CacheNotifier {
+	return &CacheNotifier{db: db}
+}
+
+// Publish sends keys to every listening replica. Key lists too long for one
+// notification are sent as an empty list, which tells replicas to drop
+// everything.
+func (n *CacheNotifier) Publish(ctx context.Context, keys []string) error {
+	payload, err := json.Marshal(keys)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache keys: %w", err)
+	}
+	if len(payload) > maxPayload {
+		payload = []byte("[]")
+	}
+
+	if _, err := n.db.Exec(ctx, notifyStatement, string(payload)); err != nil {
+		return fmt.Errorf("failed to publish cache invalidation: %w", err)
+	}
+	return nil
+}
+
+// Listen calls apply with the keys of every notification until ctx is
+// cancelled. It holds one pool connection and reconnects after errors; since
+// notifications sent while disconnected are lost, apply is called with an
+// empty list after every (re)connect.
+func (n *CacheNotifier) Listen(ctx context.Context, apply func(keys []string)) {
+	for {
+		err := n.listen(ctx, apply)
+		if ctx.Err() != nil {
+			return
+		}
+
+		slog.Warn("cache invalidation listener disconnected", slog.String("error", err.Error()))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetryDelay):
+		}
+	}
+}
+
+func (n *CacheNotifier) listen(ctx context.Context, apply func(keys []string)) error {
+	conn, err := n.db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	// The connection stays subscribed, so it is closed rather than returned
+	// to the pool.
+	defer func() {
+		conn.Conn().Close(context.Background()) //nolint:errcheck
+		conn.Release()
+	}()
+
+	if _, err := conn.Exec(ctx, listenStatement); err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	apply(nil)
+
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to wait for notification: %w", err)
+		}
+
+		var keys []string
+		if err := json.Unmarshal([]byte(notification.Payload), &keys); err != nil {
+			slog.Warn("invalid cache invalidation payload", slog.String("error", err.Error()))
+			continue
+		}
+		apply(keys)
+	}
+}
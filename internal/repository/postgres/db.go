@@ -1,50 +1,207 @@
 package postgres
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/exaring/otelpgx"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type DB struct {
-	*sql.DB
+	*pgxpool.Pool
 }
 
-func NewDB(dsn string) (*DB, error) {
-	db, err := sql.Open("pgx", dsn)
+type migration struct {
+	version int
+	path    string
+}
+
+// PoolConfig sizes the connection pool. Zero values keep the pgxpool
+// defaults.
+type PoolConfig struct {
+	MaxConns          int
+	MinConns          int
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+}
+
+func NewDB(dsn string, pool PoolConfig) (*DB, error) {
+	config, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database DSN: %w", err)
+	}
+
+	config.ConnConfig.Tracer = otelpgx.NewTracer(otelpgx.WithTrimSQLInSpanName())
+
+	if pool.MaxConns > 0 {
+		config.MaxConns = int32(pool.MaxConns) //nolint:gosec
+	}
+	if pool.MinConns > 0 {
+		config.MinConns = int32(pool.MinConns) //nolint:gosec
+	}
+	if pool.MaxConnLifetime > 0 {
+		config.MaxConnLifetime = pool.MaxConnLifetime
+	}
+	if pool.MaxConnIdleTime > 0 {
+		config.MaxConnIdleTime = pool.MaxConnIdleTime
+	}
+	if pool.HealthCheckPeriod > 0 {
+		config.HealthCheckPeriod = pool.HealthCheckPeriod
+	}
+
+	ctx := context.Background()
+
+	p, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	if err := db.Ping(); err != nil {
+	if err := p.Ping(ctx); err != nil {
+		p.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
-
-	return &DB{db}, nil
+	return &DB{p}, nil
 }
 
 func (db *DB) RunMigrations(migrationsPath string) error {
-	sqlFile := filepath.Join(migrationsPath, "001_init_schema.up.sql")
+	migrations, err := loadMigrations(migrationsPath)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	_, err = db.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
 
-	content, err := os.ReadFile(sqlFile) //nolint:gosec
+	current, err := db.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		if err := db.applyMigration(ctx, m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (db *DB) applyMigration(ctx context.Context, m migration) error {
+	content, err := os.ReadFile(m.path) //nolint:gosec
 	if err != nil {
 		return fmt.Errorf("failed to read migration file: %w", err)
 	}
 
-	_, err = db.Exec(string(content))
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	// Without arguments pgx uses the simple protocol, which allows the
+	// several statements of a migration file in one call.
+	if _, err := tx.Exec(ctx, string(content)); err != nil {
+		return fmt.Errorf("failed to execute migration %d: %w", m.version, err)
+	}
+
+	if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, m.version); err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", m.version, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit migration %d: %w", m.version, err)
+	}
+
+	return nil
+}
+
+func (db *DB) MigrationVersion(ctx context.Context) (int, error) {
+	var version int
+	err := db.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
 	if err != nil {
-		return fmt.Errorf("failed to execute migration: %w", err)
+		return 0, fmt.Errorf("failed to get migration version: %w", err)
+	}
+
+	return version, nil
+}
+
+func (db *DB) CheckMigrationVersion(ctx context.Context, expected int) error {
+	version, err := db.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	if version != expected {
+		return fmt.Errorf("schema version is %d, expected %d", version, expected)
 	}
 
 	return nil
 }
 
+func LatestMigrationVersion(migrationsPath string) (int, error) {
+	migrations, err := loadMigrations(migrationsPath)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(migrations) == 0 {
+		return 0, nil
+	}
+
+	return migrations[len(migrations)-1].version, nil
+}
+
+func loadMigrations(migrationsPath string) ([]migration, error) {
+	files, err := filepath.Glob(filepath.Join(migrationsPath, "*.up.sql"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migration files: %w", err)
+	}
+
+	migrations := make([]migration, 0, len(files))
+	for _, file := range files {
+		prefix, _, found := strings.Cut(filepath.Base(file), "_")
+		if !found {
+			return nil, fmt.Errorf("invalid migration file name: %s", file)
+		}
+
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", file, err)
+		}
+
+		migrations = append(migrations, migration{version: version, path: file})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	return migrations, nil
+}
+
 func (db *DB) Close() error {
-	return db.DB.Close()
+	db.Pool.Close()
+	return nil
 }
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/idempotency"
+)
+
+type IdempotencyRepository struct {
+	db *DB
+}
+
+func NewIdempotencyRepository(db *DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+func (r *IdempotencyRepository) Reserve(
+	ctx context.Context,
+	scope, key, requestHash string,
+	lockTimeout time.Duration,
+) (*idempotency.Record, error) {
+	// Insert a new key, or take over one whose request never completed
+	// within lockTimeout (e.g. the replica handling it crashed).
+	result, err := r.db.Exec(ctx, `
+		INSERT INTO idempotency_keys (scope, idempotency_key, request_hash)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (scope, idempotency_key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash, created_at = CURRENT_TIMESTAMP
+		WHERE idempotency_keys.completed_at IS NULL
+		  AND idempotency_keys.request_hash = EXCLUDED.request_hash
+		  AND idempotency_keys.created_at < CURRENT_TIMESTAMP - $4 * INTERVAL '1 second'
+	`, scope, key, requestHash, lockTimeout.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	if result.RowsAffected() > 0 {
+		return nil, nil
+	}
+
+	record := &idempotency.Record{}
+	var statusCode *int
+	var contentType, etag *string
+	err = r.db.QueryRow(ctx, `
+		SELECT request_hash, completed_at IS NOT NULL, status_code, content_type, etag, response_body
+		FROM idempotency_keys
+		WHERE scope = $1 AND idempotency_key = $2
+	`, scope, key).Scan(&record.RequestHash, &record.Completed, &statusCode, &contentType, &etag, &record.Body)
+	if err == pgx.ErrNoRows {
+		// Purged between the insert and the select; let the caller retry.
+		return nil, fmt.Errorf("idempotency key %q disappeared during reservation", key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	if statusCode != nil {
+		record.StatusCode = *statusCode
+	}
+	if contentType != nil {
+		record.ContentType = *contentType
+	}
+	if etag != nil {
+		record.ETag = *etag
+	}
+
+	return record, nil
+}
+
+func (r *IdempotencyRepository) Complete(ctx context.Context, scope, key string, response idempotency.Response) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE idempotency_keys
+		SET status_code = $3, content_type = $4, etag = $5, response_body = $6, completed_at = CURRENT_TIMESTAMP
+		WHERE scope = $1 AND idempotency_key = $2
+	`, scope, key, response.StatusCode, response.ContentType, response.ETag, response.Body)
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+func (r *IdempotencyRepository) Release(ctx context.Context, scope, key string) error {
+	_, err := r.db.Exec(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE scope = $1 AND idempotency_key = $2 AND completed_at IS NULL
+	`, scope, key)
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (r *IdempotencyRepository) Purge(ctx context.Context, ttl time.Duration) error {
+	_, err := r.db.Exec(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE created_at < CURRENT_TIMESTAMP - $1 * INTERVAL '1 second'
+	`, ttl.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to purge idempotency keys: %w", err)
+	}
+	return nil
+}
@@ -2,8 +2,10 @@ package postgres
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
 
 	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
 	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
@@ -17,61 +19,73 @@ func NewPRRepository(db *DB) *PRRepository {
 	return &PRRepository{db: db}
 }
 
+// Create inserts the pull request and its reviewers in a single batch. Outside
+// a transaction the batch runs in an implicit one, so a failed reviewer insert
+// leaves no pull request behind.
 func (r *PRRepository) Create(ctx context.Context, pr *domain.PullRequest) error {
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback() //nolint:errcheck
-
 	prQuery := `
-		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at, updated_at, version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 
-	_, err = tx.ExecContext(ctx, prQuery,
+	reviewerQuery := `
+		INSERT INTO pr_reviewers (pull_request_id, reviewer_id, assigned_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP)
+	`
+
+	batch := &pgx.Batch{}
+	batch.Queue(prQuery,
 		pr.PullRequestID,
 		pr.PullRequestName,
 		pr.AuthorID,
 		pr.Status,
 		pr.CreatedAt,
 		pr.UpdatedAt,
+		pr.Version,
 	)
+	for _, reviewerID := range pr.AssignedReviewers {
+		batch.Queue(reviewerQuery, pr.PullRequestID, reviewerID)
+	}
 
-	if err != nil {
+	results := r.db.conn(ctx).SendBatch(ctx, batch)
+	defer results.Close()
+
+	if _, err := results.Exec(); err != nil {
 		return fmt.Errorf("failed to create pull request: %w", err)
 	}
 
-	if len(pr.AssignedReviewers) > 0 {
-		reviewerQuery := `
-			INSERT INTO pr_reviewers (pull_request_id, reviewer_id, assigned_at)
-			VALUES ($1, $2, CURRENT_TIMESTAMP)
-		`
-
-		for _, reviewerID := range pr.AssignedReviewers {
-			_, err = tx.ExecContext(ctx, reviewerQuery, pr.PullRequestID, reviewerID)
-			if err != nil {
-				return fmt.Errorf("failed to assign reviewer %s: %w", reviewerID, err)
-			}
+	for _, reviewerID := range pr.AssignedReviewers {
+		if _, err := results.Exec(); err != nil {
+			return fmt.Errorf("failed to assign reviewer %s: %w", reviewerID, err)
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	if err := results.Close(); err != nil {
+		return fmt.Errorf("failed to create pull request: %w", err)
 	}
 
 	return nil
 }
 
+// GetByID loads the pull request together with its reviewers, aggregated in
+// assignment order, in one query.
 func (r *PRRepository) GetByID(ctx context.Context, prID string) (*domain.PullRequest, error) {
-	prQuery := `
-		SELECT pull_request_id, pull_request_name, author_id, status, created_at, updated_at, merged_at
-		FROM pull_requests
-		WHERE pull_request_id = $1
+	query := `
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status,
+		       pr.created_at, pr.updated_at, pr.merged_at, pr.version,
+		       COALESCE(array_agg(prr.reviewer_id ORDER BY prr.assigned_at, prr.reviewer_id)
+		                FILTER (WHERE prr.reviewer_id IS NOT NULL), '{}'),
+		       COALESCE(array_agg(prr.assigned_at ORDER BY prr.assigned_at, prr.reviewer_id)
+		                FILTER (WHERE prr.reviewer_id IS NOT NULL), '{}')
+		FROM pull_requests pr
+		LEFT JOIN pr_reviewers prr ON prr.pull_request_id = pr.pull_request_id
+		WHERE pr.pull_request_id = $1 AND pr.deleted_at IS NULL
+		GROUP BY pr.pull_request_id
 	`
 
 	pr := &domain.PullRequest{}
-	err := r.db.QueryRowContext(ctx, prQuery, prID).Scan(
+	var assignedAt []time.Time
+	err := r.db.conn(ctx).QueryRow(ctx, query, prID).Scan(
 		&pr.PullRequestID,
 		&pr.PullRequestName,
 		&pr.AuthorID,
@@ -79,9 +93,12 @@ func (r *PRRepository) GetByID(ctx context.Context, prID string) (*domain.PullRe
 		&pr.CreatedAt,
 		&pr.UpdatedAt,
 		&pr.MergedAt,
+		&pr.Version,
+		&pr.AssignedReviewers,
+		&assignedAt,
 	)
 
-	if err == sql.ErrNoRows {
+	if err == pgx.ErrNoRows {
 		return nil, errors.ErrPRNotFound(prID)
 	}
 
@@ -89,84 +106,81 @@ func (r *PRRepository) GetByID(ctx context.Context, prID string) (*domain.PullRe
 		return nil, fmt.Errorf("failed to get pull request: %w", err)
 	}
 
-	reviewerQuery := `
-		SELECT reviewer_id
-		FROM pr_reviewers
-		WHERE pull_request_id = $1
-		ORDER BY assigned_at
-	`
-
-	rows, err := r.db.QueryContext(ctx, reviewerQuery, prID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get reviewers: %w", err)
+	pr.ReviewerAssignments = make([]domain.ReviewerAssignment, len(pr.AssignedReviewers))
+	for i, reviewerID := range pr.AssignedReviewers {
+		pr.ReviewerAssignments[i] = domain.ReviewerAssignment{ReviewerID: reviewerID, AssignedAt: assignedAt[i]}
 	}
-	defer rows.Close()
 
-	reviewers := make([]string, 0, 2)
-	for rows.Next() {
-		var reviewerID string
-		if err := rows.Scan(&reviewerID); err != nil {
-			return nil, fmt.Errorf("failed to scan reviewer: %w", err)
-		}
-		reviewers = append(reviewers, reviewerID)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating reviewers: %w", err)
-	}
-
-	pr.AssignedReviewers = reviewers
 	return pr, nil
 }
 
+// Update saves pr if it is still at pr.Version and increments the version.
+// It returns a CONFLICT error if the pull request was changed in the meantime.
 func (r *PRRepository) Update(ctx context.Context, pr *domain.PullRequest) error {
 	query := `
 		UPDATE pull_requests
-		SET pull_request_name = $2, status = $3, updated_at = $4, merged_at = $5
-		WHERE pull_request_id = $1
+		SET pull_request_name = $2, status = $3, updated_at = $4, merged_at = $5, version = version + 1
+		WHERE pull_request_id = $1 AND version = $6 AND deleted_at IS NULL
+		RETURNING version
 	`
 
-	result, err := r.db.ExecContext(ctx, query,
+	var version int
+	err := r.db.conn(ctx).QueryRow(ctx, query,
 		pr.PullRequestID,
 		pr.PullRequestName,
 		pr.Status,
 		pr.UpdatedAt,
 		pr.MergedAt,
-	)
+		pr.Version,
+	).Scan(&version)
+
+	if err == pgx.ErrNoRows {
+		return r.versionConflict(ctx, r.db.conn(ctx), pr.PullRequestID)
+	}
 
 	if err != nil {
 		return fmt.Errorf("failed to update pull request: %w", err)
 	}
 
-	rows, err := result.RowsAffected()
+	pr.Version = version
+	return nil
+}
+
+// ReplaceReviewer swaps a reviewer if the pull request is still at
+// expectedVersion, incrementing the version in the same transaction.
+func (r *PRRepository) ReplaceReviewer(ctx context.Context, prID string, expectedVersion int, oldReviewerID, newReviewerID string) error {
+	tx, err := r.db.beginTx(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	versionQuery := `
+		UPDATE pull_requests
+		SET version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE pull_request_id = $1 AND version = $2 AND deleted_at IS NULL
+	`
 
-	if rows == 0 {
-		return errors.ErrPRNotFound(pr.PullRequestID)
+	result, err := tx.Exec(ctx, versionQuery, prID, expectedVersion)
+	if err != nil {
+		return fmt.Errorf("failed to update pull request version: %w", err)
 	}
 
-	return nil
-}
+	if result.RowsAffected() == 0 {
+		return r.versionConflict(ctx, tx, prID)
+	}
 
-func (r *PRRepository) ReplaceReviewer(ctx context.Context, prID, oldReviewerID, newReviewerID string) error {
 	deleteQuery := `
 		DELETE FROM pr_reviewers
 		WHERE pull_request_id = $1 AND reviewer_id = $2
 	`
 
-	result, err := r.db.ExecContext(ctx, deleteQuery, prID, oldReviewerID)
+	result, err = tx.Exec(ctx, deleteQuery, prID, oldReviewerID)
 	if err != nil {
 		return fmt.Errorf("failed to delete old reviewer: %w", err)
 	}
 
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
-
-	if rows == 0 {
+	if result.RowsAffected() == 0 {
 		return errors.ErrNotAssigned(oldReviewerID, prID)
 	}
 
@@ -175,24 +189,44 @@ func (r *PRRepository) ReplaceReviewer(ctx context.Context, prID, oldReviewerID,
 		VALUES ($1, $2, CURRENT_TIMESTAMP)
 	`
 
-	_, err = r.db.ExecContext(ctx, insertQuery, prID, newReviewerID)
+	_, err = tx.Exec(ctx, insertQuery, prID, newReviewerID)
 	if err != nil {
 		return fmt.Errorf("failed to insert new reviewer: %w", err)
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
+// versionConflict explains why a version-checked write matched no rows: the
+// pull request is either gone or was changed by someone else.
+func (r *PRRepository) versionConflict(ctx context.Context, q executor, prID string) error {
+	var exists bool
+	err := q.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1 AND deleted_at IS NULL)`, prID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check PR existence: %w", err)
+	}
+
+	if !exists {
+		return errors.ErrPRNotFound(prID)
+	}
+
+	return errors.ErrConcurrentModification(prID)
+}
+
 func (r *PRRepository) GetByReviewer(ctx context.Context, reviewerID string) ([]*domain.PullRequest, error) {
 	query := `
 		SELECT DISTINCT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at
 		FROM pull_requests pr
 		INNER JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
-		WHERE prr.reviewer_id = $1
+		WHERE prr.reviewer_id = $1 AND pr.deleted_at IS NULL
 		ORDER BY pr.created_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, reviewerID)
+	rows, err := r.db.conn(ctx).Query(ctx, query, reviewerID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get PRs by reviewer: %w", err)
 	}
@@ -222,11 +256,16 @@ func (r *PRRepository) GetByReviewer(ctx context.Context, reviewerID string) ([]
 	return prs, nil
 }
 
+// Exists reports whether the pull request ID is taken, including by a deleted
+// or archived pull request.
 func (r *PRRepository) Exists(ctx context.Context, prID string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1)`
+	query := `
+		SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1)
+		    OR EXISTS(SELECT 1 FROM pull_requests_archive WHERE pull_request_id = $1)
+	`
 
 	var exists bool
-	err := r.db.QueryRowContext(ctx, query, prID).Scan(&exists)
+	err := r.db.conn(ctx).QueryRow(ctx, query, prID).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("failed to check PR existence: %w", err)
 	}
@@ -234,15 +273,9 @@ func (r *PRRepository) Exists(ctx context.Context, prID string) (bool, error) {
 	return exists, nil
 }
 
-type PRReviewerInfo struct {
-	PullRequestID string
-	ReviewerID    string
-	ReviewerTeam  string
-}
-
-func (r *PRRepository) GetOpenPRsWithReviewers(ctx context.Context, reviewerIDs []string) ([]PRReviewerInfo, error) {
+func (r *PRRepository) GetOpenPRsWithReviewers(ctx context.Context, reviewerIDs []string) ([]domain.PRReviewerInfo, error) {
 	if len(reviewerIDs) == 0 {
-		return []PRReviewerInfo{}, nil
+		return []domain.PRReviewerInfo{}, nil
 	}
 
 	query := `
@@ -250,19 +283,19 @@ func (r *PRRepository) GetOpenPRsWithReviewers(ctx context.Context, reviewerIDs
 		FROM pull_requests pr
 		INNER JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
 		INNER JOIN users u ON prr.reviewer_id = u.user_id
-		WHERE pr.status = 'OPEN' AND prr.reviewer_id = ANY($1)
+		WHERE pr.status = 'OPEN' AND pr.deleted_at IS NULL AND prr.reviewer_id = ANY($1)
 		ORDER BY pr.created_at
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, reviewerIDs)
+	rows, err := r.db.conn(ctx).Query(ctx, query, reviewerIDs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get open PRs with reviewers: %w", err)
 	}
 	defer rows.Close()
 
-	infos := make([]PRReviewerInfo, 0)
+	infos := make([]domain.PRReviewerInfo, 0)
 	for rows.Next() {
-		var info PRReviewerInfo
+		var info domain.PRReviewerInfo
 		err := rows.Scan(&info.PullRequestID, &info.ReviewerID, &info.ReviewerTeam)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan PR reviewer info: %w", err)
@@ -276,3 +309,81 @@ func (r *PRRepository) GetOpenPRsWithReviewers(ctx context.Context, reviewerIDs
 
 	return infos, nil
 }
+
+// Delete soft-deletes the pull request. Its reviewers no longer count as open
+// reviews and it drops out of the statistics.
+func (r *PRRepository) Delete(ctx context.Context, prID string) error {
+	query := `
+		UPDATE pull_requests
+		SET deleted_at = CURRENT_TIMESTAMP
+		WHERE pull_request_id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.conn(ctx).Exec(ctx, query, prID)
+	if err != nil {
+		return fmt.Errorf("failed to delete pull request: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.ErrPRNotFound(prID)
+	}
+
+	return nil
+}
+
+// Restore undoes Delete. Archived pull requests cannot be restored.
+func (r *PRRepository) Restore(ctx context.Context, prID string) error {
+	query := `
+		UPDATE pull_requests
+		SET deleted_at = NULL
+		WHERE pull_request_id = $1
+	`
+
+	result, err := r.db.conn(ctx).Exec(ctx, query, prID)
+	if err != nil {
+		return fmt.Errorf("failed to restore pull request: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.ErrPRNotFound(prID)
+	}
+
+	return nil
+}
+
+// ArchiveMerged moves pull requests merged before mergedBefore, deleted ones
+// included, to the archive tables and returns how many were moved. It is a
+// single statement: every CTE reads the same snapshot, so the reviewers are
+// copied before the cascade from the DELETE removes them.
+func (r *PRRepository) ArchiveMerged(ctx context.Context, mergedBefore time.Time) (int, error) {
+	query := `
+		WITH moved AS (
+			DELETE FROM pull_requests
+			WHERE status = 'MERGED' AND merged_at < $1
+			RETURNING pull_request_id, pull_request_name, author_id, status,
+			          created_at, updated_at, merged_at, version, deleted_at
+		),
+		moved_reviewers AS (
+			INSERT INTO pr_reviewers_archive (pull_request_id, reviewer_id, assigned_at)
+			SELECT prr.pull_request_id, prr.reviewer_id, prr.assigned_at
+			FROM pr_reviewers prr
+			INNER JOIN moved m ON m.pull_request_id = prr.pull_request_id
+		),
+		archived AS (
+			INSERT INTO pull_requests_archive (pull_request_id, pull_request_name, author_id, status,
+			                                   created_at, updated_at, merged_at, version, deleted_at)
+			SELECT pull_request_id, pull_request_name, author_id, status,
+			       created_at, updated_at, merged_at, version, deleted_at
+			FROM moved
+			RETURNING pull_request_id
+		)
+		SELECT COUNT(*) FROM archived
+	`
+
+	var count int
+	if err := r.db.conn(ctx).QueryRow(ctx, query, mergedBefore).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to archive merged pull requests: %w", err)
+	}
+
+	return count, nil
+}
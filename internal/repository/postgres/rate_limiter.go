@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/ratelimit"
+)
+
+// RateLimiter stores token buckets in Postgres so that all replicas share
+// one limit per caller. Bucket state is computed against the database clock.
+type RateLimiter struct {
+	db *DB
+}
+
+func NewRateLimiter(db *DB) *RateLimiter {
+	return &RateLimiter{db: db}
+}
+
+func (l *RateLimiter) Allow(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Decision, error) {
+	tx, err := l.db.Begin(ctx)
+	if err != nil {
+		return ratelimit.Decision{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO rate_limit_buckets (bucket_key, tokens, updated_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP)
+		ON CONFLICT (bucket_key) DO NOTHING
+	`, key, limit.Burst)
+	if err != nil {
+		return ratelimit.Decision{}, fmt.Errorf("failed to create rate limit bucket: %w", err)
+	}
+
+	var bucket ratelimit.Bucket
+	var now time.Time
+	err = tx.QueryRow(ctx, `
+		SELECT tokens, updated_at, CURRENT_TIMESTAMP
+		FROM rate_limit_buckets
+		WHERE bucket_key = $1
+		FOR UPDATE
+	`, key).Scan(&bucket.Tokens, &bucket.Updated, &now)
+	if err != nil {
+		return ratelimit.Decision{}, fmt.Errorf("failed to lock rate limit bucket: %w", err)
+	}
+
+	decision := bucket.Take(limit, now)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE rate_limit_buckets
+		SET tokens = $2, updated_at = $3
+		WHERE bucket_key = $1
+	`, key, bucket.Tokens, bucket.Updated)
+	if err != nil {
+		return ratelimit.Decision{}, fmt.Errorf("failed to update rate limit bucket: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return ratelimit.Decision{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return decision, nil
+}
+
+func (l *RateLimiter) Purge(ctx context.Context, idle time.Duration) error {
+	_, err := l.db.Exec(ctx, `
+		DELETE FROM rate_limit_buckets
+		WHERE updated_at < CURRENT_TIMESTAMP - $1 * INTERVAL '1 second'
+	`, idle.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to purge rate limit buckets: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,294 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+)
+
+type SnapshotRepository struct {
+	db *DB
+}
+
+func NewSnapshotRepository(db *DB) *SnapshotRepository {
+	return &SnapshotRepository{db: db}
+}
+
+// Export reads teams, users and pull requests with one query each. Members
+// of a live team are always live, so users of deleted teams never show up.
+func (r *SnapshotRepository) Export(ctx context.Context) (*domain.Snapshot, error) {
+	teams, err := r.exportTeams(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	prs, err := r.exportPullRequests(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Snapshot{Teams: teams, PullRequests: prs}, nil
+}
+
+func (r *SnapshotRepository) exportTeams(ctx context.Context) ([]*domain.Team, error) {
+	teamQuery := `
+		SELECT team_name, created_at
+		FROM teams
+		WHERE deleted_at IS NULL
+		ORDER BY created_at, team_name
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, teamQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export teams: %w", err)
+	}
+	defer rows.Close()
+
+	teams := make([]*domain.Team, 0)
+	byName := make(map[string]*domain.Team)
+	for rows.Next() {
+		team := &domain.Team{Members: make([]*domain.User, 0)}
+		if err := rows.Scan(&team.TeamName, &team.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan team: %w", err)
+		}
+		teams = append(teams, team)
+		byName[team.TeamName] = team
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating teams: %w", err)
+	}
+
+	userQuery := `
+		SELECT user_id, username, team_name, is_active, created_at, updated_at
+		FROM users
+		WHERE deleted_at IS NULL
+		ORDER BY created_at, user_id
+	`
+
+	rows, err = r.db.conn(ctx).Query(ctx, userQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export users: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user := &domain.User{}
+		err := rows.Scan(
+			&user.UserID,
+			&user.Username,
+			&user.TeamName,
+			&user.IsActive,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		if team, ok := byName[user.TeamName]; ok {
+			team.Members = append(team.Members, user)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return teams, nil
+}
+
+func (r *SnapshotRepository) exportPullRequests(ctx context.Context) ([]*domain.PullRequest, error) {
+	query := `
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status,
+		       pr.created_at, pr.updated_at, pr.merged_at, pr.version,
+		       COALESCE(array_agg(prr.reviewer_id ORDER BY prr.assigned_at, prr.reviewer_id)
+		                FILTER (WHERE prr.reviewer_id IS NOT NULL), '{}'),
+		       COALESCE(array_agg(prr.assigned_at ORDER BY prr.assigned_at, prr.reviewer_id)
+		                FILTER (WHERE prr.reviewer_id IS NOT NULL), '{}')
+		FROM pull_requests pr
+		LEFT JOIN pr_reviewers prr ON prr.pull_request_id = pr.pull_request_id
+		WHERE pr.deleted_at IS NULL
+		GROUP BY pr.pull_request_id
+		ORDER BY pr.created_at, pr.pull_request_id
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export pull requests: %w", err)
+	}
+	defer rows.Close()
+
+	prs := make([]*domain.PullRequest, 0)
+	for rows.Next() {
+		pr := &domain.PullRequest{}
+		var assignedAt []time.Time
+		err := rows.Scan(
+			&pr.PullRequestID,
+			&pr.PullRequestName,
+			&pr.AuthorID,
+			&pr.Status,
+			&pr.CreatedAt,
+			&pr.UpdatedAt,
+			&pr.MergedAt,
+			&pr.Version,
+			&pr.AssignedReviewers,
+			&assignedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pull request: %w", err)
+		}
+
+		pr.ReviewerAssignments = make([]domain.ReviewerAssignment, len(pr.AssignedReviewers))
+		for i, reviewerID := range pr.AssignedReviewers {
+			pr.ReviewerAssignments[i] = domain.ReviewerAssignment{ReviewerID: reviewerID, AssignedAt: assignedAt[i]}
+		}
+		prs = append(prs, pr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pull requests: %w", err)
+	}
+
+	return prs, nil
+}
+
+func (r *SnapshotRepository) FindExisting(ctx context.Context, snapshot *domain.Snapshot) (*domain.ExistingRecords, error) {
+	teamNames, userIDs, prIDs := snapshot.IDs()
+
+	query := `
+		SELECT 'team', team_name, '' FROM teams WHERE team_name = ANY($1)
+		UNION ALL
+		SELECT 'user', user_id, '' FROM users WHERE user_id = ANY($2)
+		UNION ALL
+		SELECT 'pull_request', pull_request_id, '' FROM pull_requests WHERE pull_request_id = ANY($3)
+		UNION ALL
+		SELECT 'archived', pull_request_id, '' FROM pull_requests_archive WHERE pull_request_id = ANY($3)
+		UNION ALL
+		SELECT 'username', username, user_id FROM users WHERE username = ANY($4)
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, teamNames, userIDs, prIDs, snapshot.Usernames())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find existing records: %w", err)
+	}
+	defer rows.Close()
+
+	existing := domain.NewExistingRecords()
+	for rows.Next() {
+		var kind, id, owner string
+		if err := rows.Scan(&kind, &id, &owner); err != nil {
+			return nil, fmt.Errorf("failed to scan existing record: %w", err)
+		}
+
+		switch kind {
+		case "team":
+			existing.Teams[id] = true
+		case "user":
+			existing.Users[id] = true
+		case "pull_request":
+			existing.PullRequests[id] = true
+		case "archived":
+			existing.ArchivedPullRequests[id] = true
+		case "username":
+			existing.UsernameOwners[id] = owner
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating existing records: %w", err)
+	}
+
+	return existing, nil
+}
+
+// Import sends every upsert in one batch: teams first, then their members,
+// then the pull requests with their reviewers replaced.
+func (r *SnapshotRepository) Import(ctx context.Context, snapshot *domain.Snapshot) error {
+	teamQuery := `
+		INSERT INTO teams (team_name, created_at)
+		VALUES ($1, $2)
+		ON CONFLICT (team_name) DO UPDATE
+		SET created_at = EXCLUDED.created_at,
+		    deleted_at = NULL
+	`
+
+	userQuery := `
+		INSERT INTO users (user_id, username, team_name, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id) DO UPDATE
+		SET username = EXCLUDED.username,
+		    team_name = EXCLUDED.team_name,
+		    is_active = EXCLUDED.is_active,
+		    created_at = EXCLUDED.created_at,
+		    updated_at = EXCLUDED.updated_at,
+		    deleted_at = NULL
+	`
+
+	prQuery := `
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at, updated_at, merged_at, version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (pull_request_id) DO UPDATE
+		SET pull_request_name = EXCLUDED.pull_request_name,
+		    author_id = EXCLUDED.author_id,
+		    status = EXCLUDED.status,
+		    created_at = EXCLUDED.created_at,
+		    updated_at = EXCLUDED.updated_at,
+		    merged_at = EXCLUDED.merged_at,
+		    version = EXCLUDED.version,
+		    deleted_at = NULL
+	`
+
+	clearReviewersQuery := `DELETE FROM pr_reviewers WHERE pull_request_id = $1`
+
+	reviewerQuery := `
+		INSERT INTO pr_reviewers (pull_request_id, reviewer_id, assigned_at)
+		VALUES ($1, $2, $3)
+	`
+
+	batch := &pgx.Batch{}
+	for _, team := range snapshot.Teams {
+		batch.Queue(teamQuery, team.TeamName, team.CreatedAt)
+	}
+	for _, team := range snapshot.Teams {
+		for _, member := range team.Members {
+			batch.Queue(userQuery,
+				member.UserID,
+				member.Username,
+				member.TeamName,
+				member.IsActive,
+				member.CreatedAt,
+				member.UpdatedAt,
+			)
+		}
+	}
+	for _, pr := range snapshot.PullRequests {
+		batch.Queue(prQuery,
+			pr.PullRequestID,
+			pr.PullRequestName,
+			pr.AuthorID,
+			pr.Status,
+			pr.CreatedAt,
+			pr.UpdatedAt,
+			pr.MergedAt,
+			pr.Version,
+		)
+		batch.Queue(clearReviewersQuery, pr.PullRequestID)
+		for _, assignment := range pr.ReviewerAssignments {
+			batch.Queue(reviewerQuery, pr.PullRequestID, assignment.ReviewerID, assignment.AssignedAt)
+		}
+	}
+
+	if batch.Len() == 0 {
+		return nil
+	}
+
+	if err := r.db.conn(ctx).SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to import snapshot: %w", err)
+	}
+
+	return nil
+}
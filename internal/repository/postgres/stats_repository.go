@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
 )
 
 type StatsRepository struct {
@@ -15,28 +16,63 @@ func NewStatsRepository(db *DB) *StatsRepository {
 	return &StatsRepository{db: db}
 }
 
-func (r *StatsRepository) GetStatistics(ctx context.Context) (*domain.Statistics, error) {
+// statsSources are the CTEs the statistics read pull requests and review
+// assignments from. They cover live and archived pull requests, so archiving
+// does not change the numbers, and leave out deleted ones.
+const statsSources = `
+	stats_prs AS (
+		SELECT pull_request_id, author_id, status, created_at, merged_at
+		FROM pull_requests
+		WHERE deleted_at IS NULL
+		UNION ALL
+		SELECT pull_request_id, author_id, status, created_at, merged_at
+		FROM pull_requests_archive
+		WHERE deleted_at IS NULL
+	),
+	stats_reviewers AS (
+		SELECT prr.pull_request_id, prr.reviewer_id, prr.assigned_at
+		FROM pr_reviewers prr
+		INNER JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+		WHERE pr.deleted_at IS NULL
+		UNION ALL
+		SELECT prr.pull_request_id, prr.reviewer_id, prr.assigned_at
+		FROM pr_reviewers_archive prr
+		INNER JOIN pull_requests_archive pr ON pr.pull_request_id = prr.pull_request_id
+		WHERE pr.deleted_at IS NULL
+	)`
+
+func (r *StatsRepository) GetStatistics(ctx context.Context, filter domain.StatsFilter) (*domain.Statistics, error) {
 	stats := &domain.Statistics{}
 
-	prStats, err := r.getPullRequestStats(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get PR stats: %w", err)
+	if filter.From != nil || filter.To != nil {
+		stats.Period = &domain.StatsPeriod{
+			From: filter.From,
+			To:   filter.To,
+		}
 	}
-	stats.PullRequests = prStats
 
-	userStats, err := r.getUserStats(ctx)
+	breakdowns, err := r.getTeamBreakdowns(ctx, filter)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user stats: %w", err)
+		return nil, fmt.Errorf("failed to get team breakdowns: %w", err)
 	}
-	stats.Users = userStats
 
-	teamStats, err := r.getTeamStats(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get team stats: %w", err)
+	if filter.TeamName != "" && len(breakdowns) == 0 {
+		return nil, errors.ErrTeamNotFound(filter.TeamName)
 	}
-	stats.Teams = teamStats
 
-	topReviewers, err := r.getTopReviewers(ctx)
+	for _, b := range breakdowns {
+		stats.PullRequests.Total += b.PullRequests.Total
+		stats.PullRequests.Open += b.PullRequests.Open
+		stats.PullRequests.Merged += b.PullRequests.Merged
+
+		stats.Users.Total += b.Users.Total
+		stats.Users.Active += b.Users.Active
+		stats.Users.Inactive += b.Users.Inactive
+	}
+	stats.Teams.Total = len(breakdowns)
+	stats.ByTeam = breakdowns
+
+	topReviewers, err := r.getTopReviewers(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get top reviewers: %w", err)
 	}
@@ -45,69 +81,122 @@ func (r *StatsRepository) GetStatistics(ctx context.Context) (*domain.Statistics
 	return stats, nil
 }
 
-func (r *StatsRepository) getPullRequestStats(ctx context.Context) (domain.PullRequestStats, error) {
+// PRs are attributed to the author's team and review assignments to the
+// reviewer's team. PR totals and open counts use created_at, merged counts
+// use merged_at and review assignments use pr_reviewers.assigned_at. Deleted
+// teams and users are not listed, but the PRs and reviews of deleted users
+// still count for their team.
+func (r *StatsRepository) getTeamBreakdowns(ctx context.Context, filter domain.StatsFilter) ([]domain.TeamBreakdown, error) {
 	query := `
+		WITH` + statsSources + `,
+		team_users AS (
+			SELECT
+				t.team_name,
+				COUNT(u.user_id) as total,
+				COUNT(u.user_id) FILTER (WHERE u.is_active = true) as active,
+				COUNT(u.user_id) FILTER (WHERE u.is_active = false) as inactive
+			FROM teams t
+			LEFT JOIN users u ON u.team_name = t.team_name AND u.deleted_at IS NULL
+			WHERE t.deleted_at IS NULL AND ($3 = '' OR t.team_name = $3)
+			GROUP BY t.team_name
+		),
+		team_prs AS (
+			SELECT
+				a.team_name,
+				COUNT(*) FILTER (
+					WHERE ($1::timestamp IS NULL OR pr.created_at >= $1::timestamp)
+					  AND ($2::timestamp IS NULL OR pr.created_at < $2::timestamp)
+				) as total,
+				COUNT(*) FILTER (
+					WHERE pr.status = 'OPEN'
+					  AND ($1::timestamp IS NULL OR pr.created_at >= $1::timestamp)
+					  AND ($2::timestamp IS NULL OR pr.created_at < $2::timestamp)
+				) as open,
+				COUNT(*) FILTER (
+					WHERE pr.merged_at IS NOT NULL
+					  AND ($1::timestamp IS NULL OR pr.merged_at >= $1::timestamp)
+					  AND ($2::timestamp IS NULL OR pr.merged_at < $2::timestamp)
+				) as merged
+			FROM stats_prs pr
+			INNER JOIN users a ON a.user_id = pr.author_id
+			GROUP BY a.team_name
+		),
+		team_reviews AS (
+			SELECT
+				u.team_name,
+				COUNT(*) as assignments
+			FROM stats_reviewers prr
+			INNER JOIN users u ON u.user_id = prr.reviewer_id
+			WHERE ($1::timestamp IS NULL OR prr.assigned_at >= $1::timestamp)
+			  AND ($2::timestamp IS NULL OR prr.assigned_at < $2::timestamp)
+			GROUP BY u.team_name
+		)
 		SELECT
-			COUNT(*) as total,
-			COUNT(*) FILTER (WHERE status = 'OPEN') as open,
-			COUNT(*) FILTER (WHERE status = 'MERGED') as merged
-		FROM pull_requests
+			tu.team_name,
+			tu.total,
+			tu.active,
+			tu.inactive,
+			COALESCE(tp.total, 0),
+			COALESCE(tp.open, 0),
+			COALESCE(tp.merged, 0),
+			COALESCE(tr.assignments, 0)
+		FROM team_users tu
+		LEFT JOIN team_prs tp ON tp.team_name = tu.team_name
+		LEFT JOIN team_reviews tr ON tr.team_name = tu.team_name
+		ORDER BY tu.team_name
 	`
 
-	var stats domain.PullRequestStats
-	err := r.db.QueryRowContext(ctx, query).Scan(&stats.Total, &stats.Open, &stats.Merged)
+	rows, err := r.db.conn(ctx).Query(ctx, query, filter.From, filter.To, filter.TeamName)
 	if err != nil {
-		return domain.PullRequestStats{}, err
+		return nil, err
 	}
+	defer rows.Close()
 
-	return stats, nil
-}
-
-func (r *StatsRepository) getUserStats(ctx context.Context) (domain.UserStats, error) {
-	query := `
-		SELECT
-			COUNT(*) as total,
-			COUNT(*) FILTER (WHERE is_active = true) as active,
-			COUNT(*) FILTER (WHERE is_active = false) as inactive
-		FROM users
-	`
-
-	var stats domain.UserStats
-	err := r.db.QueryRowContext(ctx, query).Scan(&stats.Total, &stats.Active, &stats.Inactive)
-	if err != nil {
-		return domain.UserStats{}, err
+	breakdowns := make([]domain.TeamBreakdown, 0)
+	for rows.Next() {
+		var b domain.TeamBreakdown
+		err := rows.Scan(
+			&b.TeamName,
+			&b.Users.Total,
+			&b.Users.Active,
+			&b.Users.Inactive,
+			&b.PullRequests.Total,
+			&b.PullRequests.Open,
+			&b.PullRequests.Merged,
+			&b.ReviewAssignments,
+		)
+		if err != nil {
+			return nil, err
+		}
+		breakdowns = append(breakdowns, b)
 	}
 
-	return stats, nil
-}
-
-func (r *StatsRepository) getTeamStats(ctx context.Context) (domain.TeamStats, error) {
-	query := `SELECT COUNT(DISTINCT team_name) FROM users WHERE team_name IS NOT NULL`
-
-	var stats domain.TeamStats
-	err := r.db.QueryRowContext(ctx, query).Scan(&stats.Total)
-	if err != nil {
-		return domain.TeamStats{}, err
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return stats, nil
+	return breakdowns, nil
 }
 
-func (r *StatsRepository) getTopReviewers(ctx context.Context) ([]domain.ReviewerStat, error) {
+func (r *StatsRepository) getTopReviewers(ctx context.Context, filter domain.StatsFilter) ([]domain.ReviewerStat, error) {
 	query := `
+		WITH` + statsSources + `
 		SELECT
 			u.user_id,
 			u.username,
 			COUNT(DISTINCT prr.pull_request_id) as review_count
 		FROM users u
-		LEFT JOIN pr_reviewers prr ON u.user_id = prr.reviewer_id
+		INNER JOIN stats_reviewers prr ON u.user_id = prr.reviewer_id
+		WHERE u.deleted_at IS NULL
+		  AND ($1::timestamp IS NULL OR prr.assigned_at >= $1::timestamp)
+		  AND ($2::timestamp IS NULL OR prr.assigned_at < $2::timestamp)
+		  AND ($3 = '' OR u.team_name = $3)
 		GROUP BY u.user_id, u.username
-		HAVING COUNT(DISTINCT prr.pull_request_id) > 0
 		ORDER BY review_count DESC
 		LIMIT 10
 	`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := r.db.conn(ctx).Query(ctx, query, filter.From, filter.To, filter.TeamName)
 	if err != nil {
 		return nil, err
 	}
@@ -129,3 +218,109 @@ func (r *StatsRepository) getTopReviewers(ctx context.Context) ([]domain.Reviewe
 
 	return reviewers, nil
 }
+
+func (r *StatsRepository) GetMergedPRTimings(ctx context.Context, filter domain.StatsFilter) ([]domain.MergedPRTiming, error) {
+	if filter.TeamName != "" {
+		if err := r.ensureTeamExists(ctx, filter.TeamName); err != nil {
+			return nil, err
+		}
+	}
+
+	query := `
+		WITH` + statsSources + `
+		SELECT pr.pull_request_id, pr.author_id, a.team_name, pr.created_at, pr.merged_at
+		FROM stats_prs pr
+		INNER JOIN users a ON a.user_id = pr.author_id
+		WHERE pr.merged_at IS NOT NULL
+		  AND ($1::timestamp IS NULL OR pr.merged_at >= $1::timestamp)
+		  AND ($2::timestamp IS NULL OR pr.merged_at < $2::timestamp)
+		  AND ($3 = '' OR a.team_name = $3)
+		ORDER BY pr.merged_at
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, filter.From, filter.To, filter.TeamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merged PR timings: %w", err)
+	}
+	defer rows.Close()
+
+	timings := make([]domain.MergedPRTiming, 0)
+	for rows.Next() {
+		var t domain.MergedPRTiming
+		err := rows.Scan(&t.PullRequestID, &t.AuthorID, &t.TeamName, &t.CreatedAt, &t.MergedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan merged PR timing: %w", err)
+		}
+		timings = append(timings, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating merged PR timings: %w", err)
+	}
+
+	return timings, nil
+}
+
+func (r *StatsRepository) ensureTeamExists(ctx context.Context, teamName string) error {
+	query := `SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1 AND deleted_at IS NULL)`
+
+	var exists bool
+	if err := r.db.conn(ctx).QueryRow(ctx, query, teamName).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check team existence: %w", err)
+	}
+
+	if !exists {
+		return errors.ErrTeamNotFound(teamName)
+	}
+
+	return nil
+}
+
+func (r *StatsRepository) GetReviewerWorkload(ctx context.Context, filter domain.StatsFilter) ([]domain.ReviewerWorkload, error) {
+	if filter.TeamName != "" {
+		if err := r.ensureTeamExists(ctx, filter.TeamName); err != nil {
+			return nil, err
+		}
+	}
+
+	query := `
+		WITH` + statsSources + `
+		SELECT
+			u.user_id,
+			u.username,
+			u.team_name,
+			COUNT(prr.pull_request_id) FILTER (WHERE pr.status = 'OPEN') as open_reviews,
+			COUNT(prr.pull_request_id) FILTER (
+				WHERE ($1::timestamp IS NULL OR prr.assigned_at >= $1::timestamp)
+				  AND ($2::timestamp IS NULL OR prr.assigned_at < $2::timestamp)
+			) as assigned_reviews
+		FROM users u
+		LEFT JOIN stats_reviewers prr ON prr.reviewer_id = u.user_id
+		LEFT JOIN stats_prs pr ON pr.pull_request_id = prr.pull_request_id
+		WHERE u.is_active = true AND u.deleted_at IS NULL AND ($3 = '' OR u.team_name = $3)
+		GROUP BY u.user_id, u.username, u.team_name
+		ORDER BY u.team_name, u.user_id
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, filter.From, filter.To, filter.TeamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviewer workload: %w", err)
+	}
+	defer rows.Close()
+
+	workloads := make([]domain.ReviewerWorkload, 0)
+	for rows.Next() {
+		var w domain.ReviewerWorkload
+		err := rows.Scan(&w.UserID, &w.Username, &w.TeamName, &w.OpenReviews, &w.AssignedReviews)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reviewer workload: %w", err)
+		}
+		workloads = append(workloads, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reviewer workload: %w", err)
+	}
+
+	return workloads, nil
+}
@@ -2,8 +2,10 @@ package postgres
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
 
 	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
 	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
@@ -17,23 +19,15 @@ func NewTeamRepository(db *DB) *TeamRepository {
 	return &TeamRepository{db: db}
 }
 
+// Create inserts the team and upserts its members in a single batch. Outside a
+// transaction the batch runs in an implicit one, so a failed member insert
+// leaves no team behind. Upserting a deleted user restores them.
 func (r *TeamRepository) Create(ctx context.Context, team *domain.Team) error {
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback() //nolint:errcheck
-
 	teamQuery := `
 		INSERT INTO teams (team_name, created_at)
 		VALUES ($1, $2)
 	`
 
-	_, err = tx.ExecContext(ctx, teamQuery, team.TeamName, team.CreatedAt)
-	if err != nil {
-		return fmt.Errorf("failed to create team: %w", err)
-	}
-
 	userQuery := `
 		INSERT INTO users (user_id, username, team_name, is_active, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6)
@@ -41,11 +35,14 @@ func (r *TeamRepository) Create(ctx context.Context, team *domain.Team) error {
 		SET username = EXCLUDED.username,
 		    team_name = EXCLUDED.team_name,
 		    is_active = EXCLUDED.is_active,
-		    updated_at = EXCLUDED.updated_at
+		    updated_at = EXCLUDED.updated_at,
+		    deleted_at = NULL
 	`
 
+	batch := &pgx.Batch{}
+	batch.Queue(teamQuery, team.TeamName, team.CreatedAt)
 	for _, member := range team.Members {
-		_, err = tx.ExecContext(ctx, userQuery,
+		batch.Queue(userQuery,
 			member.UserID,
 			member.Username,
 			member.TeamName,
@@ -53,85 +50,169 @@ func (r *TeamRepository) Create(ctx context.Context, team *domain.Team) error {
 			member.CreatedAt,
 			member.UpdatedAt,
 		)
-		if err != nil {
+	}
+
+	results := r.db.conn(ctx).SendBatch(ctx, batch)
+	defer results.Close()
+
+	if _, err := results.Exec(); err != nil {
+		return fmt.Errorf("failed to create team: %w", err)
+	}
+
+	for _, member := range team.Members {
+		if _, err := results.Exec(); err != nil {
 			return fmt.Errorf("failed to create/update user %s: %w", member.UserID, err)
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	if err := results.Close(); err != nil {
+		return fmt.Errorf("failed to create team: %w", err)
 	}
 
 	return nil
 }
 
+// GetByName loads the team and its members with one query. A team without
+// members comes back as a single row with NULL user columns.
 func (r *TeamRepository) GetByName(ctx context.Context, teamName string) (*domain.Team, error) {
-	teamQuery := `
-		SELECT team_name, created_at
-		FROM teams
-		WHERE team_name = $1
+	query := `
+		SELECT t.team_name, t.created_at, u.user_id, u.username, u.is_active, u.created_at, u.updated_at
+		FROM teams t
+		LEFT JOIN users u ON u.team_name = t.team_name AND u.deleted_at IS NULL
+		WHERE t.team_name = $1 AND t.deleted_at IS NULL
+		ORDER BY u.created_at
 	`
 
-	team := &domain.Team{}
-	err := r.db.QueryRowContext(ctx, teamQuery, teamName).Scan(
-		&team.TeamName,
-		&team.CreatedAt,
-	)
-
-	if err == sql.ErrNoRows {
-		return nil, errors.ErrTeamNotFound(teamName)
-	}
-
+	rows, err := r.db.conn(ctx).Query(ctx, query, teamName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get team: %w", err)
 	}
-
-	userQuery := `
-		SELECT user_id, username, team_name, is_active, created_at, updated_at
-		FROM users
-		WHERE team_name = $1
-		ORDER BY created_at
-	`
-
-	rows, err := r.db.QueryContext(ctx, userQuery, teamName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get team members: %w", err)
-	}
 	defer rows.Close()
 
-	members := make([]*domain.User, 0)
+	var team *domain.Team
 	for rows.Next() {
-		user := &domain.User{}
-		err := rows.Scan(
-			&user.UserID,
-			&user.Username,
-			&user.TeamName,
-			&user.IsActive,
-			&user.CreatedAt,
-			&user.UpdatedAt,
+		var (
+			name          string
+			createdAt     time.Time
+			userID        *string
+			username      *string
+			isActive      *bool
+			userCreatedAt *time.Time
+			userUpdatedAt *time.Time
 		)
+		err := rows.Scan(&name, &createdAt, &userID, &username, &isActive, &userCreatedAt, &userUpdatedAt)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan user: %w", err)
+			return nil, fmt.Errorf("failed to scan team member: %w", err)
 		}
-		members = append(members, user)
+
+		if team == nil {
+			team = &domain.Team{TeamName: name, CreatedAt: createdAt, Members: make([]*domain.User, 0)}
+		}
+
+		if userID == nil {
+			continue
+		}
+
+		team.Members = append(team.Members, &domain.User{
+			UserID:    *userID,
+			Username:  *username,
+			TeamName:  name,
+			IsActive:  *isActive,
+			CreatedAt: *userCreatedAt,
+			UpdatedAt: *userUpdatedAt,
+		})
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating members: %w", err)
 	}
 
-	team.Members = members
+	if team == nil {
+		return nil, errors.ErrTeamNotFound(teamName)
+	}
+
 	return team, nil
 }
 
+// Exists reports whether the team name is taken, including by a deleted team.
 func (r *TeamRepository) Exists(ctx context.Context, teamName string) (bool, error) {
 	query := `SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1)`
 
 	var exists bool
-	err := r.db.QueryRowContext(ctx, query, teamName).Scan(&exists)
+	err := r.db.conn(ctx).QueryRow(ctx, query, teamName).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("failed to check team existence: %w", err)
 	}
 
 	return exists, nil
 }
+
+// Delete soft-deletes the team together with its members. Members get the
+// team's deleted_at, which is how Restore tells them apart from users deleted
+// on their own.
+func (r *TeamRepository) Delete(ctx context.Context, teamName string) error {
+	query := `
+		WITH deleted AS (
+			UPDATE teams
+			SET deleted_at = CURRENT_TIMESTAMP
+			WHERE team_name = $1 AND deleted_at IS NULL
+			RETURNING team_name, deleted_at
+		),
+		members AS (
+			UPDATE users u
+			SET deleted_at = d.deleted_at
+			FROM deleted d
+			WHERE u.team_name = d.team_name AND u.deleted_at IS NULL
+		)
+		SELECT COUNT(*) FROM deleted
+	`
+
+	var count int
+	if err := r.db.conn(ctx).QueryRow(ctx, query, teamName).Scan(&count); err != nil {
+		return fmt.Errorf("failed to delete team: %w", err)
+	}
+
+	if count == 0 {
+		return errors.ErrTeamNotFound(teamName)
+	}
+
+	return nil
+}
+
+// Restore undoes Delete, bringing back the members that were deleted with the
+// team.
+func (r *TeamRepository) Restore(ctx context.Context, teamName string) error {
+	query := `
+		WITH team AS (
+			SELECT team_name, deleted_at
+			FROM teams
+			WHERE team_name = $1
+			FOR UPDATE
+		),
+		restored AS (
+			UPDATE teams t
+			SET deleted_at = NULL
+			FROM team
+			WHERE t.team_name = team.team_name
+			RETURNING t.team_name
+		),
+		members AS (
+			UPDATE users u
+			SET deleted_at = NULL
+			FROM team
+			WHERE u.team_name = team.team_name AND u.deleted_at = team.deleted_at
+		)
+		SELECT COUNT(*) FROM restored
+	`
+
+	var count int
+	if err := r.db.conn(ctx).QueryRow(ctx, query, teamName).Scan(&count); err != nil {
+		return fmt.Errorf("failed to restore team: %w", err)
+	}
+
+	if count == 0 {
+		return errors.ErrTeamNotFound(teamName)
+	}
+
+	return nil
+}
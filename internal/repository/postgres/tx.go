@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type executor interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+type txKey struct{}
+
+// TxManager runs a function in a transaction that every repository call made
+// with the function's context takes part in.
+type TxManager struct {
+	db *DB
+}
+
+func NewTxManager(db *DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// WithinTx commits if fn returns nil and rolls back otherwise. Calls nested in
+// an existing transaction join it instead of starting a new one.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return m.within(ctx, pgx.TxOptions{}, fn)
+}
+
+// WithinReadOnlyTx runs fn in a read-only REPEATABLE READ transaction, so
+// every query in fn sees the same snapshot of the database.
+func (m *TxManager) WithinReadOnlyTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return m.within(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, fn)
+}
+
+func (m *TxManager) within(ctx context.Context, opts pgx.TxOptions, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.db.BeginTx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// conn returns the transaction bound to ctx, or the connection pool when
+// there is none.
+func (db *DB) conn(ctx context.Context) executor {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return db.Pool
+}
+
+// txScope is a transaction opened by a repository method. When the context
+// already carries a transaction the scope reuses it, and Commit and Rollback
+// are left to whoever started it.
+type txScope struct {
+	pgx.Tx
+	owned bool
+}
+
+func (t *txScope) Commit(ctx context.Context) error {
+	if !t.owned {
+		return nil
+	}
+	return t.Tx.Commit(ctx)
+}
+
+func (t *txScope) Rollback(ctx context.Context) error {
+	if !t.owned {
+		return nil
+	}
+	return t.Tx.Rollback(ctx)
+}
+
+func (db *DB) beginTx(ctx context.Context) (*txScope, error) {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return &txScope{Tx: tx}, nil
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &txScope{Tx: tx, owned: true}, nil
+}
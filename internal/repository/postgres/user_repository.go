@@ -2,9 +2,10 @@ package postgres
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 
+	"github.com/jackc/pgx/v5"
+
 	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
 	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
 )
@@ -23,7 +24,7 @@ func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 		VALUES ($1, $2, $3, $4, $5, $6)
 	`
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := r.db.conn(ctx).Exec(ctx, query,
 		user.UserID,
 		user.Username,
 		user.TeamName,
@@ -43,10 +44,10 @@ func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
 	query := `
 		UPDATE users
 		SET username = $2, team_name = $3, is_active = $4, updated_at = $5
-		WHERE user_id = $1
+		WHERE user_id = $1 AND deleted_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := r.db.conn(ctx).Exec(ctx, query,
 		user.UserID,
 		user.Username,
 		user.TeamName,
@@ -58,12 +59,7 @@ func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
 
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
-
-	if rows == 0 {
+	if result.RowsAffected() == 0 {
 		return errors.ErrUserNotFound(user.UserID)
 	}
 
@@ -74,11 +70,11 @@ func (r *UserRepository) GetByID(ctx context.Context, userID string) (*domain.Us
 	query := `
 		SELECT user_id, username, team_name, is_active, created_at, updated_at
 		FROM users
-		WHERE user_id = $1
+		WHERE user_id = $1 AND deleted_at IS NULL
 	`
 
 	user := &domain.User{}
-	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+	err := r.db.conn(ctx).QueryRow(ctx, query, userID).Scan(
 		&user.UserID,
 		&user.Username,
 		&user.TeamName,
@@ -87,7 +83,7 @@ func (r *UserRepository) GetByID(ctx context.Context, userID string) (*domain.Us
 		&user.UpdatedAt,
 	)
 
-	if err == sql.ErrNoRows {
+	if err == pgx.ErrNoRows {
 		return nil, errors.ErrUserNotFound(userID)
 	}
 
@@ -102,11 +98,11 @@ func (r *UserRepository) GetByTeam(ctx context.Context, teamName string) ([]*dom
 	query := `
 		SELECT user_id, username, team_name, is_active, created_at, updated_at
 		FROM users
-		WHERE team_name = $1
+		WHERE team_name = $1 AND deleted_at IS NULL
 		ORDER BY created_at
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, teamName)
+	rows, err := r.db.conn(ctx).Query(ctx, query, teamName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get users by team: %w", err)
 	}
@@ -140,11 +136,11 @@ func (r *UserRepository) GetActiveByTeamExcluding(ctx context.Context, teamName,
 	query := `
 		SELECT user_id, username, team_name, is_active, created_at, updated_at
 		FROM users
-		WHERE team_name = $1 AND is_active = true AND user_id != $2
+		WHERE team_name = $1 AND is_active = true AND user_id != $2 AND deleted_at IS NULL
 		ORDER BY created_at
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, teamName, excludeUserID)
+	rows, err := r.db.conn(ctx).Query(ctx, query, teamName, excludeUserID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get active users: %w", err)
 	}
@@ -178,31 +174,27 @@ func (r *UserRepository) SetActive(ctx context.Context, userID string, isActive
 	query := `
 		UPDATE users
 		SET is_active = $2, updated_at = CURRENT_TIMESTAMP
-		WHERE user_id = $1
+		WHERE user_id = $1 AND deleted_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(ctx, query, userID, isActive)
+	result, err := r.db.conn(ctx).Exec(ctx, query, userID, isActive)
 	if err != nil {
 		return fmt.Errorf("failed to set user active status: %w", err)
 	}
 
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
-
-	if rows == 0 {
+	if result.RowsAffected() == 0 {
 		return errors.ErrUserNotFound(userID)
 	}
 
 	return nil
 }
 
+// Exists reports whether the user ID is taken, including by a deleted user.
 func (r *UserRepository) Exists(ctx context.Context, userID string) (bool, error) {
 	query := `SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)`
 
 	var exists bool
-	err := r.db.QueryRowContext(ctx, query, userID).Scan(&exists)
+	err := r.db.conn(ctx).QueryRow(ctx, query, userID).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("failed to check user existence: %w", err)
 	}
@@ -218,10 +210,10 @@ func (r *UserRepository) BulkDeactivate(ctx context.Context, userIDs []string) e
 	query := `
 		UPDATE users
 		SET is_active = false, updated_at = CURRENT_TIMESTAMP
-		WHERE user_id = ANY($1)
+		WHERE user_id = ANY($1) AND deleted_at IS NULL
 	`
 
-	_, err := r.db.ExecContext(ctx, query, userIDs)
+	_, err := r.db.conn(ctx).Exec(ctx, query, userIDs)
 	if err != nil {
 		return fmt.Errorf("failed to bulk deactivate users: %w", err)
 	}
@@ -229,6 +221,44 @@ func (r *UserRepository) BulkDeactivate(ctx context.Context, userIDs []string) e
 	return nil
 }
 
+// Upsert creates the users or updates the username, team and active flag of
+// existing ones, bringing deleted users back. CreatedAt of existing users is
+// kept.
+func (r *UserRepository) Upsert(ctx context.Context, users []*domain.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO users (user_id, username, team_name, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id) DO UPDATE
+		SET username = EXCLUDED.username,
+		    team_name = EXCLUDED.team_name,
+		    is_active = EXCLUDED.is_active,
+		    updated_at = EXCLUDED.updated_at,
+		    deleted_at = NULL
+	`
+
+	batch := &pgx.Batch{}
+	for _, user := range users {
+		batch.Queue(query,
+			user.UserID,
+			user.Username,
+			user.TeamName,
+			user.IsActive,
+			user.CreatedAt,
+			user.UpdatedAt,
+		)
+	}
+
+	if err := r.db.conn(ctx).SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to upsert users: %w", err)
+	}
+
+	return nil
+}
+
 func (r *UserRepository) GetUsersByIDs(ctx context.Context, userIDs []string) ([]*domain.User, error) {
 	if len(userIDs) == 0 {
 		return []*domain.User{}, nil
@@ -237,10 +267,10 @@ func (r *UserRepository) GetUsersByIDs(ctx context.Context, userIDs []string) ([
 	query := `
 		SELECT user_id, username, team_name, is_active, created_at, updated_at
 		FROM users
-		WHERE user_id = ANY($1)
+		WHERE user_id = ANY($1) AND deleted_at IS NULL
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, userIDs)
+	rows, err := r.db.conn(ctx).Query(ctx, query, userIDs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get users by IDs: %w", err)
 	}
@@ -269,3 +299,167 @@ func (r *UserRepository) GetUsersByIDs(ctx context.Context, userIDs []string) ([
 
 	return users, nil
 }
+
+func (r *UserRepository) List(ctx context.Context, filter domain.UserFilter) ([]*domain.User, error) {
+	query := `
+		SELECT user_id, username, team_name, is_active, created_at, updated_at
+		FROM users
+		WHERE deleted_at IS NULL
+		  AND ($1 = '' OR team_name = $1)
+		  AND ($2::boolean IS NULL OR is_active = $2)
+		ORDER BY team_name, created_at
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, filter.TeamName, filter.IsActive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]*domain.User, 0)
+	for rows.Next() {
+		user := &domain.User{}
+		err := rows.Scan(
+			&user.UserID,
+			&user.Username,
+			&user.TeamName,
+			&user.IsActive,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) GetOpenReviewCounts(ctx context.Context, userIDs []string) (map[string]int, error) {
+	counts := make(map[string]int, len(userIDs))
+	if len(userIDs) == 0 {
+		return counts, nil
+	}
+
+	query := `
+		SELECT prr.reviewer_id, COUNT(*)
+		FROM pr_reviewers prr
+		INNER JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+		WHERE pr.status = 'OPEN' AND pr.deleted_at IS NULL AND prr.reviewer_id = ANY($1)
+		GROUP BY prr.reviewer_id
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open review counts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			userID string
+			count  int
+		)
+		if err := rows.Scan(&userID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan open review count: %w", err)
+		}
+		counts[userID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating open review counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+func (r *UserRepository) GetUsernameOwners(ctx context.Context, usernames []string) (map[string]string, error) {
+	owners := make(map[string]string, len(usernames))
+	if len(usernames) == 0 {
+		return owners, nil
+	}
+
+	query := `
+		SELECT username, user_id
+		FROM users
+		WHERE username = ANY($1)
+	`
+
+	rows, err := r.db.conn(ctx).Query(ctx, query, usernames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get username owners: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var username, userID string
+		if err := rows.Scan(&username, &userID); err != nil {
+			return nil, fmt.Errorf("failed to scan username owner: %w", err)
+		}
+		owners[username] = userID
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating username owners: %w", err)
+	}
+
+	return owners, nil
+}
+
+// Delete soft-deletes the user. Their pull requests and review history stay in
+// place.
+func (r *UserRepository) Delete(ctx context.Context, userID string) error {
+	query := `
+		UPDATE users
+		SET deleted_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.conn(ctx).Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.ErrUserNotFound(userID)
+	}
+
+	return nil
+}
+
+// Restore undoes Delete. A user of a deleted team can only come back with the
+// team, so it fails with the team's NOT_FOUND error in that case.
+func (r *UserRepository) Restore(ctx context.Context, userID string) error {
+	query := `
+		UPDATE users u
+		SET deleted_at = NULL
+		FROM teams t
+		WHERE u.user_id = $1 AND t.team_name = u.team_name AND t.deleted_at IS NULL
+	`
+
+	result, err := r.db.conn(ctx).Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to restore user: %w", err)
+	}
+
+	if result.RowsAffected() > 0 {
+		return nil
+	}
+
+	var teamName string
+	err = r.db.conn(ctx).QueryRow(ctx, `SELECT team_name FROM users WHERE user_id = $1`, userID).Scan(&teamName)
+	if err == pgx.ErrNoRows {
+		return errors.ErrUserNotFound(userID)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to restore user: %w", err)
+	}
+
+	return errors.ErrTeamNotFound(teamName)
+}
@@ -0,0 +1,923 @@
+// Package repotest is a conformance suite for repository backends. Every
+// backend must pass Run so that services behave the same whichever storage
+// they are wired to.
+package repotest
+
+import (
+	"context"
+	stderrors "errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/service"
+)
+
+type Repositories struct {
+	Users     service.UserRepository
+	Teams     service.TeamRepository
+	PRs       service.PRRepository
+	Stats     service.StatsRepository
+	Snapshots service.SnapshotRepository
+	Tx        service.TxManager
+}
+
+// Run runs the suite. newRepositories is called once per test and must
+// return repositories over empty storage.
+func Run(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, r Repositories)
+	}{
+		{"TeamCreateAndGet", testTeamCreateAndGet},
+		{"TeamCreateDuplicate", testTeamCreateDuplicate},
+		{"TeamCreateMovesExistingUsers", testTeamCreateMovesExistingUsers},
+		{"UsernamesAreUnique", testUsernamesAreUnique},
+		{"UserNotFound", testUserNotFound},
+		{"UserQueries", testUserQueries},
+		{"UserUpsert", testUserUpsert},
+		{"PRCreateAndGet", testPRCreateAndGet},
+		{"PRCreateRequiresUsers", testPRCreateRequiresUsers},
+		{"PRUpdateChecksVersion", testPRUpdateChecksVersion},
+		{"PRReplaceReviewer", testPRReplaceReviewer},
+		{"PRReviewerQueries", testPRReviewerQueries},
+		{"Statistics", testStatistics},
+		{"TxRollback", testTxRollback},
+		{"UserSoftDelete", testUserSoftDelete},
+		{"TeamSoftDelete", testTeamSoftDelete},
+		{"PRSoftDelete", testPRSoftDelete},
+		{"ArchiveMerged", testArchiveMerged},
+		{"SnapshotRoundTrip", testSnapshotRoundTrip},
+		{"SnapshotFindExisting", testSnapshotFindExisting},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepositories(t))
+		})
+	}
+}
+
+var errAbort = stderrors.New("abort")
+
+func createTeam(t *testing.T, r Repositories, teamName string, members ...*domain.User) {
+	t.Helper()
+	for _, member := range members {
+		member.TeamName = teamName
+	}
+	if err := r.Teams.Create(context.Background(), domain.NewTeam(teamName, members)); err != nil {
+		t.Fatalf("Failed to create team %s: %v", teamName, err)
+	}
+}
+
+func createPR(t *testing.T, r Repositories, prID, authorID string, reviewerIDs ...string) *domain.PullRequest {
+	t.Helper()
+	pr := domain.NewPullRequest(prID, prID, authorID)
+	pr.AssignReviewers(reviewerIDs)
+	if err := r.PRs.Create(context.Background(), pr); err != nil {
+		t.Fatalf("Failed to create PR %s: %v", prID, err)
+	}
+	return pr
+}
+
+func user(id string, active bool) *domain.User {
+	return domain.NewUser(id, "name-"+id, "", active)
+}
+
+func expectCode(t *testing.T, err error, code errors.ErrorCode) {
+	t.Helper()
+	var appErr *errors.AppError
+	if !stderrors.As(err, &appErr) || appErr.Code != code {
+		t.Fatalf("Expected %s error, got %v", code, err)
+	}
+}
+
+func userIDs(users []*domain.User) []string {
+	ids := make([]string, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.UserID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func sorted(ids []string) []string {
+	ids = append([]string(nil), ids...)
+	sort.Strings(ids)
+	return ids
+}
+
+func expectIDs(t *testing.T, what string, got []string, want ...string) {
+	t.Helper()
+	if len(want) == 0 {
+		want = []string{}
+	}
+	got = sorted(got)
+	if len(got) != len(want) {
+		t.Fatalf("Expected %s %v, got %v", what, want, got)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("Expected %s %v, got %v", what, want, got)
+		}
+	}
+}
+
+func testTeamCreateAndGet(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	createTeam(t, r, "backend", user("u1", true), user("u2", false))
+
+	team, err := r.Teams.GetByName(ctx, "backend")
+	if err != nil {
+		t.Fatalf("Failed to get team: %v", err)
+	}
+	if team.TeamName != "backend" {
+		t.Fatalf("Expected team backend, got %s", team.TeamName)
+	}
+	expectIDs(t, "members", userIDs(team.Members), "u1", "u2")
+
+	exists, err := r.Teams.Exists(ctx, "backend")
+	if err != nil || !exists {
+		t.Fatalf("Expected team to exist, got %v, %v", exists, err)
+	}
+
+	exists, err = r.Teams.Exists(ctx, "missing")
+	if err != nil || exists {
+		t.Fatalf("Expected team not to exist, got %v, %v", exists, err)
+	}
+
+	_, err = r.Teams.GetByName(ctx, "missing")
+	expectCode(t, err, errors.ErrCodeNotFound)
+}
+
+func testTeamCreateDuplicate(t *testing.T, r Repositories) {
+	createTeam(t, r, "backend", user("u1", true))
+
+	err := r.Teams.Create(context.Background(), domain.NewTeam("backend", nil))
+	if err == nil {
+		t.Fatal("Expected an error for a duplicate team")
+	}
+}
+
+func testTeamCreateMovesExistingUsers(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	createTeam(t, r, "backend", user("u1", true), user("u2", true))
+	createTeam(t, r, "frontend", user("u1", false))
+
+	u1, err := r.Users.GetByID(ctx, "u1")
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	if u1.TeamName != "frontend" || u1.IsActive {
+		t.Fatalf("Expected u1 to be an inactive member of frontend, got %+v", u1)
+	}
+
+	backend, err := r.Teams.GetByName(ctx, "backend")
+	if err != nil {
+		t.Fatalf("Failed to get team: %v", err)
+	}
+	expectIDs(t, "backend members", userIDs(backend.Members), "u2")
+}
+
+func testUsernamesAreUnique(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	createTeam(t, r, "backend", user("u1", true))
+
+	duplicate := domain.NewUser("u2", "name-u1", "frontend", true)
+	err := r.Teams.Create(ctx, domain.NewTeam("frontend", []*domain.User{duplicate}))
+	if err == nil {
+		t.Fatal("Expected an error for a duplicate username")
+	}
+
+	exists, err := r.Teams.Exists(ctx, "frontend")
+	if err != nil || exists {
+		t.Fatalf("Expected the failed team not to be created, got %v, %v", exists, err)
+	}
+
+	_, err = r.Users.GetByID(ctx, "u2")
+	expectCode(t, err, errors.ErrCodeNotFound)
+}
+
+func testUserNotFound(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	_, err := r.Users.GetByID(ctx, "missing")
+	expectCode(t, err, errors.ErrCodeNotFound)
+
+	err = r.Users.SetActive(ctx, "missing", false)
+	expectCode(t, err, errors.ErrCodeNotFound)
+}
+
+func testUserQueries(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	createTeam(t, r, "backend", user("u1", true), user("u2", true), user("u3", false))
+	createTeam(t, r, "frontend", user("u4", true))
+
+	users, err := r.Users.GetByTeam(ctx, "backend")
+	if err != nil {
+		t.Fatalf("Failed to get users by team: %v", err)
+	}
+	expectIDs(t, "team users", userIDs(users), "u1", "u2", "u3")
+
+	users, err = r.Users.GetActiveByTeamExcluding(ctx, "backend", "u1")
+	if err != nil {
+		t.Fatalf("Failed to get active users: %v", err)
+	}
+	expectIDs(t, "active users", userIDs(users), "u2")
+
+	users, err = r.Users.GetUsersByIDs(ctx, []string{"u1", "u4", "missing"})
+	if err != nil {
+		t.Fatalf("Failed to get users by IDs: %v", err)
+	}
+	expectIDs(t, "users by IDs", userIDs(users), "u1", "u4")
+
+	inactive := false
+	users, err = r.Users.List(ctx, domain.UserFilter{IsActive: &inactive})
+	if err != nil {
+		t.Fatalf("Failed to list users: %v", err)
+	}
+	expectIDs(t, "inactive users", userIDs(users), "u3")
+
+	if err := r.Users.SetActive(ctx, "u3", true); err != nil {
+		t.Fatalf("Failed to set active: %v", err)
+	}
+	u3, err := r.Users.GetByID(ctx, "u3")
+	if err != nil || !u3.IsActive {
+		t.Fatalf("Expected u3 to be active, got %+v, %v", u3, err)
+	}
+
+	if err := r.Users.BulkDeactivate(ctx, []string{"u1", "u2", "missing"}); err != nil {
+		t.Fatalf("Failed to bulk deactivate: %v", err)
+	}
+
+	active := true
+	users, err = r.Users.List(ctx, domain.UserFilter{IsActive: &active})
+	if err != nil {
+		t.Fatalf("Failed to list users: %v", err)
+	}
+	expectIDs(t, "active users", userIDs(users), "u3", "u4")
+
+	users, err = r.Users.List(ctx, domain.UserFilter{TeamName: "frontend"})
+	if err != nil {
+		t.Fatalf("Failed to list users: %v", err)
+	}
+	expectIDs(t, "frontend users", userIDs(users), "u4")
+}
+
+func testUserUpsert(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	createTeam(t, r, "backend", user("u1", true), user("u2", true))
+	createTeam(t, r, "frontend")
+	if err := r.Users.Delete(ctx, "u2"); err != nil {
+		t.Fatalf("Failed to delete user: %v", err)
+	}
+
+	u1, err := r.Users.GetByID(ctx, "u1")
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+
+	moved := domain.NewUser("u1", "renamed-u1", "frontend", false)
+	restored := domain.NewUser("u2", "name-u2", "backend", true)
+	created := domain.NewUser("u3", "name-u3", "frontend", true)
+	if err := r.Users.Upsert(ctx, []*domain.User{moved, restored, created}); err != nil {
+		t.Fatalf("Failed to upsert users: %v", err)
+	}
+
+	got, err := r.Users.GetByID(ctx, "u1")
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	if got.TeamName != "frontend" || got.Username != "renamed-u1" || got.IsActive {
+		t.Fatalf("Expected u1 to be an inactive member of frontend named renamed-u1, got %+v", got)
+	}
+	if !got.CreatedAt.Equal(u1.CreatedAt) {
+		t.Fatalf("Expected CreatedAt to be kept, got %v, want %v", got.CreatedAt, u1.CreatedAt)
+	}
+
+	users, err := r.Users.GetByTeam(ctx, "frontend")
+	if err != nil {
+		t.Fatalf("Failed to get users by team: %v", err)
+	}
+	expectIDs(t, "frontend users", userIDs(users), "u1", "u3")
+
+	if _, err := r.Users.GetByID(ctx, "u2"); err != nil {
+		t.Fatalf("Expected u2 to be restored, got %v", err)
+	}
+}
+
+func testPRCreateAndGet(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	createTeam(t, r, "backend", user("u1", true), user("u2", true), user("u3", true))
+	createPR(t, r, "pr-1", "u1", "u2", "u3")
+
+	pr, err := r.PRs.GetByID(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("Failed to get PR: %v", err)
+	}
+	if pr.AuthorID != "u1" || pr.Status != domain.PRStatusOpen || pr.Version != 1 || pr.MergedAt != nil {
+		t.Fatalf("Unexpected PR: %+v", pr)
+	}
+	expectIDs(t, "reviewers", pr.AssignedReviewers, "u2", "u3")
+	if len(pr.ReviewerAssignments) != 2 {
+		t.Fatalf("Expected 2 reviewer assignments, got %d", len(pr.ReviewerAssignments))
+	}
+
+	exists, err := r.PRs.Exists(ctx, "pr-1")
+	if err != nil || !exists {
+		t.Fatalf("Expected PR to exist, got %v, %v", exists, err)
+	}
+
+	_, err = r.PRs.GetByID(ctx, "missing")
+	expectCode(t, err, errors.ErrCodeNotFound)
+}
+
+func testPRCreateRequiresUsers(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	createTeam(t, r, "backend", user("u1", true))
+
+	if err := r.PRs.Create(ctx, domain.NewPullRequest("pr-1", "pr-1", "missing")); err == nil {
+		t.Fatal("Expected an error for an unknown author")
+	}
+
+	pr := domain.NewPullRequest("pr-2", "pr-2", "u1")
+	pr.AssignReviewers([]string{"missing"})
+	if err := r.PRs.Create(ctx, pr); err == nil {
+		t.Fatal("Expected an error for an unknown reviewer")
+	}
+
+	for _, id := range []string{"pr-1", "pr-2"} {
+		exists, err := r.PRs.Exists(ctx, id)
+		if err != nil || exists {
+			t.Fatalf("Expected %s not to be created, got %v, %v", id, exists, err)
+		}
+	}
+}
+
+func testPRUpdateChecksVersion(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	createTeam(t, r, "backend", user("u1", true), user("u2", true))
+	createPR(t, r, "pr-1", "u1", "u2")
+
+	pr, err := r.PRs.GetByID(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("Failed to get PR: %v", err)
+	}
+	stale := *pr
+
+	pr.Merge()
+	if err := r.PRs.Update(ctx, pr); err != nil {
+		t.Fatalf("Failed to update PR: %v", err)
+	}
+	if pr.Version != 2 {
+		t.Fatalf("Expected version 2 after update, got %d", pr.Version)
+	}
+
+	stored, err := r.PRs.GetByID(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("Failed to get PR: %v", err)
+	}
+	if !stored.IsMerged() || stored.MergedAt == nil || stored.Version != 2 {
+		t.Fatalf("Expected merged PR at version 2, got %+v", stored)
+	}
+
+	stale.PullRequestName = "renamed"
+	expectCode(t, r.PRs.Update(ctx, &stale), errors.ErrCodeConflict)
+
+	missing := domain.NewPullRequest("missing", "missing", "u1")
+	expectCode(t, r.PRs.Update(ctx, missing), errors.ErrCodeNotFound)
+}
+
+func testPRReplaceReviewer(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	createTeam(t, r, "backend", user("u1", true), user("u2", true), user("u3", true), user("u4", true))
+	createPR(t, r, "pr-1", "u1", "u2", "u3")
+
+	if err := r.PRs.ReplaceReviewer(ctx, "pr-1", 1, "u2", "u4"); err != nil {
+		t.Fatalf("Failed to replace reviewer: %v", err)
+	}
+
+	pr, err := r.PRs.GetByID(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("Failed to get PR: %v", err)
+	}
+	expectIDs(t, "reviewers", pr.AssignedReviewers, "u3", "u4")
+	if pr.Version != 2 {
+		t.Fatalf("Expected version 2, got %d", pr.Version)
+	}
+
+	expectCode(t, r.PRs.ReplaceReviewer(ctx, "pr-1", 1, "u3", "u2"), errors.ErrCodeConflict)
+	expectCode(t, r.PRs.ReplaceReviewer(ctx, "pr-1", 2, "u2", "u1"), errors.ErrCodeNotAssigned)
+	expectCode(t, r.PRs.ReplaceReviewer(ctx, "missing", 1, "u2", "u1"), errors.ErrCodeNotFound)
+
+	if err := r.PRs.ReplaceReviewer(ctx, "pr-1", 2, "u3", "missing"); err == nil {
+		t.Fatal("Expected an error for an unknown reviewer")
+	}
+
+	pr, err = r.PRs.GetByID(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("Failed to get PR: %v", err)
+	}
+	expectIDs(t, "reviewers", pr.AssignedReviewers, "u3", "u4")
+	if pr.Version != 2 {
+		t.Fatalf("Expected failed replacements to keep version 2, got %d", pr.Version)
+	}
+}
+
+func testPRReviewerQueries(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	createTeam(t, r, "backend", user("u1", true), user("u2", true), user("u3", true))
+	createTeam(t, r, "frontend", user("u4", true))
+	createPR(t, r, "pr-1", "u1", "u2", "u4")
+	merged := createPR(t, r, "pr-2", "u1", "u2")
+	merged.Merge()
+	if err := r.PRs.Update(ctx, merged); err != nil {
+		t.Fatalf("Failed to merge PR: %v", err)
+	}
+
+	prs, err := r.PRs.GetByReviewer(ctx, "u2")
+	if err != nil {
+		t.Fatalf("Failed to get PRs by reviewer: %v", err)
+	}
+	prIDs := make([]string, 0, len(prs))
+	for _, pr := range prs {
+		prIDs = append(prIDs, pr.PullRequestID)
+	}
+	expectIDs(t, "PRs by reviewer", prIDs, "pr-1", "pr-2")
+
+	infos, err := r.PRs.GetOpenPRsWithReviewers(ctx, []string{"u2", "u4"})
+	if err != nil {
+		t.Fatalf("Failed to get open PRs: %v", err)
+	}
+	want := map[domain.PRReviewerInfo]bool{
+		{PullRequestID: "pr-1", ReviewerID: "u2", ReviewerTeam: "backend"}:  true,
+		{PullRequestID: "pr-1", ReviewerID: "u4", ReviewerTeam: "frontend"}: true,
+	}
+	if len(infos) != len(want) {
+		t.Fatalf("Expected %d open PR reviewers, got %v", len(want), infos)
+	}
+	for _, info := range infos {
+		if !want[info] {
+			t.Fatalf("Unexpected open PR reviewer %+v", info)
+		}
+	}
+
+	counts, err := r.Users.GetOpenReviewCounts(ctx, []string{"u2", "u3", "u4"})
+	if err != nil {
+		t.Fatalf("Failed to get open review counts: %v", err)
+	}
+	if counts["u2"] != 1 || counts["u3"] != 0 || counts["u4"] != 1 {
+		t.Fatalf("Unexpected open review counts %v", counts)
+	}
+}
+
+func testStatistics(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	createTeam(t, r, "backend", user("u1", true), user("u2", true), user("u3", false))
+	createTeam(t, r, "frontend", user("u4", true))
+	createPR(t, r, "pr-1", "u1", "u2", "u4")
+	merged := createPR(t, r, "pr-2", "u4", "u2")
+	merged.Merge()
+	if err := r.PRs.Update(ctx, merged); err != nil {
+		t.Fatalf("Failed to merge PR: %v", err)
+	}
+
+	stats, err := r.Stats.GetStatistics(ctx, domain.StatsFilter{})
+	if err != nil {
+		t.Fatalf("Failed to get statistics: %v", err)
+	}
+	if stats.PullRequests != (domain.PullRequestStats{Total: 2, Open: 1, Merged: 1}) {
+		t.Fatalf("Unexpected PR stats %+v", stats.PullRequests)
+	}
+	if stats.Users != (domain.UserStats{Total: 4, Active: 3, Inactive: 1}) {
+		t.Fatalf("Unexpected user stats %+v", stats.Users)
+	}
+	if stats.Teams.Total != 2 || len(stats.ByTeam) != 2 {
+		t.Fatalf("Expected 2 teams, got %+v", stats.ByTeam)
+	}
+	backend := stats.ByTeam[0]
+	if backend.TeamName != "backend" || backend.PullRequests.Total != 1 || backend.ReviewAssignments != 2 {
+		t.Fatalf("Unexpected backend breakdown %+v", backend)
+	}
+	if len(stats.TopReviewers) == 0 || stats.TopReviewers[0].UserID != "u2" || stats.TopReviewers[0].ReviewCount != 2 {
+		t.Fatalf("Expected u2 to be the top reviewer, got %+v", stats.TopReviewers)
+	}
+
+	stats, err = r.Stats.GetStatistics(ctx, domain.StatsFilter{TeamName: "frontend"})
+	if err != nil {
+		t.Fatalf("Failed to get team statistics: %v", err)
+	}
+	if stats.Teams.Total != 1 || stats.PullRequests.Merged != 1 || stats.Users.Total != 1 {
+		t.Fatalf("Unexpected frontend statistics %+v", stats)
+	}
+
+	_, err = r.Stats.GetStatistics(ctx, domain.StatsFilter{TeamName: "missing"})
+	expectCode(t, err, errors.ErrCodeNotFound)
+
+	timings, err := r.Stats.GetMergedPRTimings(ctx, domain.StatsFilter{})
+	if err != nil {
+		t.Fatalf("Failed to get merged PR timings: %v", err)
+	}
+	if len(timings) != 1 || timings[0].PullRequestID != "pr-2" || timings[0].TeamName != "frontend" {
+		t.Fatalf("Unexpected merged PR timings %+v", timings)
+	}
+
+	_, err = r.Stats.GetMergedPRTimings(ctx, domain.StatsFilter{TeamName: "missing"})
+	expectCode(t, err, errors.ErrCodeNotFound)
+
+	workload, err := r.Stats.GetReviewerWorkload(ctx, domain.StatsFilter{TeamName: "backend"})
+	if err != nil {
+		t.Fatalf("Failed to get reviewer workload: %v", err)
+	}
+	if len(workload) != 2 || workload[0].UserID != "u1" || workload[1].UserID != "u2" {
+		t.Fatalf("Expected workload for active backend users, got %+v", workload)
+	}
+	if workload[1].OpenReviews != 1 || workload[1].AssignedReviews != 2 {
+		t.Fatalf("Unexpected workload for u2 %+v", workload[1])
+	}
+
+	_, err = r.Stats.GetReviewerWorkload(ctx, domain.StatsFilter{TeamName: "missing"})
+	expectCode(t, err, errors.ErrCodeNotFound)
+}
+
+func testTxRollback(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	createTeam(t, r, "backend", user("u1", true), user("u2", true), user("u3", true))
+	createPR(t, r, "pr-1", "u1", "u2")
+
+	err := r.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := r.Users.SetActive(ctx, "u2", false); err != nil {
+			return err
+		}
+		return r.Tx.WithinTx(ctx, func(ctx context.Context) error {
+			if err := r.PRs.ReplaceReviewer(ctx, "pr-1", 1, "u2", "u3"); err != nil {
+				return err
+			}
+			return errAbort
+		})
+	})
+	if !stderrors.Is(err, errAbort) {
+		t.Fatalf("Expected the transaction error, got %v", err)
+	}
+
+	u2, err := r.Users.GetByID(ctx, "u2")
+	if err != nil || !u2.IsActive {
+		t.Fatalf("Expected deactivation to be rolled back, got %+v, %v", u2, err)
+	}
+	pr, err := r.PRs.GetByID(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("Failed to get PR: %v", err)
+	}
+	expectIDs(t, "reviewers", pr.AssignedReviewers, "u2")
+	if pr.Version != 1 {
+		t.Fatalf("Expected version 1 after rollback, got %d", pr.Version)
+	}
+
+	err = r.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		return r.Users.SetActive(ctx, "u2", false)
+	})
+	if err != nil {
+		t.Fatalf("Failed to commit transaction: %v", err)
+	}
+
+	u2, err = r.Users.GetByID(ctx, "u2")
+	if err != nil || u2.IsActive {
+		t.Fatalf("Expected deactivation to be committed, got %+v, %v", u2, err)
+	}
+}
+
+func testUserSoftDelete(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	createTeam(t, r, "backend", user("u1", true), user("u2", true))
+
+	if err := r.Users.Delete(ctx, "u2"); err != nil {
+		t.Fatalf("Failed to delete user: %v", err)
+	}
+	expectCode(t, r.Users.Delete(ctx, "u2"), errors.ErrCodeNotFound)
+	expectCode(t, r.Users.Delete(ctx, "missing"), errors.ErrCodeNotFound)
+
+	owners, err := r.Users.GetUsernameOwners(ctx, []string{"name-u1", "name-u2", "missing"})
+	if err != nil {
+		t.Fatalf("Failed to get username owners: %v", err)
+	}
+	// Deleted users keep their usernames.
+	if want := map[string]string{"name-u1": "u1", "name-u2": "u2"}; !reflect.DeepEqual(owners, want) {
+		t.Fatalf("Expected username owners %v, got %v", want, owners)
+	}
+
+	_, err = r.Users.GetByID(ctx, "u2")
+	expectCode(t, err, errors.ErrCodeNotFound)
+	expectCode(t, r.Users.SetActive(ctx, "u2", false), errors.ErrCodeNotFound)
+
+	users, err := r.Users.GetByTeam(ctx, "backend")
+	if err != nil {
+		t.Fatalf("Failed to get users by team: %v", err)
+	}
+	expectIDs(t, "team users", userIDs(users), "u1")
+
+	users, _ = r.Users.GetActiveByTeamExcluding(ctx, "backend", "")
+	expectIDs(t, "active users", userIDs(users), "u1")
+
+	users, _ = r.Users.GetUsersByIDs(ctx, []string{"u1", "u2"})
+	expectIDs(t, "users by ID", userIDs(users), "u1")
+
+	users, _ = r.Users.List(ctx, domain.UserFilter{})
+	expectIDs(t, "listed users", userIDs(users), "u1")
+
+	team, err := r.Teams.GetByName(ctx, "backend")
+	if err != nil {
+		t.Fatalf("Failed to get team: %v", err)
+	}
+	expectIDs(t, "members", userIDs(team.Members), "u1")
+
+	if err := r.Users.Restore(ctx, "u2"); err != nil {
+		t.Fatalf("Failed to restore user: %v", err)
+	}
+	if _, err := r.Users.GetByID(ctx, "u2"); err != nil {
+		t.Fatalf("Expected the restored user, got %v", err)
+	}
+	expectCode(t, r.Users.Restore(ctx, "missing"), errors.ErrCodeNotFound)
+
+	if err := r.Users.Delete(ctx, "u2"); err != nil {
+		t.Fatalf("Failed to delete user: %v", err)
+	}
+	createTeam(t, r, "frontend", user("u2", true))
+	u2, err := r.Users.GetByID(ctx, "u2")
+	if err != nil || u2.TeamName != "frontend" {
+		t.Fatalf("Expected adding a deleted user to a team to restore them, got %+v, %v", u2, err)
+	}
+}
+
+func testTeamSoftDelete(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	createTeam(t, r, "backend", user("u1", true), user("u2", false), user("u3", true))
+
+	if err := r.Users.Delete(ctx, "u3"); err != nil {
+		t.Fatalf("Failed to delete user: %v", err)
+	}
+	if err := r.Teams.Delete(ctx, "backend"); err != nil {
+		t.Fatalf("Failed to delete team: %v", err)
+	}
+	expectCode(t, r.Teams.Delete(ctx, "backend"), errors.ErrCodeNotFound)
+
+	_, err := r.Teams.GetByName(ctx, "backend")
+	expectCode(t, err, errors.ErrCodeNotFound)
+	_, err = r.Users.GetByID(ctx, "u1")
+	expectCode(t, err, errors.ErrCodeNotFound)
+
+	exists, err := r.Teams.Exists(ctx, "backend")
+	if err != nil || !exists {
+		t.Fatalf("Expected a deleted team name to stay taken, got %v, %v", exists, err)
+	}
+
+	expectCode(t, r.Users.Restore(ctx, "u1"), errors.ErrCodeNotFound)
+
+	if err := r.Teams.Restore(ctx, "backend"); err != nil {
+		t.Fatalf("Failed to restore team: %v", err)
+	}
+	team, err := r.Teams.GetByName(ctx, "backend")
+	if err != nil {
+		t.Fatalf("Failed to get restored team: %v", err)
+	}
+	expectIDs(t, "members deleted with the team", userIDs(team.Members), "u1", "u2")
+
+	expectCode(t, r.Teams.Restore(ctx, "missing"), errors.ErrCodeNotFound)
+}
+
+func testPRSoftDelete(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	createTeam(t, r, "backend", user("u1", true), user("u2", true))
+	pr := createPR(t, r, "pr-1", "u1", "u2")
+
+	if err := r.PRs.Delete(ctx, "pr-1"); err != nil {
+		t.Fatalf("Failed to delete PR: %v", err)
+	}
+	expectCode(t, r.PRs.Delete(ctx, "pr-1"), errors.ErrCodeNotFound)
+
+	_, err := r.PRs.GetByID(ctx, "pr-1")
+	expectCode(t, err, errors.ErrCodeNotFound)
+
+	pr.Merge()
+	expectCode(t, r.PRs.Update(ctx, pr), errors.ErrCodeNotFound)
+	expectCode(t, r.PRs.ReplaceReviewer(ctx, "pr-1", 1, "u2", "u1"), errors.ErrCodeNotFound)
+
+	prs, _ := r.PRs.GetByReviewer(ctx, "u2")
+	if len(prs) != 0 {
+		t.Fatalf("Expected no PRs for u2, got %d", len(prs))
+	}
+	infos, _ := r.PRs.GetOpenPRsWithReviewers(ctx, []string{"u2"})
+	if len(infos) != 0 {
+		t.Fatalf("Expected no open reviews, got %+v", infos)
+	}
+	counts, _ := r.Users.GetOpenReviewCounts(ctx, []string{"u2"})
+	if counts["u2"] != 0 {
+		t.Fatalf("Expected no open reviews for u2, got %v", counts)
+	}
+
+	stats, err := r.Stats.GetStatistics(ctx, domain.StatsFilter{})
+	if err != nil {
+		t.Fatalf("Failed to get statistics: %v", err)
+	}
+	if stats.PullRequests.Total != 0 || stats.ByTeam[0].ReviewAssignments != 0 {
+		t.Fatalf("Expected deleted PRs not to count, got %+v", stats)
+	}
+
+	exists, err := r.PRs.Exists(ctx, "pr-1")
+	if err != nil || !exists {
+		t.Fatalf("Expected a deleted PR ID to stay taken, got %v, %v", exists, err)
+	}
+
+	if err := r.PRs.Restore(ctx, "pr-1"); err != nil {
+		t.Fatalf("Failed to restore PR: %v", err)
+	}
+	restored, err := r.PRs.GetByID(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("Failed to get restored PR: %v", err)
+	}
+	expectIDs(t, "reviewers", restored.AssignedReviewers, "u2")
+	expectCode(t, r.PRs.Restore(ctx, "missing"), errors.ErrCodeNotFound)
+}
+
+func testArchiveMerged(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	createTeam(t, r, "backend", user("u1", true), user("u2", true), user("u3", true))
+	createPR(t, r, "pr-1", "u1", "u2")
+	merged := createPR(t, r, "pr-2", "u1", "u2", "u3")
+	merged.Merge()
+	if err := r.PRs.Update(ctx, merged); err != nil {
+		t.Fatalf("Failed to merge PR: %v", err)
+	}
+
+	before, err := r.Stats.GetStatistics(ctx, domain.StatsFilter{})
+	if err != nil {
+		t.Fatalf("Failed to get statistics: %v", err)
+	}
+	timingsBefore, _ := r.Stats.GetMergedPRTimings(ctx, domain.StatsFilter{})
+	workloadBefore, _ := r.Stats.GetReviewerWorkload(ctx, domain.StatsFilter{})
+
+	archived, err := r.PRs.ArchiveMerged(ctx, time.Now().Add(-time.Hour))
+	if err != nil || archived != 0 {
+		t.Fatalf("Expected nothing merged an hour ago, got %d, %v", archived, err)
+	}
+
+	archived, err = r.PRs.ArchiveMerged(ctx, time.Now().Add(time.Hour))
+	if err != nil || archived != 1 {
+		t.Fatalf("Expected one archived PR, got %d, %v", archived, err)
+	}
+
+	_, err = r.PRs.GetByID(ctx, "pr-2")
+	expectCode(t, err, errors.ErrCodeNotFound)
+	if _, err := r.PRs.GetByID(ctx, "pr-1"); err != nil {
+		t.Fatalf("Expected the open PR to stay, got %v", err)
+	}
+
+	exists, err := r.PRs.Exists(ctx, "pr-2")
+	if err != nil || !exists {
+		t.Fatalf("Expected an archived PR ID to stay taken, got %v, %v", exists, err)
+	}
+
+	after, err := r.Stats.GetStatistics(ctx, domain.StatsFilter{})
+	if err != nil {
+		t.Fatalf("Failed to get statistics: %v", err)
+	}
+	if !reflect.DeepEqual(before, after) {
+		t.Fatalf("Expected archiving to keep statistics, got %+v, want %+v", after, before)
+	}
+
+	timingsAfter, _ := r.Stats.GetMergedPRTimings(ctx, domain.StatsFilter{})
+	if len(timingsAfter) != len(timingsBefore) || len(timingsAfter) != 1 {
+		t.Fatalf("Expected the archived PR in cycle time, got %+v", timingsAfter)
+	}
+
+	workloadAfter, _ := r.Stats.GetReviewerWorkload(ctx, domain.StatsFilter{})
+	if !reflect.DeepEqual(workloadBefore, workloadAfter) {
+		t.Fatalf("Expected archiving to keep workload, got %+v, want %+v", workloadAfter, workloadBefore)
+	}
+}
+
+func testSnapshotRoundTrip(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	createTeam(t, r, "backend", user("u1", true), user("u2", true), user("u3", false))
+	createTeam(t, r, "empty")
+	createPR(t, r, "pr-1", "u1", "u2")
+	merged := createPR(t, r, "pr-2", "u2", "u1")
+	merged.Merge()
+	if err := r.PRs.Update(ctx, merged); err != nil {
+		t.Fatalf("Failed to merge PR: %v", err)
+	}
+
+	var snapshot *domain.Snapshot
+	err := r.Tx.WithinReadOnlyTx(ctx, func(ctx context.Context) error {
+		var err error
+		snapshot, err = r.Snapshots.Export(ctx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+
+	if len(snapshot.Teams) != 2 || snapshot.Teams[0].TeamName != "backend" {
+		t.Fatalf("Expected teams backend and empty, got %+v", snapshot.Teams)
+	}
+	expectIDs(t, "members", userIDs(snapshot.Teams[0].Members), "u1", "u2", "u3")
+	if len(snapshot.Teams[1].Members) != 0 {
+		t.Fatalf("Expected no members in empty, got %d", len(snapshot.Teams[1].Members))
+	}
+	if len(snapshot.PullRequests) != 2 {
+		t.Fatalf("Expected 2 PRs, got %d", len(snapshot.PullRequests))
+	}
+	exported := snapshot.PullRequests[1]
+	if exported.PullRequestID != "pr-2" || !exported.IsMerged() || exported.MergedAt == nil || exported.Version != 2 {
+		t.Fatalf("Expected the merged pr-2, got %+v", exported)
+	}
+	expectIDs(t, "reviewers", exported.AssignedReviewers, "u1")
+
+	if err := r.PRs.Delete(ctx, "pr-1"); err != nil {
+		t.Fatalf("Failed to delete PR: %v", err)
+	}
+	if err := r.Users.SetActive(ctx, "u3", true); err != nil {
+		t.Fatalf("Failed to activate user: %v", err)
+	}
+
+	if err := r.Snapshots.Import(ctx, snapshot); err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+
+	pr, err := r.PRs.GetByID(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("Expected the import to restore pr-1, got %v", err)
+	}
+	expectIDs(t, "reviewers", pr.AssignedReviewers, "u2")
+	if pr.CreatedAt.Sub(snapshot.PullRequests[0].CreatedAt).Abs() > time.Millisecond {
+		t.Fatalf("Expected the exported createdAt, got %v", pr.CreatedAt)
+	}
+
+	u3, err := r.Users.GetByID(ctx, "u3")
+	if err != nil || u3.IsActive {
+		t.Fatalf("Expected the import to deactivate u3 again, got %+v, %v", u3, err)
+	}
+
+	again, err := r.Snapshots.Export(ctx)
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+	if len(again.Teams) != len(snapshot.Teams) || len(again.PullRequests) != len(snapshot.PullRequests) {
+		t.Fatalf("Expected the same snapshot after the round trip, got %+v", again)
+	}
+}
+
+func testSnapshotFindExisting(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	createTeam(t, r, "backend", user("u1", true), user("u2", true))
+	merged := createPR(t, r, "pr-1", "u1", "u2")
+	merged.Merge()
+	if err := r.PRs.Update(ctx, merged); err != nil {
+		t.Fatalf("Failed to merge PR: %v", err)
+	}
+	if _, err := r.PRs.ArchiveMerged(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Failed to archive: %v", err)
+	}
+	createPR(t, r, "pr-2", "u1", "u2")
+	if err := r.Users.Delete(ctx, "u2"); err != nil {
+		t.Fatalf("Failed to delete user: %v", err)
+	}
+
+	snapshot := &domain.Snapshot{
+		Teams: []*domain.Team{
+			{TeamName: "backend", Members: []*domain.User{user("u2", true), user("u3", true)}},
+			{TeamName: "frontend", Members: []*domain.User{domain.NewUser("u5", "name-u1", "", true)}},
+		},
+		PullRequests: []*domain.PullRequest{
+			{PullRequestID: "pr-1", AuthorID: "u1"},
+			{PullRequestID: "pr-2", AuthorID: "u1"},
+			{PullRequestID: "pr-3", AuthorID: "u4", AssignedReviewers: []string{"u1"}},
+		},
+	}
+
+	existing, err := r.Snapshots.FindExisting(ctx, snapshot)
+	if err != nil {
+		t.Fatalf("Failed to find existing records: %v", err)
+	}
+
+	expectIDs(t, "teams", keys(existing.Teams), "backend")
+	expectIDs(t, "users", keys(existing.Users), "u1", "u2")
+	expectIDs(t, "pull requests", keys(existing.PullRequests), "pr-2")
+	expectIDs(t, "archived pull requests", keys(existing.ArchivedPullRequests), "pr-1")
+
+	// Deleted users keep their usernames.
+	wantOwners := map[string]string{"name-u1": "u1", "name-u2": "u2"}
+	if !reflect.DeepEqual(existing.UsernameOwners, wantOwners) {
+		t.Fatalf("Expected username owners %v, got %v", wantOwners, existing.UsernameOwners)
+	}
+}
+
+func keys(set map[string]bool) []string {
+	result := make([]string, 0, len(set))
+	for key := range set {
+		result = append(result, key)
+	}
+	return result
+}
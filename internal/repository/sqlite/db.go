@@ -0,0 +1,214 @@
+// Package sqlite implements the repositories on top of an embedded SQLite
+// database, so the service can run as a single binary without a database
+// server.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/XSAM/otelsql"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	_ "modernc.org/sqlite"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+type DB struct {
+	*sql.DB
+}
+
+type migration struct {
+	version int
+	path    string
+}
+
+// NewDB opens (creating if needed) the database file at path. Timestamps are
+// stored as UTC text in a fixed layout so that they compare correctly as
+// strings.
+func NewDB(path string) (*DB, error) {
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_time_format=sqlite"
+
+	db, err := otelsql.Open("sqlite", dsn,
+		otelsql.WithAttributes(semconv.DBSystemSqlite),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitRows:             true,
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	// SQLite allows a single writer. One connection serializes transactions
+	// instead of failing them with SQLITE_BUSY.
+	db.SetMaxOpenConns(1)
+
+	return &DB{db}, nil
+}
+
+func (db *DB) RunMigrations() error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	current, err := db.MigrationVersion(context.Background())
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		if err := db.applyMigration(m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (db *DB) applyMigration(m migration) error {
+	content, err := migrationsFS.ReadFile(m.path)
+	if err != nil {
+		return fmt.Errorf("failed to read migration file: %w", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err := tx.Exec(string(content)); err != nil {
+		return fmt.Errorf("failed to execute migration %d: %w", m.version, err)
+	}
+
+	if _, err := tx.Exec(`INSERT INTO schema_migrations (version) VALUES (?)`, m.version); err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", m.version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d: %w", m.version, err)
+	}
+
+	return nil
+}
+
+func (db *DB) MigrationVersion(ctx context.Context) (int, error) {
+	var version int
+	err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get migration version: %w", err)
+	}
+
+	return version, nil
+}
+
+func (db *DB) CheckMigrationVersion(ctx context.Context, expected int) error {
+	version, err := db.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	if version != expected {
+		return fmt.Errorf("schema version is %d, expected %d", version, expected)
+	}
+
+	return nil
+}
+
+// LatestMigrationVersion returns the version of the newest embedded
+// migration.
+func LatestMigrationVersion() (int, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return 0, err
+	}
+
+	if len(migrations) == 0 {
+		return 0, nil
+	}
+
+	return migrations[len(migrations)-1].version, nil
+}
+
+func loadMigrations() ([]migration, error) {
+	files, err := fs.Glob(migrationsFS, "migrations/*.up.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migration files: %w", err)
+	}
+
+	migrations := make([]migration, 0, len(files))
+	for _, file := range files {
+		prefix, _, found := strings.Cut(path.Base(file), "_")
+		if !found {
+			return nil, fmt.Errorf("invalid migration file name: %s", file)
+		}
+
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", file, err)
+		}
+
+		migrations = append(migrations, migration{version: version, path: file})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	return migrations, nil
+}
+
+func (db *DB) Close() error {
+	return db.DB.Close()
+}
+
+// idList encodes ids for use with json_each, which stands in for Postgres'
+// = ANY($1) array parameters.
+func idList(ids []string) string {
+	encoded, _ := json.Marshal(ids) //nolint:errcheck
+	return string(encoded)
+}
+
+// utc normalizes a timestamp before it is written or compared, since
+// timestamps from different zones do not compare correctly as text.
+func utc(t time.Time) time.Time {
+	return t.UTC()
+}
+
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
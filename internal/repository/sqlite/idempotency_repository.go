@@ -0,0 +1,104 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/idempotency"
+)
+
+type IdempotencyRepository struct {
+	db *DB
+}
+
+func NewIdempotencyRepository(db *DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+func (r *IdempotencyRepository) Reserve(
+	ctx context.Context,
+	scope, key, requestHash string,
+	lockTimeout time.Duration,
+) (*idempotency.Record, error) {
+	// Insert a new key, or take over one whose request never completed
+	// within lockTimeout (e.g. the replica handling it crashed).
+	now := utc(time.Now())
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO idempotency_keys (scope, idempotency_key, request_hash, created_at)
+		VALUES (?1, ?2, ?3, ?4)
+		ON CONFLICT (scope, idempotency_key) DO UPDATE
+		SET request_hash = excluded.request_hash, created_at = excluded.created_at
+		WHERE idempotency_keys.completed_at IS NULL
+		  AND idempotency_keys.request_hash = excluded.request_hash
+		  AND idempotency_keys.created_at < ?5
+	`, scope, key, requestHash, now, now.Add(-lockTimeout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	if rows > 0 {
+		return nil, nil
+	}
+
+	record := &idempotency.Record{}
+	var statusCode sql.NullInt64
+	var contentType, etag sql.NullString
+	err = r.db.QueryRowContext(ctx, `
+		SELECT request_hash, completed_at IS NOT NULL, status_code, content_type, etag, response_body
+		FROM idempotency_keys
+		WHERE scope = ? AND idempotency_key = ?
+	`, scope, key).Scan(&record.RequestHash, &record.Completed, &statusCode, &contentType, &etag, &record.Body)
+	if err == sql.ErrNoRows {
+		// Purged between the insert and the select; let the caller retry.
+		return nil, fmt.Errorf("idempotency key %q disappeared during reservation", key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	record.StatusCode = int(statusCode.Int64)
+	record.ContentType = contentType.String
+	record.ETag = etag.String
+
+	return record, nil
+}
+
+func (r *IdempotencyRepository) Complete(ctx context.Context, scope, key string, response idempotency.Response) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE idempotency_keys
+		SET status_code = ?3, content_type = ?4, etag = ?5, response_body = ?6, completed_at = ?7
+		WHERE scope = ?1 AND idempotency_key = ?2
+	`, scope, key, response.StatusCode, response.ContentType, response.ETag, response.Body, utc(time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+func (r *IdempotencyRepository) Release(ctx context.Context, scope, key string) error {
+	_, err := r.db.ExecContext(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE scope = ? AND idempotency_key = ? AND completed_at IS NULL
+	`, scope, key)
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (r *IdempotencyRepository) Purge(ctx context.Context, ttl time.Duration) error {
+	_, err := r.db.ExecContext(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE created_at < ?
+	`, utc(time.Now().Add(-ttl)))
+	if err != nil {
+		return fmt.Errorf("failed to purge idempotency keys: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS update_pull_requests_updated_at;
+DROP TRIGGER IF EXISTS update_users_updated_at;
+
+DROP TABLE IF EXISTS pr_reviewers;
+DROP TABLE IF EXISTS pull_requests;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS teams;
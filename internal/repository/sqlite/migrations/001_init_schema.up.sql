@@ -0,0 +1,76 @@
+CREATE TABLE IF NOT EXISTS teams (
+    team_name TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '+00:00')
+);
+
+CREATE INDEX IF NOT EXISTS idx_teams_created_at ON teams(created_at);
+
+CREATE TABLE IF NOT EXISTS users (
+    user_id TEXT PRIMARY KEY,
+    username TEXT NOT NULL,
+    team_name TEXT NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '+00:00'),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '+00:00'),
+    CONSTRAINT fk_users_team FOREIGN KEY (team_name)
+        REFERENCES teams(team_name)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_team_name ON users(team_name);
+CREATE INDEX IF NOT EXISTS idx_users_is_active ON users(is_active);
+CREATE INDEX IF NOT EXISTS idx_users_team_active ON users(team_name, is_active) WHERE is_active = 1;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users(username);
+
+CREATE TABLE IF NOT EXISTS pull_requests (
+    pull_request_id TEXT PRIMARY KEY,
+    pull_request_name TEXT NOT NULL,
+    author_id TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'OPEN',
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '+00:00'),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '+00:00'),
+    merged_at TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    CONSTRAINT fk_pr_author FOREIGN KEY (author_id)
+        REFERENCES users(user_id)
+        ON DELETE RESTRICT,
+    CONSTRAINT chk_pr_status CHECK (status IN ('OPEN', 'MERGED'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_author ON pull_requests(author_id);
+CREATE INDEX IF NOT EXISTS idx_pr_status ON pull_requests(status);
+CREATE INDEX IF NOT EXISTS idx_pr_created_at ON pull_requests(created_at);
+
+CREATE TABLE IF NOT EXISTS pr_reviewers (
+    pull_request_id TEXT NOT NULL,
+    reviewer_id TEXT NOT NULL,
+    assigned_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '+00:00'),
+    PRIMARY KEY (pull_request_id, reviewer_id),
+    CONSTRAINT fk_pr_reviewers_pr FOREIGN KEY (pull_request_id)
+        REFERENCES pull_requests(pull_request_id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_pr_reviewers_user FOREIGN KEY (reviewer_id)
+        REFERENCES users(user_id)
+        ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_reviewer ON pr_reviewers(reviewer_id);
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_assigned_at ON pr_reviewers(assigned_at);
+
+-- SQLite triggers cannot modify NEW, so updated_at is set by a follow-up
+-- UPDATE. Recursive triggers are off, so it does not fire again.
+CREATE TRIGGER IF NOT EXISTS update_users_updated_at AFTER UPDATE ON users
+FOR EACH ROW
+BEGIN
+    UPDATE users
+    SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') || '+00:00'
+    WHERE user_id = NEW.user_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_pull_requests_updated_at AFTER UPDATE ON pull_requests
+FOR EACH ROW
+BEGIN
+    UPDATE pull_requests
+    SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') || '+00:00'
+    WHERE pull_request_id = NEW.pull_request_id;
+END;
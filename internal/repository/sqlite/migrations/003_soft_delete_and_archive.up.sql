@@ -0,0 +1,40 @@
+ALTER TABLE teams ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE pull_requests ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_pr_merged_at ON pull_requests(merged_at) WHERE status = 'MERGED';
+
+CREATE TABLE IF NOT EXISTS pull_requests_archive (
+    pull_request_id TEXT PRIMARY KEY,
+    pull_request_name TEXT NOT NULL,
+    author_id TEXT NOT NULL,
+    status TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    merged_at TIMESTAMP,
+    version INTEGER NOT NULL,
+    deleted_at TIMESTAMP,
+    archived_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '+00:00'),
+    CONSTRAINT fk_pr_archive_author FOREIGN KEY (author_id)
+        REFERENCES users(user_id)
+        ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_archive_author ON pull_requests_archive(author_id);
+CREATE INDEX IF NOT EXISTS idx_pr_archive_merged_at ON pull_requests_archive(merged_at);
+
+CREATE TABLE IF NOT EXISTS pr_reviewers_archive (
+    pull_request_id TEXT NOT NULL,
+    reviewer_id TEXT NOT NULL,
+    assigned_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (pull_request_id, reviewer_id),
+    CONSTRAINT fk_pr_reviewers_archive_pr FOREIGN KEY (pull_request_id)
+        REFERENCES pull_requests_archive(pull_request_id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_pr_reviewers_archive_user FOREIGN KEY (reviewer_id)
+        REFERENCES users(user_id)
+        ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_archive_reviewer ON pr_reviewers_archive(reviewer_id);
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_archive_assigned_at ON pr_reviewers_archive(assigned_at);
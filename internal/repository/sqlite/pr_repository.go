@@ -0,0 +1,438 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
+)
+
+type PRRepository struct {
+	db *DB
+}
+
+func NewPRRepository(db *DB) *PRRepository {
+	return &PRRepository{db: db}
+}
+
+func (r *PRRepository) Create(ctx context.Context, pr *domain.PullRequest) error {
+	tx, err := r.db.beginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	prQuery := `
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at, updated_at, version)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err = tx.ExecContext(ctx, prQuery,
+		pr.PullRequestID,
+		pr.PullRequestName,
+		pr.AuthorID,
+		pr.Status,
+		utc(pr.CreatedAt),
+		utc(pr.UpdatedAt),
+		pr.Version,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create pull request: %w", err)
+	}
+
+	if len(pr.AssignedReviewers) > 0 {
+		reviewerQuery := `
+			INSERT INTO pr_reviewers (pull_request_id, reviewer_id, assigned_at)
+			VALUES (?, ?, ?)
+		`
+
+		assignedAt := utc(time.Now())
+		for _, reviewerID := range pr.AssignedReviewers {
+			_, err = tx.ExecContext(ctx, reviewerQuery, pr.PullRequestID, reviewerID, assignedAt)
+			if err != nil {
+				return fmt.Errorf("failed to assign reviewer %s: %w", reviewerID, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PRRepository) GetByID(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	prQuery := `
+		SELECT pull_request_id, pull_request_name, author_id, status, created_at, updated_at, merged_at, version
+		FROM pull_requests
+		WHERE pull_request_id = ? AND deleted_at IS NULL
+	`
+
+	pr := &domain.PullRequest{}
+	err := r.db.conn(ctx).QueryRowContext(ctx, prQuery, prID).Scan(
+		&pr.PullRequestID,
+		&pr.PullRequestName,
+		&pr.AuthorID,
+		&pr.Status,
+		&pr.CreatedAt,
+		&pr.UpdatedAt,
+		&pr.MergedAt,
+		&pr.Version,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrPRNotFound(prID)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request: %w", err)
+	}
+
+	reviewerQuery := `
+		SELECT reviewer_id, assigned_at
+		FROM pr_reviewers
+		WHERE pull_request_id = ?
+		ORDER BY assigned_at
+	`
+
+	rows, err := r.db.conn(ctx).QueryContext(ctx, reviewerQuery, prID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviewers: %w", err)
+	}
+	defer rows.Close()
+
+	reviewers := make([]string, 0, 2)
+	assignments := make([]domain.ReviewerAssignment, 0, 2)
+	for rows.Next() {
+		var assignment domain.ReviewerAssignment
+		if err := rows.Scan(&assignment.ReviewerID, &assignment.AssignedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan reviewer: %w", err)
+		}
+		reviewers = append(reviewers, assignment.ReviewerID)
+		assignments = append(assignments, assignment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reviewers: %w", err)
+	}
+
+	pr.AssignedReviewers = reviewers
+	pr.ReviewerAssignments = assignments
+	return pr, nil
+}
+
+// Update saves pr if it is still at pr.Version and increments the version.
+// It returns a CONFLICT error if the pull request was changed in the meantime.
+func (r *PRRepository) Update(ctx context.Context, pr *domain.PullRequest) error {
+	query := `
+		UPDATE pull_requests
+		SET pull_request_name = ?2, status = ?3, merged_at = ?4, version = version + 1
+		WHERE pull_request_id = ?1 AND version = ?5 AND deleted_at IS NULL
+		RETURNING version
+	`
+
+	var version int
+	err := r.db.conn(ctx).QueryRowContext(ctx, query,
+		pr.PullRequestID,
+		pr.PullRequestName,
+		pr.Status,
+		utcPtr(pr.MergedAt),
+		pr.Version,
+	).Scan(&version)
+
+	if err == sql.ErrNoRows {
+		return r.versionConflict(ctx, r.db.conn(ctx), pr.PullRequestID)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to update pull request: %w", err)
+	}
+
+	pr.Version = version
+	return nil
+}
+
+// ReplaceReviewer swaps a reviewer if the pull request is still at
+// expectedVersion, incrementing the version in the same transaction.
+func (r *PRRepository) ReplaceReviewer(ctx context.Context, prID string, expectedVersion int, oldReviewerID, newReviewerID string) error {
+	tx, err := r.db.beginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	versionQuery := `
+		UPDATE pull_requests
+		SET version = version + 1
+		WHERE pull_request_id = ? AND version = ? AND deleted_at IS NULL
+	`
+
+	result, err := tx.ExecContext(ctx, versionQuery, prID, expectedVersion)
+	if err != nil {
+		return fmt.Errorf("failed to update pull request version: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return r.versionConflict(ctx, tx, prID)
+	}
+
+	deleteQuery := `
+		DELETE FROM pr_reviewers
+		WHERE pull_request_id = ? AND reviewer_id = ?
+	`
+
+	result, err = tx.ExecContext(ctx, deleteQuery, prID, oldReviewerID)
+	if err != nil {
+		return fmt.Errorf("failed to delete old reviewer: %w", err)
+	}
+
+	rows, err = result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return errors.ErrNotAssigned(oldReviewerID, prID)
+	}
+
+	insertQuery := `
+		INSERT INTO pr_reviewers (pull_request_id, reviewer_id, assigned_at)
+		VALUES (?, ?, ?)
+	`
+
+	_, err = tx.ExecContext(ctx, insertQuery, prID, newReviewerID, utc(time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to insert new reviewer: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// versionConflict explains why a version-checked write matched no rows: the
+// pull request is either gone or was changed by someone else.
+func (r *PRRepository) versionConflict(ctx context.Context, q executor, prID string) error {
+	var exists bool
+	err := q.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = ? AND deleted_at IS NULL)`, prID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check PR existence: %w", err)
+	}
+
+	if !exists {
+		return errors.ErrPRNotFound(prID)
+	}
+
+	return errors.ErrConcurrentModification(prID)
+}
+
+func (r *PRRepository) GetByReviewer(ctx context.Context, reviewerID string) ([]*domain.PullRequest, error) {
+	query := `
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status
+		FROM pull_requests pr
+		INNER JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
+		WHERE prr.reviewer_id = ? AND pr.deleted_at IS NULL
+		ORDER BY pr.created_at DESC
+	`
+
+	rows, err := r.db.conn(ctx).QueryContext(ctx, query, reviewerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PRs by reviewer: %w", err)
+	}
+	defer rows.Close()
+
+	prs := make([]*domain.PullRequest, 0)
+	for rows.Next() {
+		pr := &domain.PullRequest{}
+		err := rows.Scan(
+			&pr.PullRequestID,
+			&pr.PullRequestName,
+			&pr.AuthorID,
+			&pr.Status,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pull request: %w", err)
+		}
+		prs = append(prs, pr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pull requests: %w", err)
+	}
+
+	return prs, nil
+}
+
+// Exists reports whether the pull request ID is taken, including by a deleted
+// or archived pull request.
+func (r *PRRepository) Exists(ctx context.Context, prID string) (bool, error) {
+	query := `
+		SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = ?1)
+		    OR EXISTS(SELECT 1 FROM pull_requests_archive WHERE pull_request_id = ?1)
+	`
+
+	var exists bool
+	err := r.db.conn(ctx).QueryRowContext(ctx, query, prID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check PR existence: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (r *PRRepository) GetOpenPRsWithReviewers(ctx context.Context, reviewerIDs []string) ([]domain.PRReviewerInfo, error) {
+	if len(reviewerIDs) == 0 {
+		return []domain.PRReviewerInfo{}, nil
+	}
+
+	query := `
+		SELECT pr.pull_request_id, prr.reviewer_id, u.team_name
+		FROM pull_requests pr
+		INNER JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
+		INNER JOIN users u ON prr.reviewer_id = u.user_id
+		WHERE pr.status = 'OPEN' AND pr.deleted_at IS NULL AND prr.reviewer_id IN (SELECT value FROM json_each(?))
+		ORDER BY pr.created_at
+	`
+
+	rows, err := r.db.conn(ctx).QueryContext(ctx, query, idList(reviewerIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open PRs with reviewers: %w", err)
+	}
+	defer rows.Close()
+
+	infos := make([]domain.PRReviewerInfo, 0)
+	for rows.Next() {
+		var info domain.PRReviewerInfo
+		err := rows.Scan(&info.PullRequestID, &info.ReviewerID, &info.ReviewerTeam)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan PR reviewer info: %w", err)
+		}
+		infos = append(infos, info)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating PR reviewer infos: %w", err)
+	}
+
+	return infos, nil
+}
+
+// Delete soft-deletes the pull request. Its reviewers no longer count as open
+// reviews and it drops out of the statistics.
+func (r *PRRepository) Delete(ctx context.Context, prID string) error {
+	query := `
+		UPDATE pull_requests
+		SET deleted_at = ?2
+		WHERE pull_request_id = ?1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.conn(ctx).ExecContext(ctx, query, prID, utc(time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to delete pull request: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return errors.ErrPRNotFound(prID)
+	}
+
+	return nil
+}
+
+// Restore undoes Delete. Archived pull requests cannot be restored.
+func (r *PRRepository) Restore(ctx context.Context, prID string) error {
+	query := `
+		UPDATE pull_requests
+		SET deleted_at = NULL
+		WHERE pull_request_id = ?
+	`
+
+	result, err := r.db.conn(ctx).ExecContext(ctx, query, prID)
+	if err != nil {
+		return fmt.Errorf("failed to restore pull request: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return errors.ErrPRNotFound(prID)
+	}
+
+	return nil
+}
+
+// ArchiveMerged moves pull requests merged before mergedBefore, deleted ones
+// included, to the archive tables and returns how many were moved.
+func (r *PRRepository) ArchiveMerged(ctx context.Context, mergedBefore time.Time) (int, error) {
+	tx, err := r.db.beginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	prQuery := `
+		INSERT INTO pull_requests_archive (pull_request_id, pull_request_name, author_id, status,
+		                                   created_at, updated_at, merged_at, version, deleted_at, archived_at)
+		SELECT pull_request_id, pull_request_name, author_id, status,
+		       created_at, updated_at, merged_at, version, deleted_at, ?2
+		FROM pull_requests
+		WHERE status = 'MERGED' AND merged_at < ?1
+	`
+
+	before := utc(mergedBefore)
+	if _, err := tx.ExecContext(ctx, prQuery, before, utc(time.Now())); err != nil {
+		return 0, fmt.Errorf("failed to archive pull requests: %w", err)
+	}
+
+	reviewerQuery := `
+		INSERT INTO pr_reviewers_archive (pull_request_id, reviewer_id, assigned_at)
+		SELECT prr.pull_request_id, prr.reviewer_id, prr.assigned_at
+		FROM pr_reviewers prr
+		INNER JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+		WHERE pr.status = 'MERGED' AND pr.merged_at < ?
+	`
+
+	if _, err := tx.ExecContext(ctx, reviewerQuery, before); err != nil {
+		return 0, fmt.Errorf("failed to archive reviewers: %w", err)
+	}
+
+	deleteQuery := `
+		DELETE FROM pull_requests
+		WHERE status = 'MERGED' AND merged_at < ?
+	`
+
+	result, err := tx.ExecContext(ctx, deleteQuery, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete archived pull requests: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return int(rows), nil
+}
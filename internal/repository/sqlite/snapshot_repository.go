@@ -0,0 +1,322 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+)
+
+type SnapshotRepository struct {
+	db *DB
+}
+
+func NewSnapshotRepository(db *DB) *SnapshotRepository {
+	return &SnapshotRepository{db: db}
+}
+
+func (r *SnapshotRepository) Export(ctx context.Context) (*domain.Snapshot, error) {
+	teams, err := r.exportTeams(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	prs, err := r.exportPullRequests(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Snapshot{Teams: teams, PullRequests: prs}, nil
+}
+
+func (r *SnapshotRepository) exportTeams(ctx context.Context) ([]*domain.Team, error) {
+	teamQuery := `
+		SELECT team_name, created_at
+		FROM teams
+		WHERE deleted_at IS NULL
+		ORDER BY created_at, team_name
+	`
+
+	rows, err := r.db.conn(ctx).QueryContext(ctx, teamQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export teams: %w", err)
+	}
+	defer rows.Close()
+
+	teams := make([]*domain.Team, 0)
+	byName := make(map[string]*domain.Team)
+	for rows.Next() {
+		team := &domain.Team{Members: make([]*domain.User, 0)}
+		if err := rows.Scan(&team.TeamName, &team.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan team: %w", err)
+		}
+		teams = append(teams, team)
+		byName[team.TeamName] = team
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating teams: %w", err)
+	}
+
+	userQuery := `
+		SELECT user_id, username, team_name, is_active, created_at, updated_at
+		FROM users
+		WHERE deleted_at IS NULL
+		ORDER BY created_at, user_id
+	`
+
+	userRows, err := r.db.conn(ctx).QueryContext(ctx, userQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export users: %w", err)
+	}
+	defer userRows.Close()
+
+	for userRows.Next() {
+		user := &domain.User{}
+		err := userRows.Scan(
+			&user.UserID,
+			&user.Username,
+			&user.TeamName,
+			&user.IsActive,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		if team, ok := byName[user.TeamName]; ok {
+			team.Members = append(team.Members, user)
+		}
+	}
+
+	if err := userRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return teams, nil
+}
+
+func (r *SnapshotRepository) exportPullRequests(ctx context.Context) ([]*domain.PullRequest, error) {
+	prQuery := `
+		SELECT pull_request_id, pull_request_name, author_id, status, created_at, updated_at, merged_at, version
+		FROM pull_requests
+		WHERE deleted_at IS NULL
+		ORDER BY created_at, pull_request_id
+	`
+
+	rows, err := r.db.conn(ctx).QueryContext(ctx, prQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export pull requests: %w", err)
+	}
+	defer rows.Close()
+
+	prs := make([]*domain.PullRequest, 0)
+	byID := make(map[string]*domain.PullRequest)
+	for rows.Next() {
+		pr := &domain.PullRequest{
+			AssignedReviewers:   make([]string, 0, domain.MaxReviewers),
+			ReviewerAssignments: make([]domain.ReviewerAssignment, 0, domain.MaxReviewers),
+		}
+		err := rows.Scan(
+			&pr.PullRequestID,
+			&pr.PullRequestName,
+			&pr.AuthorID,
+			&pr.Status,
+			&pr.CreatedAt,
+			&pr.UpdatedAt,
+			&pr.MergedAt,
+			&pr.Version,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pull request: %w", err)
+		}
+		prs = append(prs, pr)
+		byID[pr.PullRequestID] = pr
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pull requests: %w", err)
+	}
+
+	reviewerQuery := `
+		SELECT pull_request_id, reviewer_id, assigned_at
+		FROM pr_reviewers
+		ORDER BY assigned_at, reviewer_id
+	`
+
+	reviewerRows, err := r.db.conn(ctx).QueryContext(ctx, reviewerQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export reviewers: %w", err)
+	}
+	defer reviewerRows.Close()
+
+	for reviewerRows.Next() {
+		var prID string
+		var assignment domain.ReviewerAssignment
+		if err := reviewerRows.Scan(&prID, &assignment.ReviewerID, &assignment.AssignedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan reviewer: %w", err)
+		}
+		if pr, ok := byID[prID]; ok {
+			pr.AssignedReviewers = append(pr.AssignedReviewers, assignment.ReviewerID)
+			pr.ReviewerAssignments = append(pr.ReviewerAssignments, assignment)
+		}
+	}
+
+	if err := reviewerRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reviewers: %w", err)
+	}
+
+	return prs, nil
+}
+
+func (r *SnapshotRepository) FindExisting(ctx context.Context, snapshot *domain.Snapshot) (*domain.ExistingRecords, error) {
+	teamNames, userIDs, prIDs := snapshot.IDs()
+
+	query := `
+		SELECT 'team', team_name, '' FROM teams WHERE team_name IN (SELECT value FROM json_each(?1))
+		UNION ALL
+		SELECT 'user', user_id, '' FROM users WHERE user_id IN (SELECT value FROM json_each(?2))
+		UNION ALL
+		SELECT 'pull_request', pull_request_id, '' FROM pull_requests WHERE pull_request_id IN (SELECT value FROM json_each(?3))
+		UNION ALL
+		SELECT 'archived', pull_request_id, '' FROM pull_requests_archive WHERE pull_request_id IN (SELECT value FROM json_each(?3))
+		UNION ALL
+		SELECT 'username', username, user_id FROM users WHERE username IN (SELECT value FROM json_each(?4))
+	`
+
+	rows, err := r.db.conn(ctx).QueryContext(ctx, query,
+		idList(teamNames), idList(userIDs), idList(prIDs), idList(snapshot.Usernames()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find existing records: %w", err)
+	}
+	defer rows.Close()
+
+	existing := domain.NewExistingRecords()
+	for rows.Next() {
+		var kind, id, owner string
+		if err := rows.Scan(&kind, &id, &owner); err != nil {
+			return nil, fmt.Errorf("failed to scan existing record: %w", err)
+		}
+
+		switch kind {
+		case "team":
+			existing.Teams[id] = true
+		case "user":
+			existing.Users[id] = true
+		case "pull_request":
+			existing.PullRequests[id] = true
+		case "archived":
+			existing.ArchivedPullRequests[id] = true
+		case "username":
+			existing.UsernameOwners[id] = owner
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating existing records: %w", err)
+	}
+
+	return existing, nil
+}
+
+func (r *SnapshotRepository) Import(ctx context.Context, snapshot *domain.Snapshot) error {
+	tx, err := r.db.beginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	teamQuery := `
+		INSERT INTO teams (team_name, created_at)
+		VALUES (?1, ?2)
+		ON CONFLICT (team_name) DO UPDATE
+		SET created_at = excluded.created_at,
+		    deleted_at = NULL
+	`
+
+	for _, team := range snapshot.Teams {
+		if _, err := tx.ExecContext(ctx, teamQuery, team.TeamName, utc(team.CreatedAt)); err != nil {
+			return fmt.Errorf("failed to import team %s: %w", team.TeamName, err)
+		}
+	}
+
+	userQuery := `
+		INSERT INTO users (user_id, username, team_name, is_active, created_at, updated_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+		ON CONFLICT (user_id) DO UPDATE
+		SET username = excluded.username,
+		    team_name = excluded.team_name,
+		    is_active = excluded.is_active,
+		    created_at = excluded.created_at,
+		    updated_at = excluded.updated_at,
+		    deleted_at = NULL
+	`
+
+	for _, team := range snapshot.Teams {
+		for _, member := range team.Members {
+			_, err := tx.ExecContext(ctx, userQuery,
+				member.UserID,
+				member.Username,
+				member.TeamName,
+				member.IsActive,
+				utc(member.CreatedAt),
+				utc(member.UpdatedAt),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to import user %s: %w", member.UserID, err)
+			}
+		}
+	}
+
+	prQuery := `
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at, updated_at, merged_at, version)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8)
+		ON CONFLICT (pull_request_id) DO UPDATE
+		SET pull_request_name = excluded.pull_request_name,
+		    author_id = excluded.author_id,
+		    status = excluded.status,
+		    created_at = excluded.created_at,
+		    updated_at = excluded.updated_at,
+		    merged_at = excluded.merged_at,
+		    version = excluded.version,
+		    deleted_at = NULL
+	`
+
+	reviewerQuery := `
+		INSERT INTO pr_reviewers (pull_request_id, reviewer_id, assigned_at)
+		VALUES (?, ?, ?)
+	`
+
+	for _, pr := range snapshot.PullRequests {
+		_, err := tx.ExecContext(ctx, prQuery,
+			pr.PullRequestID,
+			pr.PullRequestName,
+			pr.AuthorID,
+			pr.Status,
+			utc(pr.CreatedAt),
+			utc(pr.UpdatedAt),
+			utcPtr(pr.MergedAt),
+			pr.Version,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to import pull request %s: %w", pr.PullRequestID, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM pr_reviewers WHERE pull_request_id = ?`, pr.PullRequestID); err != nil {
+			return fmt.Errorf("failed to clear reviewers of %s: %w", pr.PullRequestID, err)
+		}
+
+		for _, assignment := range pr.ReviewerAssignments {
+			_, err := tx.ExecContext(ctx, reviewerQuery, pr.PullRequestID, assignment.ReviewerID, utc(assignment.AssignedAt))
+			if err != nil {
+				return fmt.Errorf("failed to import reviewer %s of %s: %w", assignment.ReviewerID, pr.PullRequestID, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/idempotency"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/repository/repotest"
+)
+
+func openTestDB(t *testing.T) *DB {
+	t.Helper()
+
+	db, err := NewDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := db.RunMigrations(); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	return db
+}
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		db := openTestDB(t)
+		return repotest.Repositories{
+			Users:     NewUserRepository(db),
+			Teams:     NewTeamRepository(db),
+			PRs:       NewPRRepository(db),
+			Stats:     NewStatsRepository(db),
+			Snapshots: NewSnapshotRepository(db),
+			Tx:        NewTxManager(db),
+		}
+	})
+}
+
+func TestMigrations(t *testing.T) {
+	db := openTestDB(t)
+
+	expected, err := LatestMigrationVersion()
+	if err != nil {
+		t.Fatalf("Failed to read migrations: %v", err)
+	}
+	if err := db.CheckMigrationVersion(context.Background(), expected); err != nil {
+		t.Fatalf("Unexpected schema version: %v", err)
+	}
+
+	if err := db.RunMigrations(); err != nil {
+		t.Fatalf("Expected migrations to be idempotent: %v", err)
+	}
+}
+
+func TestIdempotencyRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewIdempotencyRepository(openTestDB(t))
+
+	record, err := repo.Reserve(ctx, "caller", "key", "hash", time.Minute)
+	if err != nil || record != nil {
+		t.Fatalf("Expected a free key, got %+v, %v", record, err)
+	}
+
+	record, err = repo.Reserve(ctx, "caller", "key", "hash", time.Minute)
+	if err != nil || record == nil || record.Completed {
+		t.Fatalf("Expected an in-progress record, got %+v, %v", record, err)
+	}
+
+	record, err = repo.Reserve(ctx, "caller", "key", "hash", 0)
+	if err != nil || record != nil {
+		t.Fatalf("Expected an abandoned key to be taken over, got %+v, %v", record, err)
+	}
+
+	response := idempotency.Response{StatusCode: 201, ContentType: "application/json", ETag: `"1"`, Body: []byte(`{}`)}
+	if err := repo.Complete(ctx, "caller", "key", response); err != nil {
+		t.Fatalf("Failed to complete: %v", err)
+	}
+
+	record, err = repo.Reserve(ctx, "caller", "key", "other", time.Minute)
+	if err != nil || record == nil || !record.Completed || record.StatusCode != 201 || record.ETag != `"1"` || string(record.Body) != `{}` {
+		t.Fatalf("Expected the completed record, got %+v, %v", record, err)
+	}
+
+	if err := repo.Purge(ctx, 0); err != nil {
+		t.Fatalf("Failed to purge: %v", err)
+	}
+	record, err = repo.Reserve(ctx, "caller", "key", "other", time.Minute)
+	if err != nil || record != nil {
+		t.Fatalf("Expected the key to be purged, got %+v, %v", record, err)
+	}
+}
@@ -0,0 +1,235 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
+)
+
+type TeamRepository struct {
+	db *DB
+}
+
+func NewTeamRepository(db *DB) *TeamRepository {
+	return &TeamRepository{db: db}
+}
+
+// Create inserts the team and upserts its members. Upserting a deleted user
+// restores them.
+func (r *TeamRepository) Create(ctx context.Context, team *domain.Team) error {
+	tx, err := r.db.beginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	teamQuery := `
+		INSERT INTO teams (team_name, created_at)
+		VALUES (?, ?)
+	`
+
+	_, err = tx.ExecContext(ctx, teamQuery, team.TeamName, utc(team.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("failed to create team: %w", err)
+	}
+
+	userQuery := `
+		INSERT INTO users (user_id, username, team_name, is_active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE
+		SET username = EXCLUDED.username,
+		    team_name = EXCLUDED.team_name,
+		    is_active = EXCLUDED.is_active,
+		    updated_at = EXCLUDED.updated_at,
+		    deleted_at = NULL
+	`
+
+	for _, member := range team.Members {
+		_, err = tx.ExecContext(ctx, userQuery,
+			member.UserID,
+			member.Username,
+			member.TeamName,
+			member.IsActive,
+			utc(member.CreatedAt),
+			utc(member.UpdatedAt),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create/update user %s: %w", member.UserID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TeamRepository) GetByName(ctx context.Context, teamName string) (*domain.Team, error) {
+	teamQuery := `
+		SELECT team_name, created_at
+		FROM teams
+		WHERE team_name = ? AND deleted_at IS NULL
+	`
+
+	team := &domain.Team{}
+	err := r.db.conn(ctx).QueryRowContext(ctx, teamQuery, teamName).Scan(
+		&team.TeamName,
+		&team.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrTeamNotFound(teamName)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+
+	userQuery := `
+		SELECT user_id, username, team_name, is_active, created_at, updated_at
+		FROM users
+		WHERE team_name = ? AND deleted_at IS NULL
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.conn(ctx).QueryContext(ctx, userQuery, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team members: %w", err)
+	}
+	defer rows.Close()
+
+	members := make([]*domain.User, 0)
+	for rows.Next() {
+		user := &domain.User{}
+		err := rows.Scan(
+			&user.UserID,
+			&user.Username,
+			&user.TeamName,
+			&user.IsActive,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		members = append(members, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating members: %w", err)
+	}
+
+	team.Members = members
+	return team, nil
+}
+
+// Exists reports whether the team name is taken, including by a deleted team.
+func (r *TeamRepository) Exists(ctx context.Context, teamName string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = ?)`
+
+	var exists bool
+	err := r.db.conn(ctx).QueryRowContext(ctx, query, teamName).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check team existence: %w", err)
+	}
+
+	return exists, nil
+}
+
+// Delete soft-deletes the team together with its members. Members get the
+// team's deleted_at, which is how Restore tells them apart from users deleted
+// on their own.
+func (r *TeamRepository) Delete(ctx context.Context, teamName string) error {
+	tx, err := r.db.beginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	now := utc(time.Now())
+
+	teamQuery := `
+		UPDATE teams
+		SET deleted_at = ?2
+		WHERE team_name = ?1 AND deleted_at IS NULL
+	`
+
+	result, err := tx.ExecContext(ctx, teamQuery, teamName, now)
+	if err != nil {
+		return fmt.Errorf("failed to delete team: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return errors.ErrTeamNotFound(teamName)
+	}
+
+	membersQuery := `
+		UPDATE users
+		SET deleted_at = ?2
+		WHERE team_name = ?1 AND deleted_at IS NULL
+	`
+
+	if _, err := tx.ExecContext(ctx, membersQuery, teamName, now); err != nil {
+		return fmt.Errorf("failed to delete team members: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Restore undoes Delete, bringing back the members that were deleted with the
+// team.
+func (r *TeamRepository) Restore(ctx context.Context, teamName string) error {
+	tx, err := r.db.beginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var deletedAt *time.Time
+	err = tx.QueryRowContext(ctx, `SELECT deleted_at FROM teams WHERE team_name = ?`, teamName).Scan(&deletedAt)
+	if err == sql.ErrNoRows {
+		return errors.ErrTeamNotFound(teamName)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to get team: %w", err)
+	}
+
+	if deletedAt == nil {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE teams SET deleted_at = NULL WHERE team_name = ?`, teamName); err != nil {
+		return fmt.Errorf("failed to restore team: %w", err)
+	}
+
+	membersQuery := `
+		UPDATE users
+		SET deleted_at = NULL
+		WHERE team_name = ?1 AND deleted_at = ?2
+	`
+
+	if _, err := tx.ExecContext(ctx, membersQuery, teamName, utc(*deletedAt)); err != nil {
+		return fmt.Errorf("failed to restore team members: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
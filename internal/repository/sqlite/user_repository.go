@@ -0,0 +1,492 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
+)
+
+type UserRepository struct {
+	db *DB
+}
+
+func NewUserRepository(db *DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	query := `
+		INSERT INTO users (user_id, username, team_name, is_active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.conn(ctx).ExecContext(ctx, query,
+		user.UserID,
+		user.Username,
+		user.TeamName,
+		user.IsActive,
+		utc(user.CreatedAt),
+		utc(user.UpdatedAt),
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return nil
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
+	query := `
+		UPDATE users
+		SET username = ?2, team_name = ?3, is_active = ?4
+		WHERE user_id = ?1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.conn(ctx).ExecContext(ctx, query,
+		user.UserID,
+		user.Username,
+		user.TeamName,
+		user.IsActive,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return errors.ErrUserNotFound(user.UserID)
+	}
+
+	return nil
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, userID string) (*domain.User, error) {
+	query := `
+		SELECT user_id, username, team_name, is_active, created_at, updated_at
+		FROM users
+		WHERE user_id = ? AND deleted_at IS NULL
+	`
+
+	user := &domain.User{}
+	err := r.db.conn(ctx).QueryRowContext(ctx, query, userID).Scan(
+		&user.UserID,
+		&user.Username,
+		&user.TeamName,
+		&user.IsActive,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrUserNotFound(userID)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+func (r *UserRepository) GetByTeam(ctx context.Context, teamName string) ([]*domain.User, error) {
+	query := `
+		SELECT user_id, username, team_name, is_active, created_at, updated_at
+		FROM users
+		WHERE team_name = ? AND deleted_at IS NULL
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.conn(ctx).QueryContext(ctx, query, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users by team: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]*domain.User, 0)
+	for rows.Next() {
+		user := &domain.User{}
+		err := rows.Scan(
+			&user.UserID,
+			&user.Username,
+			&user.TeamName,
+			&user.IsActive,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) GetActiveByTeamExcluding(ctx context.Context, teamName, excludeUserID string) ([]*domain.User, error) {
+	query := `
+		SELECT user_id, username, team_name, is_active, created_at, updated_at
+		FROM users
+		WHERE team_name = ? AND is_active = 1 AND user_id != ? AND deleted_at IS NULL
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.conn(ctx).QueryContext(ctx, query, teamName, excludeUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active users: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]*domain.User, 0)
+	for rows.Next() {
+		user := &domain.User{}
+		err := rows.Scan(
+			&user.UserID,
+			&user.Username,
+			&user.TeamName,
+			&user.IsActive,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) SetActive(ctx context.Context, userID string, isActive bool) error {
+	query := `
+		UPDATE users
+		SET is_active = ?2
+		WHERE user_id = ?1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.conn(ctx).ExecContext(ctx, query, userID, isActive)
+	if err != nil {
+		return fmt.Errorf("failed to set user active status: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return errors.ErrUserNotFound(userID)
+	}
+
+	return nil
+}
+
+// Exists reports whether the user ID is taken, including by a deleted user.
+func (r *UserRepository) Exists(ctx context.Context, userID string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE user_id = ?)`
+
+	var exists bool
+	err := r.db.conn(ctx).QueryRowContext(ctx, query, userID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check user existence: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (r *UserRepository) BulkDeactivate(ctx context.Context, userIDs []string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	query := `
+		UPDATE users
+		SET is_active = 0
+		WHERE user_id IN (SELECT value FROM json_each(?)) AND deleted_at IS NULL
+	`
+
+	_, err := r.db.conn(ctx).ExecContext(ctx, query, idList(userIDs))
+	if err != nil {
+		return fmt.Errorf("failed to bulk deactivate users: %w", err)
+	}
+
+	return nil
+}
+
+// Upsert creates the users or updates the username, team and active flag of
+// existing ones, bringing deleted users back. CreatedAt of existing users is
+// kept.
+func (r *UserRepository) Upsert(ctx context.Context, users []*domain.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.beginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	query := `
+		INSERT INTO users (user_id, username, team_name, is_active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE
+		SET username = EXCLUDED.username,
+		    team_name = EXCLUDED.team_name,
+		    is_active = EXCLUDED.is_active,
+		    updated_at = EXCLUDED.updated_at,
+		    deleted_at = NULL
+	`
+
+	for _, user := range users {
+		_, err := tx.ExecContext(ctx, query,
+			user.UserID,
+			user.Username,
+			user.TeamName,
+			user.IsActive,
+			utc(user.CreatedAt),
+			utc(user.UpdatedAt),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to upsert user %s: %w", user.UserID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *UserRepository) GetUsersByIDs(ctx context.Context, userIDs []string) ([]*domain.User, error) {
+	if len(userIDs) == 0 {
+		return []*domain.User{}, nil
+	}
+
+	query := `
+		SELECT user_id, username, team_name, is_active, created_at, updated_at
+		FROM users
+		WHERE user_id IN (SELECT value FROM json_each(?)) AND deleted_at IS NULL
+	`
+
+	rows, err := r.db.conn(ctx).QueryContext(ctx, query, idList(userIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users by IDs: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]*domain.User, 0)
+	for rows.Next() {
+		user := &domain.User{}
+		err := rows.Scan(
+			&user.UserID,
+			&user.Username,
+			&user.TeamName,
+			&user.IsActive,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) List(ctx context.Context, filter domain.UserFilter) ([]*domain.User, error) {
+	query := `
+		SELECT user_id, username, team_name, is_active, created_at, updated_at
+		FROM users
+		WHERE deleted_at IS NULL
+		  AND (?1 = '' OR team_name = ?1)
+		  AND (?2 IS NULL OR is_active = ?2)
+		ORDER BY team_name, created_at
+	`
+
+	rows, err := r.db.conn(ctx).QueryContext(ctx, query, filter.TeamName, filter.IsActive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]*domain.User, 0)
+	for rows.Next() {
+		user := &domain.User{}
+		err := rows.Scan(
+			&user.UserID,
+			&user.Username,
+			&user.TeamName,
+			&user.IsActive,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) GetOpenReviewCounts(ctx context.Context, userIDs []string) (map[string]int, error) {
+	counts := make(map[string]int, len(userIDs))
+	if len(userIDs) == 0 {
+		return counts, nil
+	}
+
+	query := `
+		SELECT prr.reviewer_id, COUNT(*)
+		FROM pr_reviewers prr
+		INNER JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+		WHERE pr.status = 'OPEN' AND pr.deleted_at IS NULL AND prr.reviewer_id IN (SELECT value FROM json_each(?))
+		GROUP BY prr.reviewer_id
+	`
+
+	rows, err := r.db.conn(ctx).QueryContext(ctx, query, idList(userIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open review counts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			userID string
+			count  int
+		)
+		if err := rows.Scan(&userID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan open review count: %w", err)
+		}
+		counts[userID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating open review counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+func (r *UserRepository) GetUsernameOwners(ctx context.Context, usernames []string) (map[string]string, error) {
+	owners := make(map[string]string, len(usernames))
+	if len(usernames) == 0 {
+		return owners, nil
+	}
+
+	query := `
+		SELECT username, user_id
+		FROM users
+		WHERE username IN (SELECT value FROM json_each(?))
+	`
+
+	rows, err := r.db.conn(ctx).QueryContext(ctx, query, idList(usernames))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get username owners: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var username, userID string
+		if err := rows.Scan(&username, &userID); err != nil {
+			return nil, fmt.Errorf("failed to scan username owner: %w", err)
+		}
+		owners[username] = userID
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating username owners: %w", err)
+	}
+
+	return owners, nil
+}
+
+// Delete soft-deletes the user. Their pull requests and review history stay in
+// place.
+func (r *UserRepository) Delete(ctx context.Context, userID string) error {
+	query := `
+		UPDATE users
+		SET deleted_at = ?2
+		WHERE user_id = ?1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.conn(ctx).ExecContext(ctx, query, userID, utc(time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return errors.ErrUserNotFound(userID)
+	}
+
+	return nil
+}
+
+// Restore undoes Delete. A user of a deleted team can only come back with the
+// team, so it fails with the team's NOT_FOUND error in that case.
+func (r *UserRepository) Restore(ctx context.Context, userID string) error {
+	query := `
+		UPDATE users
+		SET deleted_at = NULL
+		WHERE user_id = ?
+		  AND EXISTS(SELECT 1 FROM teams t WHERE t.team_name = users.team_name AND t.deleted_at IS NULL)
+	`
+
+	result, err := r.db.conn(ctx).ExecContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to restore user: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows > 0 {
+		return nil
+	}
+
+	var teamName string
+	err = r.db.conn(ctx).QueryRowContext(ctx, `SELECT team_name FROM users WHERE user_id = ?`, userID).Scan(&teamName)
+	if err == sql.ErrNoRows {
+		return errors.ErrUserNotFound(userID)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to restore user: %w", err)
+	}
+
+	return errors.ErrTeamNotFound(teamName)
+}
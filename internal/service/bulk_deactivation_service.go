@@ -3,15 +3,28 @@ package service
 import (
 	"context"
 	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
-	"github.com/Raisondetr3/Avito-test-assignment/internal/repository/postgres"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/metrics"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/tracing"
 )
 
+// TxManager runs fn atomically: repository calls made with the context passed
+// to fn share one transaction. WithinReadOnlyTx is for reads that must see one
+// consistent snapshot across several queries.
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	WithinReadOnlyTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type BulkDeactivationService struct {
 	userRepo         UserRepository
 	teamRepo         TeamRepository
 	prRepo           PRRepository
+	txManager        TxManager
 	reviewerAssigner *ReviewerAssigner
 }
 
@@ -19,21 +32,92 @@ func NewBulkDeactivationService(
 	userRepo UserRepository,
 	teamRepo TeamRepository,
 	prRepo PRRepository,
+	txManager TxManager,
 	reviewerAssigner *ReviewerAssigner,
 ) *BulkDeactivationService {
 	return &BulkDeactivationService{
 		userRepo:         userRepo,
 		teamRepo:         teamRepo,
 		prRepo:           prRepo,
+		txManager:        txManager,
 		reviewerAssigner: reviewerAssigner,
 	}
 }
 
-//nolint:gocyclo
+// DeactivateUsersAndReassignPRs deactivates the users and moves their open
+// reviews to other active team members. By default everything happens in one
+// transaction and any failure leaves the data untouched. With
+// opts.BestEffort each reassignment is committed separately and failures are
+// reported as skipped PRs instead. PRs without a replacement candidate are
+// skipped in both modes.
 func (s *BulkDeactivationService) DeactivateUsersAndReassignPRs(
 	ctx context.Context,
 	teamName string,
 	userIDs []string,
+	opts domain.BulkDeactivationOptions,
+) (_ *domain.BulkDeactivationResult, err error) {
+	ctx, span := tracing.StartSpan(ctx, "BulkDeactivationService.DeactivateUsersAndReassignPRs",
+		attribute.String("team.name", teamName),
+		attribute.Int("users.requested", len(userIDs)),
+		attribute.Bool("best_effort", opts.BestEffort),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	start := time.Now()
+	defer func() {
+		metrics.BulkDeactivationDuration.Observe(time.Since(start).Seconds())
+	}()
+
+	if opts.BestEffort {
+		return s.deactivate(ctx, teamName, userIDs, true)
+	}
+
+	var result *domain.BulkDeactivationResult
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = s.deactivate(ctx, teamName, userIDs, false)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	recordReassignments(result)
+
+	return result, nil
+}
+
+// skipNoCandidate is the skip reason for a PR whose reviewer's team has
+// nobody left to take over.
+const skipNoCandidate = "no active replacement candidate in team"
+
+// recordReassignments counts the reassignments and missing candidates in a
+// result produced inside a transaction. Call it once the transaction has
+// committed, so rolled back work is never counted.
+func recordReassignments(result *domain.BulkDeactivationResult) {
+	if result == nil {
+		return
+	}
+	if n := len(result.ReassignedPRs); n > 0 {
+		metrics.ReviewerReassignmentsTotal.WithLabelValues(metrics.SourceBulkDeactivation).Add(float64(n))
+	}
+	for _, skipped := range result.SkippedPRs {
+		if skipped.Reason == skipNoCandidate {
+			metrics.NoCandidateTotal.WithLabelValues(metrics.SourceBulkDeactivation).Inc()
+		}
+	}
+}
+
+// deactivate does the work of DeactivateUsersAndReassignPRs. Without
+// bestEffort it runs in the caller's transaction and leaves the metrics to
+// recordReassignments; with bestEffort each reassignment commits on its own
+// and is counted right away.
+//
+//nolint:gocyclo
+func (s *BulkDeactivationService) deactivate(
+	ctx context.Context,
+	teamName string,
+	userIDs []string,
+	bestEffort bool,
 ) (*domain.BulkDeactivationResult, error) {
 	team, err := s.teamRepo.GetByName(ctx, teamName)
 	if err != nil {
@@ -70,7 +154,7 @@ func (s *BulkDeactivationService) DeactivateUsersAndReassignPRs(
 		}, nil
 	}
 
-	openPRsInfo, err := s.prRepo.(*postgres.PRRepository).GetOpenPRsWithReviewers(ctx, usersToDeactivate) //nolint:errcheck
+	openPRsInfo, err := s.prRepo.GetOpenPRsWithReviewers(ctx, usersToDeactivate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get open PRs: %w", err)
 	}
@@ -84,72 +168,29 @@ func (s *BulkDeactivationService) DeactivateUsersAndReassignPRs(
 	skippedPRs := make([]domain.SkippedPR, 0)
 
 	for _, prInfo := range openPRsInfo {
-		candidates, err := s.userRepo.GetActiveByTeamExcluding(ctx, prInfo.ReviewerTeam, prInfo.ReviewerID)
-		if err != nil {
-			skippedPRs = append(skippedPRs, domain.SkippedPR{
-				PullRequestID: prInfo.PullRequestID,
-				Reason:        fmt.Sprintf("failed to get candidates: %v", err),
-			})
-			continue
-		}
-
-		excludeUsers := []string{prInfo.ReviewerID}
-		pr, err := s.prRepo.GetByID(ctx, prInfo.PullRequestID)
+		reassigned, skipReason, err := s.reassign(ctx, prInfo)
 		if err != nil {
-			skippedPRs = append(skippedPRs, domain.SkippedPR{
-				PullRequestID: prInfo.PullRequestID,
-				Reason:        fmt.Sprintf("failed to get PR: %v", err),
-			})
-			continue
-		}
-
-		excludeUsers = append(excludeUsers, pr.AssignedReviewers...)
-
-		availableCandidates := make([]*domain.User, 0)
-		for _, candidate := range candidates {
-			isExcluded := false
-			for _, excludeID := range excludeUsers {
-				if candidate.UserID == excludeID {
-					isExcluded = true
-					break
-				}
-			}
-			if !isExcluded {
-				availableCandidates = append(availableCandidates, candidate)
+			if !bestEffort {
+				return nil, fmt.Errorf("failed to reassign PR %s: %w", prInfo.PullRequestID, err)
 			}
+			skipReason = err.Error()
 		}
 
-		if len(availableCandidates) == 0 {
-			skippedPRs = append(skippedPRs, domain.SkippedPR{
-				PullRequestID: prInfo.PullRequestID,
-				Reason:        "no active replacement candidate in team",
-			})
-			continue
-		}
-
-		newReviewerID, ok := s.reviewerAssigner.SelectRandomReviewer(availableCandidates)
-		if !ok {
+		if skipReason != "" {
+			if bestEffort && skipReason == skipNoCandidate {
+				metrics.NoCandidateTotal.WithLabelValues(metrics.SourceBulkDeactivation).Inc()
+			}
 			skippedPRs = append(skippedPRs, domain.SkippedPR{
 				PullRequestID: prInfo.PullRequestID,
-				Reason:        "failed to select reviewer",
+				Reason:        skipReason,
 			})
 			continue
 		}
 
-		err = s.prRepo.ReplaceReviewer(ctx, prInfo.PullRequestID, prInfo.ReviewerID, newReviewerID)
-		if err != nil {
-			skippedPRs = append(skippedPRs, domain.SkippedPR{
-				PullRequestID: prInfo.PullRequestID,
-				Reason:        fmt.Sprintf("failed to replace reviewer: %v", err),
-			})
-			continue
+		if bestEffort {
+			metrics.ReviewerReassignmentsTotal.WithLabelValues(metrics.SourceBulkDeactivation).Inc()
 		}
-
-		reassignedPRs = append(reassignedPRs, domain.ReassignedPR{
-			PullRequestID: prInfo.PullRequestID,
-			OldReviewerID: prInfo.ReviewerID,
-			NewReviewerID: newReviewerID,
-		})
+		reassignedPRs = append(reassignedPRs, *reassigned)
 	}
 
 	return &domain.BulkDeactivationResult{
@@ -158,3 +199,38 @@ func (s *BulkDeactivationService) DeactivateUsersAndReassignPRs(
 		SkippedPRs:       skippedPRs,
 	}, nil
 }
+
+// reassign replaces a deactivated reviewer on one PR. It returns a skip reason
+// when the team has nobody to take over, and an error when the operation failed.
+func (s *BulkDeactivationService) reassign(
+	ctx context.Context,
+	prInfo domain.PRReviewerInfo,
+) (*domain.ReassignedPR, string, error) {
+	candidates, err := s.userRepo.GetActiveByTeamExcluding(ctx, prInfo.ReviewerTeam, prInfo.ReviewerID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get candidates: %w", err)
+	}
+
+	pr, err := s.prRepo.GetByID(ctx, prInfo.PullRequestID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get PR: %w", err)
+	}
+
+	availableCandidates := excludeUsers(candidates, append([]string{prInfo.ReviewerID, pr.AuthorID}, pr.AssignedReviewers...))
+
+	newReviewerID, ok := s.reviewerAssigner.SelectRandomReviewer(availableCandidates)
+	if !ok {
+		return nil, skipNoCandidate, nil
+	}
+
+	err = s.prRepo.ReplaceReviewer(ctx, prInfo.PullRequestID, pr.Version, prInfo.ReviewerID, newReviewerID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to replace reviewer: %w", err)
+	}
+
+	return &domain.ReassignedPR{
+		PullRequestID: prInfo.PullRequestID,
+		OldReviewerID: prInfo.ReviewerID,
+		NewReviewerID: newReviewerID,
+	}, "", nil
+}
@@ -0,0 +1,374 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/metrics"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/repository/memory"
+)
+
+// testStore wires the services under test to the in-memory backend. Tests
+// seed it and inspect it through the repositories.
+type testStore struct {
+	t         *testing.T
+	users     *memory.UserRepository
+	teams     *memory.TeamRepository
+	prs       *failingPRRepo
+	snapshots *memory.SnapshotRepository
+	tx        *countingTxManager
+}
+
+func newTestStore(t *testing.T) *testStore {
+	store := memory.NewStore()
+	return &testStore{
+		t:         t,
+		users:     memory.NewUserRepository(store),
+		teams:     memory.NewTeamRepository(store),
+		prs:       &failingPRRepo{PRRepository: memory.NewPRRepository(store), errs: make(map[string]error)},
+		snapshots: memory.NewSnapshotRepository(store),
+		tx:        &countingTxManager{TxManager: memory.NewTxManager(store)},
+	}
+}
+
+// addTeam creates an active user for every ID, with the ID as username.
+func (s *testStore) addTeam(teamName string, userIDs ...string) {
+	s.t.Helper()
+	members := make([]*domain.User, 0, len(userIDs))
+	for _, id := range userIDs {
+		members = append(members, domain.NewUser(id, id, teamName, true))
+	}
+	if err := s.teams.Create(context.Background(), domain.NewTeam(teamName, members)); err != nil {
+		s.t.Fatalf("Failed to create team %s: %v", teamName, err)
+	}
+}
+
+func (s *testStore) addPR(prID, authorID string, reviewerIDs ...string) {
+	s.t.Helper()
+	pr := domain.NewPullRequest(prID, prID, authorID)
+	pr.AssignReviewers(reviewerIDs)
+	if err := s.prs.Create(context.Background(), pr); err != nil {
+		s.t.Fatalf("Failed to create PR %s: %v", prID, err)
+	}
+}
+
+func (s *testStore) mergePR(prID string) {
+	s.t.Helper()
+	pr := s.pr(prID)
+	pr.Merge()
+	if err := s.prs.Update(context.Background(), pr); err != nil {
+		s.t.Fatalf("Failed to merge PR %s: %v", prID, err)
+	}
+}
+
+func (s *testStore) user(userID string) *domain.User {
+	s.t.Helper()
+	user, err := s.users.GetByID(context.Background(), userID)
+	if err != nil {
+		s.t.Fatalf("Failed to get user %s: %v", userID, err)
+	}
+	return user
+}
+
+func (s *testStore) hasUser(userID string) bool {
+	_, err := s.users.GetByID(context.Background(), userID)
+	return err == nil
+}
+
+func (s *testStore) hasTeam(teamName string) bool {
+	_, err := s.teams.GetByName(context.Background(), teamName)
+	return err == nil
+}
+
+func (s *testStore) pr(prID string) *domain.PullRequest {
+	s.t.Helper()
+	pr, err := s.prs.GetByID(context.Background(), prID)
+	if err != nil {
+		s.t.Fatalf("Failed to get PR %s: %v", prID, err)
+	}
+	return pr
+}
+
+// failingPRRepo makes ReplaceReviewer fail for the pull requests in errs.
+type failingPRRepo struct {
+	*memory.PRRepository
+	errs map[string]error
+}
+
+func (r *failingPRRepo) ReplaceReviewer(ctx context.Context, prID string, expectedVersion int, oldReviewerID, newReviewerID string) error {
+	if err := r.errs[prID]; err != nil {
+		return err
+	}
+	return r.PRRepository.ReplaceReviewer(ctx, prID, expectedVersion, oldReviewerID, newReviewerID)
+}
+
+// countingTxManager counts the transactions a service starts.
+type countingTxManager struct {
+	*memory.TxManager
+	calls int
+}
+
+func (m *countingTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.calls++
+	return m.TxManager.WithinTx(ctx, fn)
+}
+
+func newTestBulkService(s *testStore) *BulkDeactivationService {
+	return NewBulkDeactivationService(s.users, s.teams, s.prs, s.tx, NewReviewerAssigner())
+}
+
+func TestBulkDeactivationReassignsOpenReviews(t *testing.T) {
+	s := newTestStore(t)
+	s.addTeam("backend", "alice", "bob", "carol", "dave")
+	s.addPR("pr-1", "alice", "bob", "carol")
+	s.addPR("pr-2", "alice", "bob")
+	s.mergePR("pr-2")
+
+	svc := newTestBulkService(s)
+
+	result, err := svc.DeactivateUsersAndReassignPRs(context.Background(), "backend", []string{"bob"}, domain.BulkDeactivationOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if s.tx.calls != 1 {
+		t.Fatalf("Expected the operation to run in one transaction, got %d", s.tx.calls)
+	}
+	if len(result.DeactivatedUsers) != 1 || result.DeactivatedUsers[0] != "bob" {
+		t.Fatalf("Expected bob to be deactivated, got %v", result.DeactivatedUsers)
+	}
+	if s.user("bob").IsActive {
+		t.Fatal("Expected bob to be inactive")
+	}
+
+	// The author, the other reviewer and bob are excluded, so dave is
+	// the only possible replacement.
+	want := domain.ReassignedPR{PullRequestID: "pr-1", OldReviewerID: "bob", NewReviewerID: "dave"}
+	if len(result.ReassignedPRs) != 1 || result.ReassignedPRs[0] != want {
+		t.Fatalf("Expected %v, got %v", want, result.ReassignedPRs)
+	}
+	if len(result.SkippedPRs) != 0 {
+		t.Fatalf("Expected no skipped PRs, got %v", result.SkippedPRs)
+	}
+
+	pr := s.pr("pr-1")
+	if !pr.HasReviewer("dave") || !pr.HasReviewer("carol") || pr.HasReviewer("bob") {
+		t.Fatalf("Expected reviewers [dave carol], got %v", pr.AssignedReviewers)
+	}
+	if pr.Version != 2 {
+		t.Fatalf("Expected version 2, got %d", pr.Version)
+	}
+	if !s.pr("pr-2").HasReviewer("bob") {
+		t.Fatal("Expected merged PR to keep its reviewers")
+	}
+}
+
+func TestBulkDeactivationExcludesAssignedCandidates(t *testing.T) {
+	// Repeat to make sure the only valid candidate is not picked by chance.
+	for i := 0; i < 50; i++ {
+		s := newTestStore(t)
+		s.addTeam("backend", "alice", "bob", "carol", "dave", "erin")
+		s.addPR("pr-1", "alice", "bob", "carol")
+
+		svc := newTestBulkService(s)
+
+		result, err := svc.DeactivateUsersAndReassignPRs(context.Background(), "backend", []string{"bob", "dave"}, domain.BulkDeactivationOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(result.ReassignedPRs) != 1 || result.ReassignedPRs[0].NewReviewerID != "erin" {
+			t.Fatalf("Expected erin to replace bob, got %v", result.ReassignedPRs)
+		}
+	}
+}
+
+func TestBulkDeactivationSkipsPRWithoutCandidate(t *testing.T) {
+	s := newTestStore(t)
+	s.addTeam("backend", "alice", "bob", "carol")
+	s.addPR("pr-1", "alice", "bob", "carol")
+
+	svc := newTestBulkService(s)
+
+	result, err := svc.DeactivateUsersAndReassignPRs(context.Background(), "backend", []string{"bob"}, domain.BulkDeactivationOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := domain.SkippedPR{PullRequestID: "pr-1", Reason: "no active replacement candidate in team"}
+	if len(result.SkippedPRs) != 1 || result.SkippedPRs[0] != want {
+		t.Fatalf("Expected %v, got %v", want, result.SkippedPRs)
+	}
+	if len(result.ReassignedPRs) != 0 {
+		t.Fatalf("Expected no reassigned PRs, got %v", result.ReassignedPRs)
+	}
+	if s.user("bob").IsActive {
+		t.Fatal("Expected bob to be deactivated even though the PR was skipped")
+	}
+	if !s.pr("pr-1").HasReviewer("bob") {
+		t.Fatal("Expected skipped PR to keep its reviewers")
+	}
+}
+
+func TestBulkDeactivationRollsBackOnFailure(t *testing.T) {
+	s := newTestStore(t)
+	s.addTeam("backend", "alice", "bob", "carol", "dave")
+	s.addPR("pr-1", "alice", "bob")
+	s.addPR("pr-2", "alice", "bob")
+	s.prs.errs["pr-2"] = stderrors.New("connection reset")
+
+	svc := newTestBulkService(s)
+
+	_, err := svc.DeactivateUsersAndReassignPRs(context.Background(), "backend", []string{"bob"}, domain.BulkDeactivationOptions{})
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if !strings.Contains(err.Error(), "pr-2") {
+		t.Fatalf("Expected error to name the failed PR, got %v", err)
+	}
+
+	if !s.user("bob").IsActive {
+		t.Fatal("Expected deactivation to be rolled back")
+	}
+	if pr := s.pr("pr-1"); !pr.HasReviewer("bob") || pr.Version != 1 {
+		t.Fatalf("Expected pr-1 to be rolled back, got %v (version %d)", pr.AssignedReviewers, pr.Version)
+	}
+}
+
+func TestBulkDeactivationBestEffortReportsFailures(t *testing.T) {
+	s := newTestStore(t)
+	s.addTeam("backend", "alice", "bob", "carol", "dave")
+	s.addPR("pr-1", "alice", "bob")
+	s.addPR("pr-2", "alice", "bob")
+	s.prs.errs["pr-2"] = stderrors.New("connection reset")
+
+	svc := newTestBulkService(s)
+
+	result, err := svc.DeactivateUsersAndReassignPRs(context.Background(), "backend", []string{"bob"}, domain.BulkDeactivationOptions{BestEffort: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if s.tx.calls != 0 {
+		t.Fatalf("Expected no transaction in best-effort mode, got %d", s.tx.calls)
+	}
+	if len(result.ReassignedPRs) != 1 || result.ReassignedPRs[0].PullRequestID != "pr-1" {
+		t.Fatalf("Expected pr-1 to be reassigned, got %v", result.ReassignedPRs)
+	}
+	if len(result.SkippedPRs) != 1 || result.SkippedPRs[0].PullRequestID != "pr-2" {
+		t.Fatalf("Expected pr-2 to be skipped, got %v", result.SkippedPRs)
+	}
+	if !strings.Contains(result.SkippedPRs[0].Reason, "connection reset") {
+		t.Fatalf("Expected skip reason to contain the failure, got %q", result.SkippedPRs[0].Reason)
+	}
+	if s.user("bob").IsActive {
+		t.Fatal("Expected bob to stay deactivated")
+	}
+}
+
+func TestBulkDeactivationWholeTeam(t *testing.T) {
+	s := newTestStore(t)
+	s.addTeam("backend", "alice", "bob")
+	s.addTeam("frontend", "carol")
+	s.addPR("pr-1", "carol", "alice")
+
+	svc := newTestBulkService(s)
+
+	result, err := svc.DeactivateUsersAndReassignPRs(context.Background(), "backend", nil, domain.BulkDeactivationOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(result.DeactivatedUsers) != 2 {
+		t.Fatalf("Expected 2 deactivated users, got %v", result.DeactivatedUsers)
+	}
+	if s.user("alice").IsActive || s.user("bob").IsActive {
+		t.Fatal("Expected the whole team to be deactivated")
+	}
+	if !s.user("carol").IsActive {
+		t.Fatal("Expected other teams to be untouched")
+	}
+	if len(result.SkippedPRs) != 1 || result.SkippedPRs[0].PullRequestID != "pr-1" {
+		t.Fatalf("Expected pr-1 to be skipped, got %v", result.SkippedPRs)
+	}
+}
+
+func TestBulkDeactivationRejectsInvalidInput(t *testing.T) {
+	s := newTestStore(t)
+	s.addTeam("backend", "alice", "bob")
+	s.addTeam("frontend", "carol")
+
+	svc := newTestBulkService(s)
+
+	_, err := svc.DeactivateUsersAndReassignPRs(context.Background(), "missing", []string{"alice"}, domain.BulkDeactivationOptions{})
+	var appErr *errors.AppError
+	if !stderrors.As(err, &appErr) || appErr.Code != errors.ErrCodeNotFound {
+		t.Fatalf("Expected NOT_FOUND for unknown team, got %v", err)
+	}
+
+	_, err = svc.DeactivateUsersAndReassignPRs(context.Background(), "backend", []string{"alice", "carol"}, domain.BulkDeactivationOptions{})
+	if err == nil {
+		t.Fatal("Expected an error for a user from another team")
+	}
+	if !s.user("alice").IsActive || !s.user("carol").IsActive {
+		t.Fatal("Expected no users to be deactivated")
+	}
+}
+
+func TestBulkDeactivationMetricsCountCommittedWork(t *testing.T) {
+	reassigned := metrics.ReviewerReassignmentsTotal.WithLabelValues(metrics.SourceBulkDeactivation)
+	noCandidate := metrics.NoCandidateTotal.WithLabelValues(metrics.SourceBulkDeactivation)
+
+	tests := []struct {
+		name            string
+		members         []string
+		reviewers       []string
+		fail            bool
+		bestEffort      bool
+		wantReassigned  float64
+		wantNoCandidate float64
+	}{
+		{name: "committed", members: []string{"alice", "bob", "carol", "dave"}, reviewers: []string{"bob"}, wantReassigned: 2},
+		{name: "no candidate", members: []string{"alice", "bob", "carol"}, reviewers: []string{"bob", "carol"}, wantNoCandidate: 2},
+		{name: "rolled back", members: []string{"alice", "bob", "carol", "dave"}, reviewers: []string{"bob"}, fail: true},
+		{
+			name: "best effort", members: []string{"alice", "bob", "carol", "dave"}, reviewers: []string{"bob"},
+			fail: true, bestEffort: true, wantReassigned: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStore(t)
+			s.addTeam("backend", tt.members...)
+			s.addPR("pr-1", "alice", tt.reviewers...)
+			s.addPR("pr-2", "alice", tt.reviewers...)
+			if tt.fail {
+				s.prs.errs["pr-2"] = stderrors.New("connection reset")
+			}
+
+			reassignedBefore := testutil.ToFloat64(reassigned)
+			noCandidateBefore := testutil.ToFloat64(noCandidate)
+
+			svc := newTestBulkService(s)
+			_, err := svc.DeactivateUsersAndReassignPRs(context.Background(), "backend", []string{"bob"},
+				domain.BulkDeactivationOptions{BestEffort: tt.bestEffort})
+			if (err != nil) != (tt.fail && !tt.bestEffort) {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if got := testutil.ToFloat64(reassigned) - reassignedBefore; got != tt.wantReassigned {
+				t.Fatalf("Expected %v reassignments counted, got %v", tt.wantReassigned, got)
+			}
+			if got := testutil.ToFloat64(noCandidate) - noCandidateBefore; got != tt.wantNoCandidate {
+				t.Fatalf("Expected %v missing candidates counted, got %v", tt.wantNoCandidate, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+)
+
+type histogramBound struct {
+	label string
+	limit time.Duration
+}
+
+var cycleTimeBuckets = []histogramBound{
+	{label: "1h", limit: time.Hour},
+	{label: "4h", limit: 4 * time.Hour},
+	{label: "1d", limit: 24 * time.Hour},
+	{label: "3d", limit: 3 * 24 * time.Hour},
+	{label: "7d", limit: 7 * 24 * time.Hour},
+	{label: "+Inf", limit: time.Duration(math.MaxInt64)},
+}
+
+func buildCycleTimeStats(timings []domain.MergedPRTiming) *domain.CycleTimeStats {
+	all := make([]time.Duration, 0, len(timings))
+	byTeam := make(map[string][]time.Duration)
+	byAuthor := make(map[string][]time.Duration)
+	authorTeams := make(map[string]string)
+
+	for _, t := range timings {
+		d := t.TimeToMerge()
+		all = append(all, d)
+		byTeam[t.TeamName] = append(byTeam[t.TeamName], d)
+		byAuthor[t.AuthorID] = append(byAuthor[t.AuthorID], d)
+		authorTeams[t.AuthorID] = t.TeamName
+	}
+
+	teams := make([]domain.TeamCycleTime, 0, len(byTeam))
+	for teamName, durations := range byTeam {
+		teams = append(teams, domain.TeamCycleTime{
+			TeamName:    teamName,
+			TimeToMerge: summarizeDurations(durations),
+		})
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		return teams[i].TeamName < teams[j].TeamName
+	})
+
+	authors := make([]domain.AuthorCycleTime, 0, len(byAuthor))
+	for authorID, durations := range byAuthor {
+		authors = append(authors, domain.AuthorCycleTime{
+			AuthorID:    authorID,
+			TeamName:    authorTeams[authorID],
+			TimeToMerge: summarizeDurations(durations),
+		})
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		return authors[i].AuthorID < authors[j].AuthorID
+	})
+
+	return &domain.CycleTimeStats{
+		TimeToMerge: summarizeDurations(all),
+		ByTeam:      teams,
+		ByAuthor:    authors,
+	}
+}
+
+func summarizeDurations(durations []time.Duration) domain.DurationSummary {
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+
+	histogram := make([]domain.HistogramBucket, len(cycleTimeBuckets))
+	for i, bound := range cycleTimeBuckets {
+		histogram[i].UpperBound = bound.label
+	}
+	for _, d := range sorted {
+		for i, bound := range cycleTimeBuckets {
+			if d <= bound.limit {
+				histogram[i].Count++
+				break
+			}
+		}
+	}
+
+	return domain.DurationSummary{
+		Count:      len(sorted),
+		P50Seconds: percentile(sorted, 0.50).Seconds(),
+		P90Seconds: percentile(sorted, 0.90).Seconds(),
+		P99Seconds: percentile(sorted, 0.99).Seconds(),
+		Histogram:  histogram,
+	}
+}
+
+// percentile uses the nearest-rank method on an already sorted slice.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+)
+
+func TestPercentile(t *testing.T) {
+	tenMinutes := make([]time.Duration, 10)
+	for i := range tenMinutes {
+		tenMinutes[i] = time.Duration(i+1) * time.Minute
+	}
+	four := []time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second, 40 * time.Second}
+
+	tests := []struct {
+		name   string
+		sorted []time.Duration
+		p      float64
+		want   time.Duration
+	}{
+		{"empty", nil, 0.50, 0},
+		{"single sample p50", []time.Duration{time.Hour}, 0.50, time.Hour},
+		{"single sample p99", []time.Duration{time.Hour}, 0.99, time.Hour},
+		{"p0 takes the smallest", four, 0, 10 * time.Second},
+		{"p50 of an even count", four, 0.50, 20 * time.Second},
+		{"p90 between samples rounds up", four, 0.90, 40 * time.Second},
+		{"p50 of ten", tenMinutes, 0.50, 5 * time.Minute},
+		{"p90 of ten", tenMinutes, 0.90, 9 * time.Minute},
+		{"p99 of ten", tenMinutes, 0.99, 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentile(tt.sorted, tt.p); got != tt.want {
+				t.Fatalf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSummarizeDurations(t *testing.T) {
+	histogram := func(counts ...int) []domain.HistogramBucket {
+		buckets := make([]domain.HistogramBucket, len(cycleTimeBuckets))
+		for i, bound := range cycleTimeBuckets {
+			buckets[i] = domain.HistogramBucket{UpperBound: bound.label, Count: counts[i]}
+		}
+		return buckets
+	}
+
+	tests := []struct {
+		name      string
+		durations []time.Duration
+		want      domain.DurationSummary
+	}{
+		{
+			name:      "empty",
+			durations: nil,
+			want:      domain.DurationSummary{Histogram: histogram(0, 0, 0, 0, 0, 0)},
+		},
+		{
+			name:      "single sample",
+			durations: []time.Duration{2 * time.Hour},
+			want: domain.DurationSummary{
+				Count:      1,
+				P50Seconds: 7200,
+				P90Seconds: 7200,
+				P99Seconds: 7200,
+				Histogram:  histogram(0, 1, 0, 0, 0, 0),
+			},
+		},
+		{
+			name: "unsorted samples",
+			durations: []time.Duration{
+				10 * 24 * time.Hour,
+				30 * time.Minute,
+				4 * time.Hour,
+				time.Hour,
+				2 * 24 * time.Hour,
+			},
+			want: domain.DurationSummary{
+				Count:      5,
+				P50Seconds: 4 * 3600,
+				P90Seconds: 10 * 24 * 3600,
+				P99Seconds: 10 * 24 * 3600,
+				Histogram:  histogram(2, 1, 0, 1, 0, 1),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := append([]time.Duration(nil), tt.durations...)
+
+			got := summarizeDurations(tt.durations)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Expected %+v, got %+v", tt.want, got)
+			}
+			if !reflect.DeepEqual(tt.durations, input) {
+				t.Fatalf("Expected the input to stay unsorted, got %v", tt.durations)
+			}
+		})
+	}
+}
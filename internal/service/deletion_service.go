@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/metrics"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/tracing"
+)
+
+// DeletionService soft-deletes and restores users, teams and pull requests,
+// and archives old merged pull requests. Deleted rows are hidden from every
+// repository query but keep counting in the history they are part of.
+type DeletionService struct {
+	userRepo    UserRepository
+	teamRepo    TeamRepository
+	prRepo      PRRepository
+	txManager   TxManager
+	deactivator *BulkDeactivationService
+}
+
+func NewDeletionService(
+	userRepo UserRepository,
+	teamRepo TeamRepository,
+	prRepo PRRepository,
+	txManager TxManager,
+	deactivator *BulkDeactivationService,
+) *DeletionService {
+	return &DeletionService{
+		userRepo:    userRepo,
+		teamRepo:    teamRepo,
+		prRepo:      prRepo,
+		txManager:   txManager,
+		deactivator: deactivator,
+	}
+}
+
+// DeleteUser deactivates the user, moves their open reviews to other active
+// team members like a bulk deactivation does, and soft-deletes them, all in
+// one transaction. The user comes back inactive when restored.
+func (s *DeletionService) DeleteUser(ctx context.Context, userID string) (_ *domain.BulkDeactivationResult, err error) {
+	ctx, span := tracing.StartSpan(ctx, "DeletionService.DeleteUser", attribute.String("user.id", userID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var result *domain.BulkDeactivationResult
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		result, err = s.deactivator.deactivate(ctx, user.TeamName, []string{userID}, false)
+		if err != nil {
+			return err
+		}
+
+		return s.userRepo.Delete(ctx, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	recordReassignments(result)
+
+	return result, nil
+}
+
+// RestoreUser undoes DeleteUser. Reviews moved away on deletion stay with
+// their new reviewers.
+func (s *DeletionService) RestoreUser(ctx context.Context, userID string) (_ *domain.User, err error) {
+	ctx, span := tracing.StartSpan(ctx, "DeletionService.RestoreUser", attribute.String("user.id", userID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if err := s.userRepo.Restore(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.userRepo.GetByID(ctx, userID)
+}
+
+// DeleteTeam deactivates every member, tries to reassign their open reviews
+// and soft-deletes the team with its members in one transaction.
+func (s *DeletionService) DeleteTeam(ctx context.Context, teamName string) (_ *domain.BulkDeactivationResult, err error) {
+	ctx, span := tracing.StartSpan(ctx, "DeletionService.DeleteTeam", attribute.String("team.name", teamName))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var result *domain.BulkDeactivationResult
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = s.deactivator.deactivate(ctx, teamName, nil, false)
+		if err != nil {
+			return err
+		}
+
+		return s.teamRepo.Delete(ctx, teamName)
+	})
+	if err != nil {
+		return nil, err
+	}
+	recordReassignments(result)
+
+	return result, nil
+}
+
+// RestoreTeam undoes DeleteTeam, bringing back the members deleted with the
+// team. Members stay inactive.
+func (s *DeletionService) RestoreTeam(ctx context.Context, teamName string) (_ *domain.Team, err error) {
+	ctx, span := tracing.StartSpan(ctx, "DeletionService.RestoreTeam", attribute.String("team.name", teamName))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if err := s.teamRepo.Restore(ctx, teamName); err != nil {
+		return nil, err
+	}
+
+	return s.teamRepo.GetByName(ctx, teamName)
+}
+
+func (s *DeletionService) DeletePR(ctx context.Context, prID string) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "DeletionService.DeletePR", attribute.String("pr.id", prID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	return s.prRepo.Delete(ctx, prID)
+}
+
+func (s *DeletionService) RestorePR(ctx context.Context, prID string) (_ *domain.PullRequest, err error) {
+	ctx, span := tracing.StartSpan(ctx, "DeletionService.RestorePR", attribute.String("pr.id", prID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if err := s.prRepo.Restore(ctx, prID); err != nil {
+		return nil, err
+	}
+
+	return s.prRepo.GetByID(ctx, prID)
+}
+
+// ArchiveMergedPRs moves pull requests merged more than olderThan ago to the
+// archive tables. Archived pull requests are no longer returned by the API
+// but still count in the statistics.
+func (s *DeletionService) ArchiveMergedPRs(ctx context.Context, olderThan time.Duration) (_ int, err error) {
+	ctx, span := tracing.StartSpan(ctx, "DeletionService.ArchiveMergedPRs")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	archived, err := s.prRepo.ArchiveMerged(ctx, time.Now().Add(-olderThan))
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive merged pull requests: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("prs.archived", archived))
+	metrics.ArchivedPRsTotal.Add(float64(archived))
+
+	return archived, nil
+}
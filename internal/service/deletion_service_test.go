@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
+)
+
+func newTestDeletionService(s *testStore) *DeletionService {
+	return NewDeletionService(s.users, s.teams, s.prs, s.tx, newTestBulkService(s))
+}
+
+func TestDeleteUserReassignsReviews(t *testing.T) {
+	s := newTestStore(t)
+	s.addTeam("backend", "alice", "bob", "carol", "dave")
+	s.addPR("pr-1", "alice", "bob", "carol")
+
+	svc := newTestDeletionService(s)
+
+	result, err := svc.DeleteUser(context.Background(), "bob")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if s.tx.calls != 1 {
+		t.Fatalf("Expected the operation to run in one transaction, got %d", s.tx.calls)
+	}
+
+	want := domain.ReassignedPR{PullRequestID: "pr-1", OldReviewerID: "bob", NewReviewerID: "dave"}
+	if len(result.ReassignedPRs) != 1 || result.ReassignedPRs[0] != want {
+		t.Fatalf("Expected %v, got %v", want, result.ReassignedPRs)
+	}
+
+	if s.hasUser("bob") {
+		t.Fatal("Expected bob to be deleted")
+	}
+	if err := s.users.Restore(context.Background(), "bob"); err != nil {
+		t.Fatalf("Failed to restore bob: %v", err)
+	}
+	if s.user("bob").IsActive {
+		t.Fatal("Expected bob to be deactivated before deletion")
+	}
+}
+
+func TestDeleteUserRollsBackOnFailure(t *testing.T) {
+	s := newTestStore(t)
+	s.addTeam("backend", "alice", "bob", "carol", "dave")
+	s.addPR("pr-1", "alice", "bob", "carol")
+	s.prs.errs["pr-1"] = stderrors.New("connection reset")
+
+	svc := newTestDeletionService(s)
+
+	if _, err := svc.DeleteUser(context.Background(), "bob"); err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	if !s.hasUser("bob") || !s.user("bob").IsActive {
+		t.Fatal("Expected bob to stay active and not deleted after the rollback")
+	}
+}
+
+func TestRestoreUser(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+	s.addTeam("backend", "alice", "bob")
+
+	svc := newTestDeletionService(s)
+
+	if _, err := svc.DeleteUser(ctx, "bob"); err != nil {
+		t.Fatalf("Failed to delete user: %v", err)
+	}
+
+	user, err := svc.RestoreUser(ctx, "bob")
+	if err != nil {
+		t.Fatalf("Failed to restore user: %v", err)
+	}
+	if user.IsActive {
+		t.Fatal("Expected the restored user to stay inactive")
+	}
+
+	if _, err := svc.DeleteTeam(ctx, "backend"); err != nil {
+		t.Fatalf("Failed to delete team: %v", err)
+	}
+
+	_, err = svc.RestoreUser(ctx, "bob")
+	var appErr *errors.AppError
+	if !stderrors.As(err, &appErr) || appErr.Code != errors.ErrCodeNotFound {
+		t.Fatalf("Expected NOT_FOUND for a user of a deleted team, got %v", err)
+	}
+}
+
+func TestDeleteAndRestoreTeam(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+	s.addTeam("backend", "alice", "bob")
+
+	svc := newTestDeletionService(s)
+
+	result, err := svc.DeleteTeam(ctx, "backend")
+	if err != nil {
+		t.Fatalf("Failed to delete team: %v", err)
+	}
+	if len(result.DeactivatedUsers) != 2 {
+		t.Fatalf("Expected both members to be deactivated, got %v", result.DeactivatedUsers)
+	}
+
+	if _, err := svc.DeleteTeam(ctx, "backend"); err == nil {
+		t.Fatal("Expected deleting a deleted team to fail")
+	}
+
+	team, err := svc.RestoreTeam(ctx, "backend")
+	if err != nil {
+		t.Fatalf("Failed to restore team: %v", err)
+	}
+	if len(team.Members) != 2 {
+		t.Fatalf("Expected the members to come back, got %d", len(team.Members))
+	}
+	for _, member := range team.Members {
+		if member.IsActive {
+			t.Fatalf("Expected %s to stay inactive", member.UserID)
+		}
+	}
+}
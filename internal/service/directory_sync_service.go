@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/metrics"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/tracing"
+)
+
+type DirectorySource interface {
+	// Groups lists the teams in the directory with their members.
+	Groups(ctx context.Context) ([]domain.DirectoryGroup, error)
+}
+
+// DirectorySyncService mirrors team membership from the company directory.
+type DirectorySyncService struct {
+	source DirectorySource
+	roster *RosterService
+}
+
+func NewDirectorySyncService(source DirectorySource, roster *RosterService) *DirectorySyncService {
+	return &DirectorySyncService{
+		source: source,
+		roster: roster,
+	}
+}
+
+// Sync imports the directory groups the way a roster import does: each group
+// is a team and everyone listed in it is an active member. Active members of
+// a group's team missing from the group are deactivated and their open
+// reviews reassigned; teams without a group are left alone.
+func (s *DirectorySyncService) Sync(ctx context.Context) (_ *domain.RosterImportResult, err error) {
+	ctx, span := tracing.StartSpan(ctx, "DirectorySyncService.Sync")
+	defer func() {
+		tracing.EndSpan(span, err)
+		if err != nil {
+			metrics.DirectorySyncsTotal.WithLabelValues("error").Inc()
+			return
+		}
+		metrics.DirectorySyncsTotal.WithLabelValues("success").Inc()
+		metrics.DirectoryLastSyncTimestamp.Set(float64(time.Now().Unix()))
+	}()
+
+	groups, err := s.source.Groups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("directory returned no groups")
+	}
+
+	teamNames := make([]string, 0, len(groups))
+	var entries []domain.RosterEntry
+	for _, group := range groups {
+		teamNames = append(teamNames, group.TeamName)
+		for _, member := range group.Members {
+			entries = append(entries, domain.RosterEntry{
+				TeamName: group.TeamName,
+				UserID:   member.UserID,
+				Username: member.Username,
+				IsActive: true,
+			})
+		}
+	}
+
+	span.SetAttributes(
+		attribute.Int("directory.groups", len(groups)),
+		attribute.Int("directory.members", len(entries)),
+	)
+
+	if err := validateRoster(entries); err != nil {
+		return nil, err
+	}
+
+	return s.roster.importRoster(ctx, teamNames, entries, false)
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+)
+
+type fakeDirectory struct {
+	groups []domain.DirectoryGroup
+	err    error
+}
+
+func (d fakeDirectory) Groups(context.Context) ([]domain.DirectoryGroup, error) {
+	return d.groups, d.err
+}
+
+func TestDirectorySyncMirrorsGroups(t *testing.T) {
+	s := newRosterStore(t)
+	directory := fakeDirectory{groups: []domain.DirectoryGroup{
+		{TeamName: "backend", Members: []domain.DirectoryMember{
+			{UserID: "alice", Username: "alice"},
+			{UserID: "bob", Username: "bob"},
+			{UserID: "erin", Username: "erin"},
+		}},
+		// An empty group drops everyone from the team.
+		{TeamName: "frontend"},
+	}}
+	svc := NewDirectorySyncService(directory, newTestRosterService(s))
+
+	result, err := svc.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.DryRun {
+		t.Fatal("Expected the sync to be applied")
+	}
+	if !s.hasUser("erin") {
+		t.Fatal("Expected erin to be created")
+	}
+	if erin := s.user("erin"); erin.TeamName != "backend" || !erin.IsActive {
+		t.Fatalf("Expected erin to join backend, got %+v", erin)
+	}
+	for _, userID := range []string{"carol", "dave", "gina"} {
+		if s.user(userID).IsActive {
+			t.Fatalf("Expected %s to be deactivated", userID)
+		}
+	}
+
+	reassigned := result.Deactivation.ReassignedPRs
+	if len(reassigned) != 1 || reassigned[0].OldReviewerID != "carol" || reassigned[0].NewReviewerID != "erin" {
+		t.Fatalf("Expected erin to replace carol, got %v", reassigned)
+	}
+}
+
+func TestDirectorySyncRejectsEmptyDirectory(t *testing.T) {
+	s := newRosterStore(t)
+	svc := NewDirectorySyncService(fakeDirectory{}, newTestRosterService(s))
+
+	if _, err := svc.Sync(context.Background()); err == nil {
+		t.Fatal("Expected an error for a directory without groups")
+	}
+	if !s.user("carol").IsActive {
+		t.Fatal("Expected nothing to change")
+	}
+}
+
+func TestDirectorySyncPropagatesSourceErrors(t *testing.T) {
+	sourceErr := stderrors.New("connection refused")
+	svc := NewDirectorySyncService(fakeDirectory{err: sourceErr}, newTestRosterService(newRosterStore(t)))
+
+	if _, err := svc.Sync(context.Background()); !stderrors.Is(err, sourceErr) {
+		t.Fatalf("Expected %v, got %v", sourceErr, err)
+	}
+}
@@ -2,18 +2,27 @@ package service
 
 import (
 	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
 	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/metrics"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/tracing"
 )
 
 type PRRepository interface {
 	Create(ctx context.Context, pr *domain.PullRequest) error
 	GetByID(ctx context.Context, prID string) (*domain.PullRequest, error)
 	Update(ctx context.Context, pr *domain.PullRequest) error
-	ReplaceReviewer(ctx context.Context, prID, oldReviewerID, newReviewerID string) error
+	ReplaceReviewer(ctx context.Context, prID string, expectedVersion int, oldReviewerID, newReviewerID string) error
 	GetByReviewer(ctx context.Context, reviewerID string) ([]*domain.PullRequest, error)
 	Exists(ctx context.Context, prID string) (bool, error)
+	GetOpenPRsWithReviewers(ctx context.Context, reviewerIDs []string) ([]domain.PRReviewerInfo, error)
+	Delete(ctx context.Context, prID string) error
+	Restore(ctx context.Context, prID string) error
+	ArchiveMerged(ctx context.Context, mergedBefore time.Time) (int, error)
 }
 
 type PRUserRepository interface {
@@ -35,7 +44,13 @@ func NewPRService(prRepo PRRepository, userRepo PRUserRepository, reviewerAssg *
 	}
 }
 
-func (s *PRService) CreatePR(ctx context.Context, prID, prName, authorID string) (*domain.PullRequest, error) {
+func (s *PRService) CreatePR(ctx context.Context, prID, prName, authorID string) (_ *domain.PullRequest, err error) {
+	ctx, span := tracing.StartSpan(ctx, "PRService.CreatePR",
+		attribute.String("pr.id", prID),
+		attribute.String("pr.author_id", authorID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
 	exists, err := s.prRepo.Exists(ctx, prID)
 	if err != nil {
 		return nil, err
@@ -57,22 +72,37 @@ func (s *PRService) CreatePR(ctx context.Context, prID, prName, authorID string)
 
 	pr := domain.NewPullRequest(prID, prName, authorID)
 
-	reviewerIDs := s.reviewerAssg.SelectReviewers(candidates, 2)
+	reviewerIDs := s.reviewerAssg.SelectReviewers(candidates, s.reviewerAssg.ReviewersPerPR())
 	pr.AssignReviewers(reviewerIDs)
 
 	if err := s.prRepo.Create(ctx, pr); err != nil {
 		return nil, err
 	}
 
+	metrics.ReviewerAssignmentsTotal.Add(float64(len(reviewerIDs)))
+
 	return pr, nil
 }
 
-func (s *PRService) MergePR(ctx context.Context, prID string) (*domain.PullRequest, error) {
+// MergePR marks the pull request as merged. A non-zero expectedVersion makes
+// the call fail with CONFLICT unless the pull request is at that version.
+func (s *PRService) MergePR(ctx context.Context, prID string, expectedVersion int) (_ *domain.PullRequest, err error) {
+	ctx, span := tracing.StartSpan(ctx, "PRService.MergePR", attribute.String("pr.id", prID))
+	defer func() { tracing.EndSpan(span, err) }()
+
 	pr, err := s.prRepo.GetByID(ctx, prID)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := checkVersion(pr, expectedVersion); err != nil {
+		return nil, err
+	}
+
+	if pr.IsMerged() {
+		return pr, nil
+	}
+
 	pr.Merge()
 
 	if err := s.prRepo.Update(ctx, pr); err != nil {
@@ -82,12 +112,29 @@ func (s *PRService) MergePR(ctx context.Context, prID string) (*domain.PullReque
 	return pr, nil
 }
 
-func (s *PRService) ReassignReviewer(ctx context.Context, prID, oldReviewerID string) (*domain.PullRequest, string, error) {
+// ReassignReviewer replaces oldReviewerID with a random active member of their
+// team. A non-zero expectedVersion makes the call fail with CONFLICT unless the
+// pull request is at that version.
+func (s *PRService) ReassignReviewer(
+	ctx context.Context,
+	prID, oldReviewerID string,
+	expectedVersion int,
+) (_ *domain.PullRequest, _ string, err error) {
+	ctx, span := tracing.StartSpan(ctx, "PRService.ReassignReviewer",
+		attribute.String("pr.id", prID),
+		attribute.String("pr.old_reviewer_id", oldReviewerID),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
 	pr, err := s.prRepo.GetByID(ctx, prID)
 	if err != nil {
 		return nil, "", err
 	}
 
+	if err := checkVersion(pr, expectedVersion); err != nil {
+		return nil, "", err
+	}
+
 	if pr.IsMerged() {
 		return nil, "", errors.ErrPRMerged(prID)
 	}
@@ -106,15 +153,20 @@ func (s *PRService) ReassignReviewer(ctx context.Context, prID, oldReviewerID st
 		return nil, "", err
 	}
 
+	candidates = excludeUsers(candidates, append([]string{pr.AuthorID}, pr.AssignedReviewers...))
+
 	newReviewerID, found := s.reviewerAssg.SelectRandomReviewer(candidates)
 	if !found {
+		metrics.NoCandidateTotal.WithLabelValues(metrics.SourceManual).Inc()
 		return nil, "", errors.ErrNoCandidate(oldReviewer.TeamName)
 	}
 
-	if err := s.prRepo.ReplaceReviewer(ctx, prID, oldReviewerID, newReviewerID); err != nil {
+	if err := s.prRepo.ReplaceReviewer(ctx, prID, pr.Version, oldReviewerID, newReviewerID); err != nil {
 		return nil, "", err
 	}
 
+	metrics.ReviewerReassignmentsTotal.WithLabelValues(metrics.SourceManual).Inc()
+
 	pr, err = s.prRepo.GetByID(ctx, prID)
 	if err != nil {
 		return nil, "", err
@@ -123,10 +175,40 @@ func (s *PRService) ReassignReviewer(ctx context.Context, prID, oldReviewerID st
 	return pr, newReviewerID, nil
 }
 
-func (s *PRService) GetPRsByReviewer(ctx context.Context, reviewerID string) ([]*domain.PullRequest, error) {
+func (s *PRService) GetPRsByReviewer(ctx context.Context, reviewerID string) (_ []*domain.PullRequest, err error) {
+	ctx, span := tracing.StartSpan(ctx, "PRService.GetPRsByReviewer", attribute.String("user.id", reviewerID))
+	defer func() { tracing.EndSpan(span, err) }()
+
 	return s.prRepo.GetByReviewer(ctx, reviewerID)
 }
 
-func (s *PRService) GetByID(ctx context.Context, prID string) (*domain.PullRequest, error) {
+func (s *PRService) GetByID(ctx context.Context, prID string) (_ *domain.PullRequest, err error) {
+	ctx, span := tracing.StartSpan(ctx, "PRService.GetByID", attribute.String("pr.id", prID))
+	defer func() { tracing.EndSpan(span, err) }()
+
 	return s.prRepo.GetByID(ctx, prID)
 }
+
+func checkVersion(pr *domain.PullRequest, expectedVersion int) error {
+	if expectedVersion != 0 && pr.Version != expectedVersion {
+		return errors.ErrVersionMismatch(pr.PullRequestID, expectedVersion, pr.Version)
+	}
+	return nil
+}
+
+func excludeUsers(users []*domain.User, excludeIDs []string) []*domain.User {
+	result := make([]*domain.User, 0, len(users))
+	for _, user := range users {
+		excluded := false
+		for _, id := range excludeIDs {
+			if user.UserID == id {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			result = append(result, user)
+		}
+	}
+	return result
+}
@@ -2,15 +2,29 @@ package service
 
 import (
 	"math/rand"
+	"sync/atomic"
 
 	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
 )
 
 type ReviewerAssigner struct {
+	reviewersPerPR atomic.Int32
 }
 
 func NewReviewerAssigner() *ReviewerAssigner {
-	return &ReviewerAssigner{}
+	ra := &ReviewerAssigner{}
+	ra.reviewersPerPR.Store(domain.MaxReviewers)
+	return ra
+}
+
+// SetReviewersPerPR changes how many reviewers new pull requests get. It is
+// safe to call while requests are being served.
+func (ra *ReviewerAssigner) SetReviewersPerPR(count int) {
+	ra.reviewersPerPR.Store(int32(minInt(count, domain.MaxReviewers))) //nolint:gosec
+}
+
+func (ra *ReviewerAssigner) ReviewersPerPR() int {
+	return int(ra.reviewersPerPR.Load())
 }
 
 func (ra *ReviewerAssigner) SelectReviewers(candidates []*domain.User, maxCount int) []string {
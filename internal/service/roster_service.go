@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/tracing"
+)
+
+// RosterService syncs teams with rosters exported from an HR system.
+type RosterService struct {
+	userRepo    UserRepository
+	teamRepo    TeamRepository
+	txManager   TxManager
+	deactivator *BulkDeactivationService
+}
+
+func NewRosterService(
+	userRepo UserRepository,
+	teamRepo TeamRepository,
+	txManager TxManager,
+	deactivator *BulkDeactivationService,
+) *RosterService {
+	return &RosterService{
+		userRepo:    userRepo,
+		teamRepo:    teamRepo,
+		txManager:   txManager,
+		deactivator: deactivator,
+	}
+}
+
+// ImportRoster compares the roster with the teams it names and, unless
+// dryRun is set, applies the difference in one transaction. Users who are
+// deactivated have their open reviews moved to other active team members the
+// way a bulk deactivation does; reviews without a replacement are reported as
+// skipped. Rosters naming a deleted team or giving a user a username held by
+// another user are rejected as invalid, dry run or not.
+func (s *RosterService) ImportRoster(
+	ctx context.Context,
+	entries []domain.RosterEntry,
+	dryRun bool,
+) (_ *domain.RosterImportResult, err error) {
+	ctx, span := tracing.StartSpan(ctx, "RosterService.ImportRoster",
+		attribute.Int("roster.entries", len(entries)),
+		attribute.Bool("dry_run", dryRun),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if len(entries) == 0 {
+		return nil, errors.ErrInvalidRoster("roster is empty")
+	}
+	if err := validateRoster(entries); err != nil {
+		return nil, err
+	}
+
+	result, err := s.importRoster(ctx, nil, entries, dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.Int("roster.new_users", len(result.Diff.NewUsers)),
+		attribute.Int("roster.moved_users", len(result.Diff.MovedUsers)),
+		attribute.Int("roster.deactivations", len(result.Diff.Deactivations)),
+	)
+
+	return result, nil
+}
+
+// importRoster compares the entries against teamNames and the teams the
+// entries name, so teams listed in teamNames without entries lose all
+// their active members.
+func (s *RosterService) importRoster(
+	ctx context.Context,
+	teamNames []string,
+	entries []domain.RosterEntry,
+	dryRun bool,
+) (*domain.RosterImportResult, error) {
+	result := &domain.RosterImportResult{DryRun: dryRun}
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		diff, writes, err := s.diff(ctx, teamNames, entries)
+		if err != nil {
+			return err
+		}
+		result.Diff = diff
+
+		if dryRun {
+			return nil
+		}
+
+		result.Deactivation, err = s.apply(ctx, diff, writes)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	recordReassignments(result.Deactivation)
+
+	return result, nil
+}
+
+func validateRoster(entries []domain.RosterEntry) error {
+	userIDs := make(map[string]bool, len(entries))
+	usernames := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if entry.TeamName == "" || entry.UserID == "" || entry.Username == "" {
+			return errors.ErrInvalidRoster("team_name, user_id and username are required")
+		}
+		if userIDs[entry.UserID] {
+			return errors.ErrInvalidRoster(fmt.Sprintf("user %s is listed more than once", entry.UserID))
+		}
+		if usernames[entry.Username] {
+			return errors.ErrInvalidRoster(fmt.Sprintf("username %s is listed more than once", entry.Username))
+		}
+		userIDs[entry.UserID] = true
+		usernames[entry.Username] = true
+	}
+
+	return nil
+}
+
+// diff returns the changes the roster makes together with the users to
+// write. Users about to be deactivated are written with their current active
+// flag so that apply deactivates them through the bulk deactivation path.
+func (s *RosterService) diff(
+	ctx context.Context,
+	teamNames []string,
+	entries []domain.RosterEntry,
+) (*domain.RosterDiff, []*domain.User, error) {
+	diff := &domain.RosterDiff{
+		NewTeams:      []string{},
+		NewUsers:      []*domain.User{},
+		MovedUsers:    []domain.RosterMove{},
+		UpdatedUsers:  []*domain.User{},
+		Deactivations: []domain.RosterDeactivation{},
+	}
+
+	inRoster := make(map[string]bool, len(entries))
+	userIDs := make([]string, 0, len(entries))
+	usernames := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		inRoster[entry.UserID] = true
+		userIDs = append(userIDs, entry.UserID)
+		usernames = append(usernames, entry.Username)
+	}
+
+	owners, err := s.userRepo.GetUsernameOwners(ctx, usernames)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get username owners: %w", err)
+	}
+	for _, entry := range entries {
+		if owner, ok := owners[entry.Username]; ok && owner != entry.UserID {
+			return nil, nil, errors.ErrInvalidRoster(
+				fmt.Sprintf("username %s of user %s belongs to user %s", entry.Username, entry.UserID, owner))
+		}
+	}
+
+	teams := append([]string{}, teamNames...)
+	for _, entry := range entries {
+		teams = append(teams, entry.TeamName)
+	}
+
+	seenTeams := make(map[string]bool)
+	for _, teamName := range teams {
+		if seenTeams[teamName] {
+			continue
+		}
+		seenTeams[teamName] = true
+
+		exists, err := s.teamRepo.Exists(ctx, teamName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to check team: %w", err)
+		}
+		if !exists {
+			diff.NewTeams = append(diff.NewTeams, teamName)
+			continue
+		}
+
+		team, err := s.teamRepo.GetByName(ctx, teamName)
+		var appErr *errors.AppError
+		if stderrors.As(err, &appErr) && appErr.Code == errors.ErrCodeNotFound {
+			// Exists counts deleted teams, whose names cannot be taken again.
+			return nil, nil, errors.ErrInvalidRoster(fmt.Sprintf("team %s is deleted, restore it first", teamName))
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, member := range team.Members {
+			if !inRoster[member.UserID] && member.IsActive {
+				diff.Deactivations = append(diff.Deactivations, domain.RosterDeactivation{
+					UserID:   member.UserID,
+					TeamName: teamName,
+					Reason:   domain.RosterReasonRemoved,
+				})
+			}
+		}
+	}
+
+	existingUsers, err := s.userRepo.GetUsersByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	existing := make(map[string]*domain.User, len(existingUsers))
+	for _, user := range existingUsers {
+		existing[user.UserID] = user
+	}
+
+	writes := make([]*domain.User, 0, len(entries))
+	for _, entry := range entries {
+		user := domain.NewUser(entry.UserID, entry.Username, entry.TeamName, entry.IsActive)
+
+		current, ok := existing[entry.UserID]
+		if !ok {
+			diff.NewUsers = append(diff.NewUsers, user)
+			writes = append(writes, user)
+			continue
+		}
+
+		deactivated := current.IsActive && !entry.IsActive
+		if deactivated {
+			user.IsActive = true
+			diff.Deactivations = append(diff.Deactivations, domain.RosterDeactivation{
+				UserID:   entry.UserID,
+				TeamName: entry.TeamName,
+				Reason:   domain.RosterReasonInactive,
+			})
+		}
+
+		switch {
+		case current.TeamName != entry.TeamName:
+			diff.MovedUsers = append(diff.MovedUsers, domain.RosterMove{
+				UserID:   entry.UserID,
+				FromTeam: current.TeamName,
+				ToTeam:   entry.TeamName,
+			})
+		case current.Username != entry.Username || (!current.IsActive && entry.IsActive):
+			diff.UpdatedUsers = append(diff.UpdatedUsers, user)
+		default:
+			continue
+		}
+
+		writes = append(writes, user)
+	}
+
+	return diff, writes, nil
+}
+
+// apply writes the diff. Deactivations run per team after all users are in
+// place, so users joining a team can take over reviews of those leaving it.
+func (s *RosterService) apply(
+	ctx context.Context,
+	diff *domain.RosterDiff,
+	writes []*domain.User,
+) (*domain.BulkDeactivationResult, error) {
+	for _, teamName := range diff.NewTeams {
+		if err := s.teamRepo.Create(ctx, domain.NewTeam(teamName, nil)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.userRepo.Upsert(ctx, writes); err != nil {
+		return nil, err
+	}
+
+	result := &domain.BulkDeactivationResult{
+		DeactivatedUsers: []string{},
+		ReassignedPRs:    []domain.ReassignedPR{},
+		SkippedPRs:       []domain.SkippedPR{},
+	}
+
+	var teams []string
+	byTeam := make(map[string][]string)
+	for _, d := range diff.Deactivations {
+		if _, ok := byTeam[d.TeamName]; !ok {
+			teams = append(teams, d.TeamName)
+		}
+		byTeam[d.TeamName] = append(byTeam[d.TeamName], d.UserID)
+	}
+
+	for _, teamName := range teams {
+		teamResult, err := s.deactivator.deactivate(ctx, teamName, byTeam[teamName], false)
+		if err != nil {
+			return nil, err
+		}
+		result.DeactivatedUsers = append(result.DeactivatedUsers, teamResult.DeactivatedUsers...)
+		result.ReassignedPRs = append(result.ReassignedPRs, teamResult.ReassignedPRs...)
+		result.SkippedPRs = append(result.SkippedPRs, teamResult.SkippedPRs...)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
+)
+
+func newTestRosterService(s *testStore) *RosterService {
+	return NewRosterService(s.users, s.teams, s.tx, newTestBulkService(s))
+}
+
+// newTestRoster keeps alice, renames bob, adds erin, moves dave over from
+// frontend, drops carol and creates the platform team.
+func newTestRoster() []domain.RosterEntry {
+	return []domain.RosterEntry{
+		{TeamName: "backend", UserID: "alice", Username: "alice", IsActive: true},
+		{TeamName: "backend", UserID: "bob", Username: "Bob B.", IsActive: true},
+		{TeamName: "backend", UserID: "erin", Username: "erin", IsActive: true},
+		{TeamName: "backend", UserID: "dave", Username: "dave", IsActive: true},
+		{TeamName: "platform", UserID: "frank", Username: "frank", IsActive: true},
+	}
+}
+
+func newRosterStore(t *testing.T) *testStore {
+	s := newTestStore(t)
+	s.addTeam("backend", "alice", "bob", "carol")
+	s.addTeam("frontend", "dave", "gina")
+	s.addPR("pr-1", "alice", "bob", "carol")
+	return s
+}
+
+func TestRosterDryRunLeavesDataUntouched(t *testing.T) {
+	s := newRosterStore(t)
+	svc := newTestRosterService(s)
+
+	result, err := svc.ImportRoster(context.Background(), newTestRoster(), true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	diff := result.Diff
+	if !result.DryRun || result.Deactivation != nil {
+		t.Fatalf("Expected a dry run without deactivation results, got %+v", result)
+	}
+	if len(diff.NewTeams) != 1 || diff.NewTeams[0] != "platform" {
+		t.Fatalf("Expected platform to be new, got %v", diff.NewTeams)
+	}
+	if got := userIDsOf(diff.NewUsers); len(got) != 2 || got[0] != "erin" || got[1] != "frank" {
+		t.Fatalf("Expected erin and frank to be new, got %v", got)
+	}
+	wantMove := domain.RosterMove{UserID: "dave", FromTeam: "frontend", ToTeam: "backend"}
+	if len(diff.MovedUsers) != 1 || diff.MovedUsers[0] != wantMove {
+		t.Fatalf("Expected %v, got %v", wantMove, diff.MovedUsers)
+	}
+	if got := userIDsOf(diff.UpdatedUsers); len(got) != 1 || got[0] != "bob" {
+		t.Fatalf("Expected bob to be updated, got %v", got)
+	}
+	wantDeactivation := domain.RosterDeactivation{UserID: "carol", TeamName: "backend", Reason: domain.RosterReasonRemoved}
+	if len(diff.Deactivations) != 1 || diff.Deactivations[0] != wantDeactivation {
+		t.Fatalf("Expected %v, got %v", wantDeactivation, diff.Deactivations)
+	}
+
+	if s.hasTeam("platform") || s.user("dave").TeamName != "frontend" || !s.user("carol").IsActive {
+		t.Fatal("Expected a dry run to change nothing")
+	}
+	if s.hasUser("erin") {
+		t.Fatal("Expected a dry run not to create users")
+	}
+}
+
+func TestRosterImportAppliesDiff(t *testing.T) {
+	s := newRosterStore(t)
+	svc := newTestRosterService(s)
+
+	result, err := svc.ImportRoster(context.Background(), newTestRoster(), false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !s.hasTeam("platform") || s.user("frank").TeamName != "platform" {
+		t.Fatal("Expected platform to be created with frank")
+	}
+	if dave, bob := s.user("dave"), s.user("bob"); dave.TeamName != "backend" || bob.Username != "Bob B." {
+		t.Fatalf("Expected dave to move and bob to be renamed, got %+v, %+v", dave, bob)
+	}
+	if s.user("carol").IsActive {
+		t.Fatal("Expected carol to be deactivated")
+	}
+	if gina := s.user("gina"); !gina.IsActive || gina.TeamName != "frontend" {
+		t.Fatal("Expected members of teams outside the roster to be left alone")
+	}
+
+	// carol's review goes to someone who joined backend with this roster.
+	reassigned := result.Deactivation.ReassignedPRs
+	if len(reassigned) != 1 || reassigned[0].OldReviewerID != "carol" {
+		t.Fatalf("Expected carol's review to be reassigned, got %v", reassigned)
+	}
+	if newReviewer := reassigned[0].NewReviewerID; newReviewer != "dave" && newReviewer != "erin" {
+		t.Fatalf("Expected dave or erin to take over, got %s", newReviewer)
+	}
+}
+
+func TestRosterImportDeactivatesInactiveRows(t *testing.T) {
+	s := newRosterStore(t)
+	svc := newTestRosterService(s)
+
+	roster := []domain.RosterEntry{
+		{TeamName: "backend", UserID: "alice", Username: "alice", IsActive: true},
+		{TeamName: "backend", UserID: "bob", Username: "bob", IsActive: false},
+		{TeamName: "backend", UserID: "carol", Username: "carol", IsActive: true},
+		{TeamName: "backend", UserID: "erin", Username: "erin", IsActive: true},
+	}
+
+	result, err := svc.ImportRoster(context.Background(), roster, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := domain.RosterDeactivation{UserID: "bob", TeamName: "backend", Reason: domain.RosterReasonInactive}
+	if len(result.Diff.Deactivations) != 1 || result.Diff.Deactivations[0] != want {
+		t.Fatalf("Expected %v, got %v", want, result.Diff.Deactivations)
+	}
+
+	reassigned := result.Deactivation.ReassignedPRs
+	if len(reassigned) != 1 || reassigned[0].OldReviewerID != "bob" || reassigned[0].NewReviewerID != "erin" {
+		t.Fatalf("Expected erin to replace bob, got %v", reassigned)
+	}
+	if s.user("bob").IsActive {
+		t.Fatal("Expected bob to be inactive")
+	}
+}
+
+func TestRosterValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		roster []domain.RosterEntry
+	}{
+		{"empty", nil},
+		{"missing username", []domain.RosterEntry{{TeamName: "backend", UserID: "alice"}}},
+		{"duplicate user", []domain.RosterEntry{
+			{TeamName: "backend", UserID: "alice", Username: "alice"},
+			{TeamName: "frontend", UserID: "alice", Username: "alice2"},
+		}},
+		{"duplicate username", []domain.RosterEntry{
+			{TeamName: "backend", UserID: "alice", Username: "alice"},
+			{TeamName: "backend", UserID: "bob", Username: "alice"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newTestRosterService(newRosterStore(t))
+
+			_, err := svc.ImportRoster(context.Background(), tt.roster, false)
+			expectErrorCode(t, err, errors.ErrCodeInvalidRoster)
+		})
+	}
+}
+
+func TestRosterRejectsConflictsWithStoredData(t *testing.T) {
+	tests := []struct {
+		name    string
+		roster  []domain.RosterEntry
+		message string
+	}{
+		{"deleted team", []domain.RosterEntry{
+			{TeamName: "frontend", UserID: "erin", Username: "erin", IsActive: true},
+		}, "team frontend is deleted"},
+		{"username of another user", []domain.RosterEntry{
+			{TeamName: "backend", UserID: "bob", Username: "alice", IsActive: true},
+		}, "belongs to user alice"},
+		{"username of a deleted user", []domain.RosterEntry{
+			{TeamName: "backend", UserID: "erin", Username: "gina", IsActive: true},
+		}, "belongs to user gina"},
+	}
+
+	for _, tt := range tests {
+		for _, dryRun := range []bool{true, false} {
+			t.Run(fmt.Sprintf("%s/dry_run=%t", tt.name, dryRun), func(t *testing.T) {
+				s := newRosterStore(t)
+				if err := s.teams.Delete(context.Background(), "frontend"); err != nil {
+					t.Fatalf("Failed to delete team: %v", err)
+				}
+				svc := newTestRosterService(s)
+
+				_, err := svc.ImportRoster(context.Background(), tt.roster, dryRun)
+				expectErrorCode(t, err, errors.ErrCodeInvalidRoster)
+				if !strings.Contains(err.Error(), tt.message) {
+					t.Fatalf("Expected %q in the error, got %v", tt.message, err)
+				}
+				if s.hasUser("erin") || s.user("bob").Username != "bob" {
+					t.Fatal("Expected a rejected roster to change nothing")
+				}
+			})
+		}
+	}
+}
+
+func userIDsOf(users []*domain.User) []string {
+	ids := make([]string, len(users))
+	for i, user := range users {
+		ids[i] = user.UserID
+	}
+	return ids
+}
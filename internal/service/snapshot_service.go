@@ -0,0 +1,322 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/tracing"
+)
+
+type SnapshotRepository interface {
+	// Export reads the live teams with their members and the live pull
+	// requests with their reviewers.
+	Export(ctx context.Context) (*domain.Snapshot, error)
+	// FindExisting returns which IDs of the snapshot, including the authors
+	// and reviewers of its pull requests, are already taken, and who holds
+	// the usernames of its users.
+	FindExisting(ctx context.Context, snapshot *domain.Snapshot) (*domain.ExistingRecords, error)
+	// Import upserts every record of the snapshot, restoring deleted ones and
+	// replacing the reviewers of existing pull requests.
+	Import(ctx context.Context, snapshot *domain.Snapshot) error
+}
+
+type SnapshotService struct {
+	snapshotRepo SnapshotRepository
+	txManager    TxManager
+}
+
+func NewSnapshotService(snapshotRepo SnapshotRepository, txManager TxManager) *SnapshotService {
+	return &SnapshotService{
+		snapshotRepo: snapshotRepo,
+		txManager:    txManager,
+	}
+}
+
+// Export reads the snapshot in one read-only transaction, so teams, users and
+// pull requests come from the same point in time.
+func (s *SnapshotService) Export(ctx context.Context) (_ *domain.Snapshot, err error) {
+	ctx, span := tracing.StartSpan(ctx, "SnapshotService.Export")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var snapshot *domain.Snapshot
+	err = s.txManager.WithinReadOnlyTx(ctx, func(ctx context.Context) error {
+		var err error
+		snapshot, err = s.snapshotRepo.Export(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.Version = domain.SnapshotVersion
+	snapshot.ExportedAt = time.Now()
+
+	span.SetAttributes(
+		attribute.Int("snapshot.teams", len(snapshot.Teams)),
+		attribute.Int("snapshot.pull_requests", len(snapshot.PullRequests)),
+	)
+
+	return snapshot, nil
+}
+
+// Import validates the snapshot and loads it in one transaction. Records
+// whose ID is taken are left alone with ConflictSkip, replaced with
+// ConflictOverwrite and fail the whole import with ConflictFail. Archived
+// pull requests are never replaced, and neither is a user holding the
+// username of a different user from the snapshot: such users are skipped
+// with ConflictSkip and fail the import otherwise.
+func (s *SnapshotService) Import(
+	ctx context.Context,
+	snapshot *domain.Snapshot,
+	policy domain.ConflictPolicy,
+) (_ *domain.ImportResult, err error) {
+	ctx, span := tracing.StartSpan(ctx, "SnapshotService.Import",
+		attribute.String("import.policy", string(policy)),
+		attribute.Int("snapshot.teams", len(snapshot.Teams)),
+		attribute.Int("snapshot.pull_requests", len(snapshot.PullRequests)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if !policy.IsValid() {
+		return nil, errors.ErrInvalidSnapshot("conflict policy must be skip, overwrite or fail")
+	}
+
+	fillSnapshotDefaults(snapshot, time.Now())
+	if err := validateSnapshot(snapshot); err != nil {
+		return nil, err
+	}
+
+	var result *domain.ImportResult
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		existing, err := s.snapshotRepo.FindExisting(ctx, snapshot)
+		if err != nil {
+			return err
+		}
+
+		var filtered *domain.Snapshot
+		filtered, result, err = resolveConflicts(snapshot, existing, policy)
+		if err != nil {
+			return err
+		}
+
+		if err := checkReferences(filtered, existing); err != nil {
+			return err
+		}
+
+		return s.snapshotRepo.Import(ctx, filtered)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// fillSnapshotDefaults sets the timestamps and versions a hand-written
+// snapshot may leave out.
+func fillSnapshotDefaults(snapshot *domain.Snapshot, now time.Time) {
+	for _, team := range snapshot.Teams {
+		if team.CreatedAt.IsZero() {
+			team.CreatedAt = now
+		}
+		for _, member := range team.Members {
+			member.TeamName = team.TeamName
+			if member.CreatedAt.IsZero() {
+				member.CreatedAt = now
+			}
+			if member.UpdatedAt.IsZero() {
+				member.UpdatedAt = member.CreatedAt
+			}
+		}
+	}
+
+	for _, pr := range snapshot.PullRequests {
+		if pr.CreatedAt.IsZero() {
+			pr.CreatedAt = now
+		}
+		if pr.UpdatedAt.IsZero() {
+			pr.UpdatedAt = pr.CreatedAt
+		}
+		if pr.Version == 0 {
+			pr.Version = 1
+		}
+		for i := range pr.ReviewerAssignments {
+			if pr.ReviewerAssignments[i].AssignedAt.IsZero() {
+				pr.ReviewerAssignments[i].AssignedAt = pr.CreatedAt
+			}
+		}
+		pr.AssignedReviewers = make([]string, len(pr.ReviewerAssignments))
+		for i, assignment := range pr.ReviewerAssignments {
+			pr.AssignedReviewers[i] = assignment.ReviewerID
+		}
+	}
+}
+
+func validateSnapshot(snapshot *domain.Snapshot) error {
+	if snapshot.Version != domain.SnapshotVersion {
+		return errors.ErrInvalidSnapshot(fmt.Sprintf("unsupported snapshot version %d, expected %d", snapshot.Version, domain.SnapshotVersion))
+	}
+
+	teams := make(map[string]bool, len(snapshot.Teams))
+	users := make(map[string]bool)
+	usernames := make(map[string]bool)
+	for _, team := range snapshot.Teams {
+		if team.TeamName == "" {
+			return errors.ErrInvalidSnapshot("team_name is required")
+		}
+		if teams[team.TeamName] {
+			return errors.ErrInvalidSnapshot(fmt.Sprintf("team '%s' appears more than once", team.TeamName))
+		}
+		teams[team.TeamName] = true
+
+		for _, member := range team.Members {
+			if member.UserID == "" || member.Username == "" {
+				return errors.ErrInvalidSnapshot(fmt.Sprintf("members of team '%s' need user_id and username", team.TeamName))
+			}
+			if users[member.UserID] {
+				return errors.ErrInvalidSnapshot(fmt.Sprintf("user '%s' appears more than once", member.UserID))
+			}
+			if usernames[member.Username] {
+				return errors.ErrInvalidSnapshot(fmt.Sprintf("username '%s' appears more than once", member.Username))
+			}
+			users[member.UserID] = true
+			usernames[member.Username] = true
+		}
+	}
+
+	prs := make(map[string]bool, len(snapshot.PullRequests))
+	for _, pr := range snapshot.PullRequests {
+		if pr.PullRequestID == "" || pr.PullRequestName == "" || pr.AuthorID == "" {
+			return errors.ErrInvalidSnapshot("pull requests need pull_request_id, pull_request_name and author_id")
+		}
+		if prs[pr.PullRequestID] {
+			return errors.ErrInvalidSnapshot(fmt.Sprintf("pull request '%s' appears more than once", pr.PullRequestID))
+		}
+		prs[pr.PullRequestID] = true
+
+		if !pr.Status.IsValid() {
+			return errors.ErrInvalidSnapshot(fmt.Sprintf("pull request '%s' has invalid status '%s'", pr.PullRequestID, pr.Status))
+		}
+		if pr.IsMerged() != (pr.MergedAt != nil) {
+			return errors.ErrInvalidSnapshot(fmt.Sprintf("pull request '%s' must have mergedAt exactly when it is merged", pr.PullRequestID))
+		}
+		if pr.Version < 1 {
+			return errors.ErrInvalidSnapshot(fmt.Sprintf("pull request '%s' has invalid version %d", pr.PullRequestID, pr.Version))
+		}
+		if len(pr.AssignedReviewers) > domain.MaxReviewers {
+			return errors.ErrInvalidSnapshot(fmt.Sprintf("pull request '%s' has more than %d reviewers", pr.PullRequestID, domain.MaxReviewers))
+		}
+
+		reviewers := make(map[string]bool, len(pr.AssignedReviewers))
+		for _, reviewerID := range pr.AssignedReviewers {
+			if reviewerID == pr.AuthorID || reviewers[reviewerID] {
+				return errors.ErrInvalidSnapshot(fmt.Sprintf("pull request '%s' has an invalid reviewer '%s'", pr.PullRequestID, reviewerID))
+			}
+			reviewers[reviewerID] = true
+		}
+	}
+
+	return nil
+}
+
+// checkReferences makes sure the authors and reviewers of the pull requests
+// to import are either imported with them or already exist.
+func checkReferences(snapshot *domain.Snapshot, existing *domain.ExistingRecords) error {
+	users := make(map[string]bool)
+	for _, team := range snapshot.Teams {
+		for _, member := range team.Members {
+			users[member.UserID] = true
+		}
+	}
+
+	for _, pr := range snapshot.PullRequests {
+		for _, userID := range append([]string{pr.AuthorID}, pr.AssignedReviewers...) {
+			if !users[userID] && !existing.Users[userID] {
+				return errors.ErrInvalidSnapshot(fmt.Sprintf("pull request '%s' refers to unknown user '%s'", pr.PullRequestID, userID))
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveConflicts applies the policy to the records whose ID is taken and
+// returns the records to import along with the counts to report.
+func resolveConflicts(
+	snapshot *domain.Snapshot,
+	existing *domain.ExistingRecords,
+	policy domain.ConflictPolicy,
+) (*domain.Snapshot, *domain.ImportResult, error) {
+	result := &domain.ImportResult{}
+	filtered := &domain.Snapshot{Version: snapshot.Version, ExportedAt: snapshot.ExportedAt}
+	var conflicts []string
+
+	keep := func(taken, archived bool, counts *domain.ImportCounts, what string) bool {
+		switch {
+		case !taken && !archived:
+			counts.Created++
+			return true
+		case policy == domain.ConflictFail:
+			conflicts = append(conflicts, what)
+			return false
+		case policy == domain.ConflictOverwrite && !archived:
+			counts.Updated++
+			return true
+		default:
+			counts.Skipped++
+			return false
+		}
+	}
+
+	for _, team := range snapshot.Teams {
+		teamKept := keep(existing.Teams[team.TeamName], false, &result.Teams, "team "+team.TeamName)
+
+		members := make([]*domain.User, 0, len(team.Members))
+		for _, member := range team.Members {
+			// Members of a skipped team are skipped with it.
+			if !teamKept && policy != domain.ConflictFail {
+				result.Users.Skipped++
+				continue
+			}
+			if owner, ok := existing.UsernameOwners[member.Username]; ok && owner != member.UserID {
+				if policy == domain.ConflictSkip {
+					result.Users.Skipped++
+				} else {
+					conflicts = append(conflicts, fmt.Sprintf("username %s (user %s)", member.Username, owner))
+				}
+				continue
+			}
+			if keep(existing.Users[member.UserID], false, &result.Users, "user "+member.UserID) {
+				members = append(members, member)
+			}
+		}
+
+		if teamKept {
+			filtered.Teams = append(filtered.Teams, &domain.Team{
+				TeamName:  team.TeamName,
+				Members:   members,
+				CreatedAt: team.CreatedAt,
+			})
+		}
+	}
+
+	for _, pr := range snapshot.PullRequests {
+		id := pr.PullRequestID
+		if keep(existing.PullRequests[id], existing.ArchivedPullRequests[id], &result.PullRequests, "pull request "+id) {
+			filtered.PullRequests = append(filtered.PullRequests, pr)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return nil, nil, errors.ErrImportConflict(conflicts)
+	}
+
+	return filtered, result, nil
+}
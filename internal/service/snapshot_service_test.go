@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
+)
+
+func newTestSnapshotService(s *testStore) *SnapshotService {
+	return NewSnapshotService(s.snapshots, s.tx)
+}
+
+func newTestSnapshot() *domain.Snapshot {
+	mergedAt := time.Now()
+	return &domain.Snapshot{
+		Version: domain.SnapshotVersion,
+		Teams: []*domain.Team{
+			{TeamName: "backend", Members: []*domain.User{
+				{UserID: "alice", Username: "Alice", IsActive: true},
+				{UserID: "bob", Username: "Bob", IsActive: true},
+			}},
+			{TeamName: "frontend", Members: []*domain.User{
+				{UserID: "carol", Username: "Carol", IsActive: true},
+			}},
+		},
+		PullRequests: []*domain.PullRequest{
+			{
+				PullRequestID:       "pr-1",
+				PullRequestName:     "Add search",
+				AuthorID:            "alice",
+				Status:              domain.PRStatusOpen,
+				ReviewerAssignments: []domain.ReviewerAssignment{{ReviewerID: "bob"}},
+			},
+			{
+				PullRequestID:   "pr-2",
+				PullRequestName: "Fix login",
+				AuthorID:        "carol",
+				Status:          domain.PRStatusMerged,
+				MergedAt:        &mergedAt,
+			},
+		},
+	}
+}
+
+func expectErrorCode(t *testing.T, err error, code errors.ErrorCode) {
+	t.Helper()
+	var appErr *errors.AppError
+	if !stderrors.As(err, &appErr) || appErr.Code != code {
+		t.Fatalf("Expected %s, got %v", code, err)
+	}
+}
+
+func TestImportIntoEmptyStorage(t *testing.T) {
+	s := newTestStore(t)
+	svc := newTestSnapshotService(s)
+
+	result, err := svc.Import(context.Background(), newTestSnapshot(), domain.ConflictFail)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := domain.ImportResult{
+		Teams:        domain.ImportCounts{Created: 2},
+		Users:        domain.ImportCounts{Created: 3},
+		PullRequests: domain.ImportCounts{Created: 2},
+	}
+	if *result != want {
+		t.Fatalf("Expected %+v, got %+v", want, *result)
+	}
+
+	pr := s.pr("pr-1")
+	if pr.Version != 1 || pr.CreatedAt.IsZero() || !pr.ReviewerAssignments[0].AssignedAt.Equal(pr.CreatedAt) {
+		t.Fatalf("Expected defaults to be filled in, got %+v", pr)
+	}
+	if s.user("alice").TeamName != "backend" {
+		t.Fatal("Expected members to take the team's name")
+	}
+}
+
+func TestImportConflictPolicies(t *testing.T) {
+	newStore := func(t *testing.T) *testStore {
+		s := newTestStore(t)
+		s.addTeam("backend", "alice")
+		s.addPR("pr-1", "alice")
+		s.addPR("pr-2", "alice")
+		s.mergePR("pr-2")
+		if _, err := s.prs.ArchiveMerged(context.Background(), time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("Failed to archive: %v", err)
+		}
+		return s
+	}
+
+	t.Run("fail", func(t *testing.T) {
+		s := newStore(t)
+		svc := newTestSnapshotService(s)
+
+		_, err := svc.Import(context.Background(), newTestSnapshot(), domain.ConflictFail)
+		expectErrorCode(t, err, errors.ErrCodeImportConflict)
+		if s.hasTeam("frontend") || s.hasUser("bob") {
+			t.Fatal("Expected nothing to be imported")
+		}
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		s := newStore(t)
+		svc := newTestSnapshotService(s)
+
+		result, err := svc.Import(context.Background(), newTestSnapshot(), domain.ConflictSkip)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		want := domain.ImportResult{
+			Teams:        domain.ImportCounts{Created: 1, Skipped: 1},
+			Users:        domain.ImportCounts{Created: 1, Skipped: 2},
+			PullRequests: domain.ImportCounts{Skipped: 2},
+		}
+		if *result != want {
+			t.Fatalf("Expected %+v, got %+v", want, *result)
+		}
+		if !s.hasTeam("frontend") || s.hasUser("bob") || s.user("alice").Username != "alice" {
+			t.Fatal("Expected only frontend to be imported")
+		}
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		s := newStore(t)
+		svc := newTestSnapshotService(s)
+
+		result, err := svc.Import(context.Background(), newTestSnapshot(), domain.ConflictOverwrite)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		want := domain.ImportResult{
+			Teams:        domain.ImportCounts{Created: 1, Updated: 1},
+			Users:        domain.ImportCounts{Created: 2, Updated: 1},
+			PullRequests: domain.ImportCounts{Updated: 1, Skipped: 1},
+		}
+		if *result != want {
+			t.Fatalf("Expected %+v, got %+v", want, *result)
+		}
+		if pr := s.pr("pr-1"); pr.PullRequestName != "Add search" || !pr.HasReviewer("bob") {
+			t.Fatalf("Expected pr-1 to be overwritten, got %+v", pr)
+		}
+		if _, err := s.prs.GetByID(context.Background(), "pr-2"); err == nil {
+			t.Fatal("Expected the archived pr-2 to be left alone")
+		}
+	})
+}
+
+func TestImportValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(s *domain.Snapshot)
+		policy domain.ConflictPolicy
+	}{
+		{"unknown policy", func(s *domain.Snapshot) {}, "replace"},
+		{"wrong version", func(s *domain.Snapshot) { s.Version = 2 }, domain.ConflictFail},
+		{"duplicate user", func(s *domain.Snapshot) {
+			s.Teams[1].Members = append(s.Teams[1].Members, &domain.User{UserID: "alice", Username: "Alice2"})
+		}, domain.ConflictFail},
+		{"merged without mergedAt", func(s *domain.Snapshot) { s.PullRequests[1].MergedAt = nil }, domain.ConflictFail},
+		{"author as reviewer", func(s *domain.Snapshot) {
+			s.PullRequests[0].ReviewerAssignments = []domain.ReviewerAssignment{{ReviewerID: "alice"}}
+		}, domain.ConflictFail},
+		{"unknown author", func(s *domain.Snapshot) { s.PullRequests[0].AuthorID = "dave" }, domain.ConflictFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newTestSnapshotService(newTestStore(t))
+
+			snapshot := newTestSnapshot()
+			tt.modify(snapshot)
+
+			_, err := svc.Import(context.Background(), snapshot, tt.policy)
+			expectErrorCode(t, err, errors.ErrCodeInvalidSnapshot)
+		})
+	}
+}
+
+func TestImportUsernameTakenByAnotherUser(t *testing.T) {
+	// dan's username belongs to the existing user "ops".
+	newSnapshot := func() *domain.Snapshot {
+		snapshot := newTestSnapshot()
+		snapshot.Teams[1].Members = append(snapshot.Teams[1].Members, &domain.User{UserID: "dan", Username: "ops", IsActive: true})
+		return snapshot
+	}
+
+	for _, policy := range []domain.ConflictPolicy{domain.ConflictFail, domain.ConflictOverwrite} {
+		t.Run(string(policy), func(t *testing.T) {
+			s := newTestStore(t)
+			s.addTeam("ops", "ops")
+
+			_, err := newTestSnapshotService(s).Import(context.Background(), newSnapshot(), policy)
+			expectErrorCode(t, err, errors.ErrCodeImportConflict)
+			if s.hasTeam("backend") {
+				t.Fatal("Expected nothing to be imported")
+			}
+		})
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		s := newTestStore(t)
+		s.addTeam("ops", "ops")
+
+		result, err := newTestSnapshotService(s).Import(context.Background(), newSnapshot(), domain.ConflictSkip)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		want := domain.ImportCounts{Created: 3, Skipped: 1}
+		if result.Users != want {
+			t.Fatalf("Expected %+v, got %+v", want, result.Users)
+		}
+		if s.hasUser("dan") || s.user("ops").Username != "ops" {
+			t.Fatal("Expected dan to be skipped and ops to keep its username")
+		}
+	})
+}
@@ -3,11 +3,16 @@ package service
 import (
 	"context"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/tracing"
 )
 
 type StatsRepository interface {
-	GetStatistics(ctx context.Context) (*domain.Statistics, error)
+	GetStatistics(ctx context.Context, filter domain.StatsFilter) (*domain.Statistics, error)
+	GetMergedPRTimings(ctx context.Context, filter domain.StatsFilter) ([]domain.MergedPRTiming, error)
+	GetReviewerWorkload(ctx context.Context, filter domain.StatsFilter) ([]domain.ReviewerWorkload, error)
 }
 
 type StatsService struct {
@@ -20,6 +25,49 @@ func NewStatsService(statsRepo StatsRepository) *StatsService {
 	}
 }
 
-func (s *StatsService) GetStatistics(ctx context.Context) (*domain.Statistics, error) {
-	return s.statsRepo.GetStatistics(ctx)
+func (s *StatsService) GetStatistics(ctx context.Context, filter domain.StatsFilter) (_ *domain.Statistics, err error) {
+	ctx, span := tracing.StartSpan(ctx, "StatsService.GetStatistics", attribute.String("team.name", filter.TeamName))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	return s.statsRepo.GetStatistics(ctx, filter)
+}
+
+func (s *StatsService) GetCycleTime(ctx context.Context, filter domain.StatsFilter) (_ *domain.CycleTimeStats, err error) {
+	ctx, span := tracing.StartSpan(ctx, "StatsService.GetCycleTime", attribute.String("team.name", filter.TeamName))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	timings, err := s.statsRepo.GetMergedPRTimings(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := buildCycleTimeStats(timings)
+	if filter.From != nil || filter.To != nil {
+		stats.Period = &domain.StatsPeriod{
+			From: filter.From,
+			To:   filter.To,
+		}
+	}
+
+	return stats, nil
+}
+
+func (s *StatsService) GetWorkload(ctx context.Context, filter domain.StatsFilter) (_ *domain.WorkloadStats, err error) {
+	ctx, span := tracing.StartSpan(ctx, "StatsService.GetWorkload", attribute.String("team.name", filter.TeamName))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	workloads, err := s.statsRepo.GetReviewerWorkload(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := buildWorkloadStats(workloads)
+	if filter.From != nil || filter.To != nil {
+		stats.Period = &domain.StatsPeriod{
+			From: filter.From,
+			To:   filter.To,
+		}
+	}
+
+	return stats, nil
 }
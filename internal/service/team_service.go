@@ -3,14 +3,19 @@ package service
 import (
 	"context"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
 	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/tracing"
 )
 
 type TeamRepository interface {
 	Create(ctx context.Context, team *domain.Team) error
 	GetByName(ctx context.Context, teamName string) (*domain.Team, error)
 	Exists(ctx context.Context, teamName string) (bool, error)
+	Delete(ctx context.Context, teamName string) error
+	Restore(ctx context.Context, teamName string) error
 }
 
 type TeamService struct {
@@ -23,7 +28,13 @@ func NewTeamService(teamRepo TeamRepository) *TeamService {
 	}
 }
 
-func (s *TeamService) CreateTeam(ctx context.Context, teamName string, members []*domain.User) (*domain.Team, error) {
+func (s *TeamService) CreateTeam(ctx context.Context, teamName string, members []*domain.User) (_ *domain.Team, err error) {
+	ctx, span := tracing.StartSpan(ctx, "TeamService.CreateTeam",
+		attribute.String("team.name", teamName),
+		attribute.Int("team.members", len(members)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
 	exists, err := s.teamRepo.Exists(ctx, teamName)
 	if err != nil {
 		return nil, err
@@ -42,6 +53,9 @@ func (s *TeamService) CreateTeam(ctx context.Context, teamName string, members [
 	return team, nil
 }
 
-func (s *TeamService) GetTeam(ctx context.Context, teamName string) (*domain.Team, error) {
+func (s *TeamService) GetTeam(ctx context.Context, teamName string) (_ *domain.Team, err error) {
+	ctx, span := tracing.StartSpan(ctx, "TeamService.GetTeam", attribute.String("team.name", teamName))
+	defer func() { tracing.EndSpan(span, err) }()
+
 	return s.teamRepo.GetByName(ctx, teamName)
 }
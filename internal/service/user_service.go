@@ -3,7 +3,10 @@ package service
 import (
 	"context"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/tracing"
 )
 
 type UserRepository interface {
@@ -13,6 +16,14 @@ type UserRepository interface {
 	GetByTeam(ctx context.Context, teamName string) ([]*domain.User, error)
 	GetUsersByIDs(ctx context.Context, userIDs []string) ([]*domain.User, error)
 	BulkDeactivate(ctx context.Context, userIDs []string) error
+	Upsert(ctx context.Context, users []*domain.User) error
+	List(ctx context.Context, filter domain.UserFilter) ([]*domain.User, error)
+	GetOpenReviewCounts(ctx context.Context, userIDs []string) (map[string]int, error)
+	// GetUsernameOwners maps each taken username to the user holding it.
+	// Deleted users keep their usernames.
+	GetUsernameOwners(ctx context.Context, usernames []string) (map[string]string, error)
+	Delete(ctx context.Context, userID string) error
+	Restore(ctx context.Context, userID string) error
 }
 
 type UserService struct {
@@ -25,7 +36,13 @@ func NewUserService(userRepo UserRepository) *UserService {
 	}
 }
 
-func (s *UserService) SetActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
+func (s *UserService) SetActive(ctx context.Context, userID string, isActive bool) (_ *domain.User, err error) {
+	ctx, span := tracing.StartSpan(ctx, "UserService.SetActive",
+		attribute.String("user.id", userID),
+		attribute.Bool("user.is_active", isActive),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
 	if err := s.userRepo.SetActive(ctx, userID, isActive); err != nil {
 		return nil, err
 	}
@@ -38,6 +55,59 @@ func (s *UserService) SetActive(ctx context.Context, userID string, isActive boo
 	return user, nil
 }
 
-func (s *UserService) GetByID(ctx context.Context, userID string) (*domain.User, error) {
+func (s *UserService) GetByID(ctx context.Context, userID string) (_ *domain.User, err error) {
+	ctx, span := tracing.StartSpan(ctx, "UserService.GetByID", attribute.String("user.id", userID))
+	defer func() { tracing.EndSpan(span, err) }()
+
 	return s.userRepo.GetByID(ctx, userID)
 }
+
+func (s *UserService) GetDetails(ctx context.Context, userID string) (_ *domain.UserDetails, err error) {
+	ctx, span := tracing.StartSpan(ctx, "UserService.GetDetails", attribute.String("user.id", userID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.userRepo.GetOpenReviewCounts(ctx, []string{userID})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.UserDetails{
+		User:            user,
+		OpenReviewCount: counts[userID],
+	}, nil
+}
+
+func (s *UserService) ListUsers(ctx context.Context, filter domain.UserFilter) (_ []*domain.UserDetails, err error) {
+	ctx, span := tracing.StartSpan(ctx, "UserService.ListUsers", attribute.String("team.name", filter.TeamName))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	users, err := s.userRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]string, 0, len(users))
+	for _, user := range users {
+		userIDs = append(userIDs, user.UserID)
+	}
+
+	counts, err := s.userRepo.GetOpenReviewCounts(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]*domain.UserDetails, 0, len(users))
+	for _, user := range users {
+		details = append(details, &domain.UserDetails{
+			User:            user,
+			OpenReviewCount: counts[user.UserID],
+		})
+	}
+
+	return details, nil
+}
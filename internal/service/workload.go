@@ -0,0 +1,71 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+)
+
+func buildWorkloadStats(workloads []domain.ReviewerWorkload) *domain.WorkloadStats {
+	openByTeam := make(map[string][]int)
+	assignedByTeam := make(map[string][]int)
+
+	for _, w := range workloads {
+		openByTeam[w.TeamName] = append(openByTeam[w.TeamName], w.OpenReviews)
+		assignedByTeam[w.TeamName] = append(assignedByTeam[w.TeamName], w.AssignedReviews)
+	}
+
+	teams := make([]domain.TeamWorkload, 0, len(openByTeam))
+	for teamName, open := range openByTeam {
+		teams = append(teams, domain.TeamWorkload{
+			TeamName:        teamName,
+			ActiveUsers:     len(open),
+			OpenReviews:     computeFairness(open),
+			AssignedReviews: computeFairness(assignedByTeam[teamName]),
+		})
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		return teams[i].TeamName < teams[j].TeamName
+	})
+
+	return &domain.WorkloadStats{
+		Users: workloads,
+		Teams: teams,
+	}
+}
+
+// computeFairness reports the Gini coefficient (0 is a perfectly even
+// distribution) and the max/min ratio, which is undefined when someone
+// has no reviews at all.
+func computeFairness(values []int) domain.FairnessStats {
+	if len(values) == 0 {
+		return domain.FairnessStats{}
+	}
+
+	sorted := make([]int, len(values))
+	copy(sorted, values)
+	sort.Ints(sorted)
+
+	stats := domain.FairnessStats{
+		Min: sorted[0],
+		Max: sorted[len(sorted)-1],
+	}
+
+	weighted := 0
+	for i, v := range sorted {
+		stats.Total += v
+		weighted += (i + 1) * v
+	}
+
+	if stats.Total > 0 {
+		n := float64(len(sorted))
+		stats.Gini = 2*float64(weighted)/(n*float64(stats.Total)) - (n+1)/n
+	}
+
+	if stats.Min > 0 {
+		ratio := float64(stats.Max) / float64(stats.Min)
+		stats.MaxMinRatio = &ratio
+	}
+
+	return stats
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputeFairness(t *testing.T) {
+	ratio := func(r float64) *float64 { return &r }
+
+	tests := []struct {
+		name     string
+		values   []int
+		total    int
+		min, max int
+		gini     float64
+		ratio    *float64
+	}{
+		{name: "no reviewers", values: nil},
+		{name: "all zero load", values: []int{0, 0, 0}},
+		{name: "single reviewer", values: []int{5}, total: 5, min: 5, max: 5, ratio: ratio(1)},
+		{name: "even load", values: []int{3, 3, 3}, total: 9, min: 3, max: 3, ratio: ratio(1)},
+		{name: "uneven load", values: []int{6, 1, 3, 2}, total: 12, min: 1, max: 6, gini: 1.0 / 3, ratio: ratio(6)},
+		{name: "one reviewer takes everything", values: []int{0, 4, 0}, total: 4, max: 4, gini: 2.0 / 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeFairness(tt.values)
+
+			if got.Total != tt.total || got.Min != tt.min || got.Max != tt.max {
+				t.Fatalf("Expected total %d, min %d, max %d, got %+v", tt.total, tt.min, tt.max, got)
+			}
+			if math.Abs(got.Gini-tt.gini) > 1e-9 {
+				t.Fatalf("Expected gini %v, got %v", tt.gini, got.Gini)
+			}
+			switch {
+			case tt.ratio == nil && got.MaxMinRatio != nil:
+				t.Fatalf("Expected no max/min ratio, got %v", *got.MaxMinRatio)
+			case tt.ratio != nil && (got.MaxMinRatio == nil || *got.MaxMinRatio != *tt.ratio):
+				t.Fatalf("Expected max/min ratio %v, got %v", *tt.ratio, got.MaxMinRatio)
+			}
+		})
+	}
+}
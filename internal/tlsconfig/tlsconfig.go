@@ -0,0 +1,192 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/config"
+)
+
+// Reloader serves the certificates named in the configuration and picks up
+// new versions of the files without restarting the server.
+type Reloader struct {
+	cfg config.TLSConfig
+
+	mu       sync.RWMutex
+	current  *tls.Config
+	modTimes map[string]time.Time
+}
+
+func NewReloader(cfg config.TLSConfig) (*Reloader, error) {
+	r := &Reloader{cfg: cfg}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ServerConfig returns the configuration to pass to http.Server. Every
+// handshake uses the most recently loaded certificates.
+func (r *Reloader) ServerConfig() *tls.Config {
+	r.mu.RLock()
+	minVersion := r.current.MinVersion
+	r.mu.RUnlock()
+
+	return &tls.Config{
+		MinVersion: minVersion,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			return r.current, nil
+		},
+	}
+}
+
+// Reload reads the certificate, key and client CA files again. On error the
+// previously loaded configuration stays in use.
+func (r *Reloader) Reload() error {
+	modTimes, err := r.statFiles()
+	if err != nil {
+		return err
+	}
+
+	next, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.current = next
+	r.modTimes = modTimes
+	r.mu.Unlock()
+
+	return nil
+}
+
+// Watch reloads the certificates whenever one of the files changes on disk,
+// checking every interval until ctx is cancelled.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+
+			if err := r.Reload(); err != nil {
+				slog.Error("failed to reload TLS certificates", slog.String("error", err.Error()))
+				continue
+			}
+
+			slog.Info("TLS certificates reloaded")
+		}
+	}
+}
+
+func (r *Reloader) changed() bool {
+	modTimes, err := r.statFiles()
+	if err != nil {
+		return false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for path, modTime := range modTimes {
+		if !modTime.Equal(r.modTimes[path]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Reloader) statFiles() (map[string]time.Time, error) {
+	modTimes := make(map[string]time.Time)
+	for _, path := range []string{r.cfg.CertFile, r.cfg.KeyFile, r.cfg.ClientCAFile} {
+		if path == "" {
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+		modTimes[path] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+func (r *Reloader) load() (*tls.Config, error) {
+	minVersion, err := ParseVersion(r.cfg.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:   minVersion,
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+
+	if r.cfg.ClientCAFile != "" {
+		pool, err := loadCertPool(r.cfg.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("client CA file contains no certificates")
+	}
+
+	return pool, nil
+}
+
+func ParseVersion(version string) (uint16, error) {
+	switch version {
+	case "1.2", "":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version %q: must be 1.2 or 1.3", version)
+	}
+}
+
+// Identity returns the caller name for a client certificate: the common name
+// when present, otherwise the full subject.
+func Identity(cert *x509.Certificate) string {
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+	return cert.Subject.String()
+}
@@ -38,6 +38,24 @@ type User struct {
 	IsActive bool   `json:"is_active"`
 }
 
+type UserDetails struct {
+	UserID          string    `json:"user_id"`
+	Username        string    `json:"username"`
+	TeamName        string    `json:"team_name"`
+	IsActive        bool      `json:"is_active"`
+	OpenReviewCount int       `json:"open_review_count"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+type UserDetailsResponse struct {
+	User *UserDetails `json:"user"`
+}
+
+type ListUsersResponse struct {
+	Users []*UserDetails `json:"users"`
+}
+
 type CreatePRRequest struct {
 	PullRequestID   string `json:"pull_request_id"`
 	PullRequestName string `json:"pull_request_name"`
@@ -49,13 +67,21 @@ type PRResponse struct {
 }
 
 type PullRequest struct {
-	PullRequestID     string     `json:"pull_request_id"`
-	PullRequestName   string     `json:"pull_request_name"`
-	AuthorID          string     `json:"author_id"`
-	Status            string     `json:"status"`
-	AssignedReviewers []string   `json:"assigned_reviewers"`
-	CreatedAt         *time.Time `json:"createdAt,omitempty"`
-	MergedAt          *time.Time `json:"mergedAt,omitempty"`
+	PullRequestID       string                `json:"pull_request_id"`
+	PullRequestName     string                `json:"pull_request_name"`
+	AuthorID            string                `json:"author_id"`
+	Status              string                `json:"status"`
+	AssignedReviewers   []string              `json:"assigned_reviewers"`
+	ReviewerAssignments []*ReviewerAssignment `json:"reviewer_assignments,omitempty"`
+	CreatedAt           *time.Time            `json:"createdAt,omitempty"`
+	UpdatedAt           *time.Time            `json:"updatedAt,omitempty"`
+	MergedAt            *time.Time            `json:"mergedAt,omitempty"`
+	Version             int                   `json:"version,omitempty"`
+}
+
+type ReviewerAssignment struct {
+	ReviewerID string    `json:"reviewer_id"`
+	AssignedAt time.Time `json:"assignedAt"`
 }
 
 type MergePRRequest struct {
@@ -85,8 +111,9 @@ type GetReviewResponse struct {
 }
 
 type BulkDeactivateRequest struct {
-	TeamName string   `json:"team_name"`
-	UserIDs  []string `json:"user_ids,omitempty"`
+	TeamName   string   `json:"team_name"`
+	UserIDs    []string `json:"user_ids,omitempty"`
+	BestEffort bool     `json:"best_effort,omitempty"`
 }
 
 type BulkDeactivateResponse struct {
@@ -95,6 +122,38 @@ type BulkDeactivateResponse struct {
 	SkippedPRs       []SkippedPRInfo    `json:"skipped_prs"`
 }
 
+// RosterImportResponse is returned by /team/import. reassigned_prs and
+// skipped_prs stay empty in a dry run.
+type RosterImportResponse struct {
+	DryRun        bool                 `json:"dry_run"`
+	NewTeams      []string             `json:"new_teams"`
+	NewUsers      []RosterUser         `json:"new_users"`
+	MovedUsers    []RosterMove         `json:"moved_users"`
+	UpdatedUsers  []RosterUser         `json:"updated_users"`
+	Deactivations []RosterDeactivation `json:"deactivations"`
+	ReassignedPRs []ReassignedPRInfo   `json:"reassigned_prs"`
+	SkippedPRs    []SkippedPRInfo      `json:"skipped_prs"`
+}
+
+type RosterUser struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	TeamName string `json:"team_name"`
+	IsActive bool   `json:"is_active"`
+}
+
+type RosterMove struct {
+	UserID   string `json:"user_id"`
+	FromTeam string `json:"from_team"`
+	ToTeam   string `json:"to_team"`
+}
+
+type RosterDeactivation struct {
+	UserID   string `json:"user_id"`
+	TeamName string `json:"team_name"`
+	Reason   string `json:"reason"`
+}
+
 type ReassignedPRInfo struct {
 	PullRequestID string `json:"pull_request_id"`
 	OldReviewerID string `json:"old_reviewer_id"`
@@ -106,11 +165,97 @@ type SkippedPRInfo struct {
 	Reason        string `json:"reason"`
 }
 
+type TeamNameRequest struct {
+	TeamName string `json:"team_name"`
+}
+
+type UserIDRequest struct {
+	UserID string `json:"user_id"`
+}
+
+type PullRequestIDRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+}
+
 type ErrorResponse struct {
 	Error ErrorDetail `json:"error"`
 }
 
 type ErrorDetail struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Snapshot is the export format of /admin/export and /admin/import.
+type Snapshot struct {
+	Version      int                    `json:"version"`
+	ExportedAt   *time.Time             `json:"exported_at,omitempty"`
+	Teams        []*SnapshotTeam        `json:"teams"`
+	PullRequests []*SnapshotPullRequest `json:"pull_requests"`
+}
+
+type SnapshotTeam struct {
+	TeamName  string          `json:"team_name"`
+	CreatedAt *time.Time      `json:"createdAt,omitempty"`
+	Members   []*SnapshotUser `json:"members"`
+}
+
+type SnapshotUser struct {
+	UserID    string     `json:"user_id"`
+	Username  string     `json:"username"`
+	IsActive  bool       `json:"is_active"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+type SnapshotPullRequest struct {
+	PullRequestID       string                `json:"pull_request_id"`
+	PullRequestName     string                `json:"pull_request_name"`
+	AuthorID            string                `json:"author_id"`
+	Status              string                `json:"status"`
+	ReviewerAssignments []*ReviewerAssignment `json:"reviewer_assignments"`
+	CreatedAt           *time.Time            `json:"createdAt,omitempty"`
+	UpdatedAt           *time.Time            `json:"updatedAt,omitempty"`
+	MergedAt            *time.Time            `json:"mergedAt,omitempty"`
+	Version             int                   `json:"version,omitempty"`
+}
+
+// SnapshotRecord is one line of the NDJSON format: a header with the version
+// first, then one line per team and per pull request.
+type SnapshotRecord struct {
+	Type        string               `json:"type"`
+	Version     int                  `json:"version,omitempty"`
+	ExportedAt  *time.Time           `json:"exported_at,omitempty"`
+	Team        *SnapshotTeam        `json:"team,omitempty"`
+	PullRequest *SnapshotPullRequest `json:"pull_request,omitempty"`
+}
+
+const (
+	SnapshotRecordHeader      = "header"
+	SnapshotRecordTeam        = "team"
+	SnapshotRecordPullRequest = "pull_request"
+)
+
+type ImportCounts struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+}
+
+type ImportResponse struct {
+	Teams        ImportCounts `json:"teams"`
+	Users        ImportCounts `json:"users"`
+	PullRequests ImportCounts `json:"pull_requests"`
+}
+
+type HealthResponse struct {
+	Status string                       `json:"status"`
+	Checks map[string]HealthCheckResult `json:"checks,omitempty"`
+}
+
+type HealthCheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
 }
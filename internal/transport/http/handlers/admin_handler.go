@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/transport/http/dto"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/transport/http/middleware"
+)
+
+const ndjsonContentType = "application/x-ndjson"
+
+type AdminHandler struct {
+	snapshotService SnapshotService
+}
+
+func NewAdminHandler(snapshotService SnapshotService) *AdminHandler {
+	return &AdminHandler{
+		snapshotService: snapshotService,
+	}
+}
+
+// Export returns the snapshot as one JSON document, or as NDJSON with
+// ?format=ndjson or Accept: application/x-ndjson.
+func (h *AdminHandler) Export(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" && strings.Contains(r.Header.Get("Accept"), ndjsonContentType) {
+		format = "ndjson"
+	}
+	if format != "" && format != "json" && format != "ndjson" {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "format must be json or ndjson")
+		return
+	}
+
+	snapshot, err := h.snapshotService.Export(r.Context())
+	if err != nil {
+		middleware.WriteError(w, err)
+		return
+	}
+
+	response := mapSnapshotToDTO(snapshot)
+
+	if format != "ndjson" {
+		middleware.WriteJSON(w, http.StatusOK, response)
+		return
+	}
+
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.WriteHeader(http.StatusOK)
+	writeSnapshotNDJSON(w, response)
+}
+
+// writeSnapshotNDJSON writes one record per line. The snapshot is already
+// loaded in full, so NDJSON saves memory only on the client side.
+func writeSnapshotNDJSON(w http.ResponseWriter, snapshot *dto.Snapshot) {
+	encoder := json.NewEncoder(w)
+
+	_ = encoder.Encode(dto.SnapshotRecord{ //nolint:errcheck
+		Type:       dto.SnapshotRecordHeader,
+		Version:    snapshot.Version,
+		ExportedAt: snapshot.ExportedAt,
+	})
+
+	for _, team := range snapshot.Teams {
+		if err := encoder.Encode(dto.SnapshotRecord{Type: dto.SnapshotRecordTeam, Team: team}); err != nil {
+			return
+		}
+	}
+
+	for _, pr := range snapshot.PullRequests {
+		if err := encoder.Encode(dto.SnapshotRecord{Type: dto.SnapshotRecordPullRequest, PullRequest: pr}); err != nil {
+			return
+		}
+	}
+}
+
+// Import loads a snapshot sent as JSON or, with Content-Type
+// application/x-ndjson, as NDJSON. ?on_conflict chooses what happens to
+// records that already exist and defaults to fail.
+func (h *AdminHandler) Import(w http.ResponseWriter, r *http.Request) {
+	policy := domain.ConflictPolicy(r.URL.Query().Get("on_conflict"))
+	if policy == "" {
+		policy = domain.ConflictFail
+	}
+
+	var snapshot *dto.Snapshot
+	var err error
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == ndjsonContentType {
+		snapshot, err = readSnapshotNDJSON(r.Body)
+	} else {
+		snapshot = &dto.Snapshot{}
+		err = json.NewDecoder(r.Body).Decode(snapshot)
+	}
+	if middleware.IsBodyTooLarge(err) {
+		middleware.WriteBodyTooLarge(w)
+		return
+	}
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", fmt.Sprintf("Invalid snapshot: %v", err))
+		return
+	}
+
+	result, err := h.snapshotService.Import(r.Context(), mapSnapshotFromDTO(snapshot), policy)
+	if err != nil {
+		middleware.WriteError(w, err)
+		return
+	}
+
+	response := dto.ImportResponse{
+		Teams:        dto.ImportCounts(result.Teams),
+		Users:        dto.ImportCounts(result.Users),
+		PullRequests: dto.ImportCounts(result.PullRequests),
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, response)
+}
+
+func readSnapshotNDJSON(body io.Reader) (*dto.Snapshot, error) {
+	snapshot := &dto.Snapshot{}
+	decoder := json.NewDecoder(body)
+
+	for line := 1; ; line++ {
+		var record dto.SnapshotRecord
+		if err := decoder.Decode(&record); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("record %d: %w", line, err)
+		}
+
+		switch {
+		case record.Type == dto.SnapshotRecordHeader && line == 1:
+			snapshot.Version = record.Version
+			snapshot.ExportedAt = record.ExportedAt
+		case record.Type == dto.SnapshotRecordTeam && record.Team != nil:
+			snapshot.Teams = append(snapshot.Teams, record.Team)
+		case record.Type == dto.SnapshotRecordPullRequest && record.PullRequest != nil:
+			snapshot.PullRequests = append(snapshot.PullRequests, record.PullRequest)
+		default:
+			return nil, fmt.Errorf("record %d: unexpected %q record", line, record.Type)
+		}
+	}
+
+	return snapshot, nil
+}
+
+func mapSnapshotToDTO(snapshot *domain.Snapshot) *dto.Snapshot {
+	teams := make([]*dto.SnapshotTeam, 0, len(snapshot.Teams))
+	for _, team := range snapshot.Teams {
+		members := make([]*dto.SnapshotUser, 0, len(team.Members))
+		for _, m := range team.Members {
+			members = append(members, &dto.SnapshotUser{
+				UserID:    m.UserID,
+				Username:  m.Username,
+				IsActive:  m.IsActive,
+				CreatedAt: timePtr(m.CreatedAt),
+				UpdatedAt: timePtr(m.UpdatedAt),
+			})
+		}
+		teams = append(teams, &dto.SnapshotTeam{
+			TeamName:  team.TeamName,
+			CreatedAt: timePtr(team.CreatedAt),
+			Members:   members,
+		})
+	}
+
+	prs := make([]*dto.SnapshotPullRequest, 0, len(snapshot.PullRequests))
+	for _, pr := range snapshot.PullRequests {
+		assignments := mapReviewerAssignmentsToDTO(pr.ReviewerAssignments)
+		if assignments == nil {
+			assignments = []*dto.ReviewerAssignment{}
+		}
+		prs = append(prs, &dto.SnapshotPullRequest{
+			PullRequestID:       pr.PullRequestID,
+			PullRequestName:     pr.PullRequestName,
+			AuthorID:            pr.AuthorID,
+			Status:              pr.Status.String(),
+			ReviewerAssignments: assignments,
+			CreatedAt:           timePtr(pr.CreatedAt),
+			UpdatedAt:           timePtr(pr.UpdatedAt),
+			MergedAt:            pr.MergedAt,
+			Version:             pr.Version,
+		})
+	}
+
+	return &dto.Snapshot{
+		Version:      snapshot.Version,
+		ExportedAt:   timePtr(snapshot.ExportedAt),
+		Teams:        teams,
+		PullRequests: prs,
+	}
+}
+
+// mapSnapshotFromDTO leaves missing timestamps zero for the service to fill
+// in.
+func mapSnapshotFromDTO(snapshot *dto.Snapshot) *domain.Snapshot {
+	teams := make([]*domain.Team, 0, len(snapshot.Teams))
+	for _, team := range snapshot.Teams {
+		members := make([]*domain.User, 0, len(team.Members))
+		for _, m := range team.Members {
+			members = append(members, &domain.User{
+				UserID:    m.UserID,
+				Username:  m.Username,
+				TeamName:  team.TeamName,
+				IsActive:  m.IsActive,
+				CreatedAt: timeValue(m.CreatedAt),
+				UpdatedAt: timeValue(m.UpdatedAt),
+			})
+		}
+		teams = append(teams, &domain.Team{
+			TeamName:  team.TeamName,
+			Members:   members,
+			CreatedAt: timeValue(team.CreatedAt),
+		})
+	}
+
+	prs := make([]*domain.PullRequest, 0, len(snapshot.PullRequests))
+	for _, pr := range snapshot.PullRequests {
+		assignments := make([]domain.ReviewerAssignment, 0, len(pr.ReviewerAssignments))
+		for _, a := range pr.ReviewerAssignments {
+			assignments = append(assignments, domain.ReviewerAssignment{
+				ReviewerID: a.ReviewerID,
+				AssignedAt: a.AssignedAt,
+			})
+		}
+		prs = append(prs, &domain.PullRequest{
+			PullRequestID:       pr.PullRequestID,
+			PullRequestName:     pr.PullRequestName,
+			AuthorID:            pr.AuthorID,
+			Status:              domain.PRStatus(pr.Status),
+			ReviewerAssignments: assignments,
+			CreatedAt:           timeValue(pr.CreatedAt),
+			UpdatedAt:           timeValue(pr.UpdatedAt),
+			MergedAt:            pr.MergedAt,
+			Version:             pr.Version,
+		})
+	}
+
+	return &domain.Snapshot{
+		Version:      snapshot.Version,
+		ExportedAt:   timeValue(snapshot.ExportedAt),
+		Teams:        teams,
+		PullRequests: prs,
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func timeValue(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/transport/http/dto"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/transport/http/middleware"
+)
+
+const (
+	healthStatusOK   = "ok"
+	healthStatusFail = "fail"
+)
+
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type HealthHandler struct {
+	checks       []HealthCheck
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+func NewHealthHandler(timeout time.Duration, checks ...HealthCheck) *HealthHandler {
+	return &HealthHandler{
+		checks:  checks,
+		timeout: timeout,
+	}
+}
+
+// SetShuttingDown makes readiness fail so that load balancers stop routing
+// new traffic before the server is shut down.
+func (h *HealthHandler) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	middleware.WriteJSON(w, http.StatusOK, dto.HealthResponse{Status: healthStatusOK})
+}
+
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	response := dto.HealthResponse{
+		Status: healthStatusOK,
+		Checks: make(map[string]dto.HealthCheckResult, len(h.checks)+1),
+	}
+
+	shutdown := dto.HealthCheckResult{Status: healthStatusOK}
+	if h.shuttingDown.Load() {
+		shutdown = dto.HealthCheckResult{Status: healthStatusFail, Error: "server is shutting down"}
+		response.Status = healthStatusFail
+	}
+	response.Checks["shutdown"] = shutdown
+
+	for _, check := range h.checks {
+		start := time.Now()
+		err := check.Check(ctx)
+
+		result := dto.HealthCheckResult{
+			Status:    healthStatusOK,
+			LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+		}
+		if err != nil {
+			result.Status = healthStatusFail
+			result.Error = err.Error()
+			response.Status = healthStatusFail
+		}
+		response.Checks[check.Name] = result
+	}
+
+	statusCode := http.StatusOK
+	if response.Status != healthStatusOK {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	middleware.WriteJSON(w, statusCode, response)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"time"
 
 	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
 )
@@ -14,20 +15,48 @@ type TeamService interface {
 type UserService interface {
 	SetActive(ctx context.Context, userID string, isActive bool) (*domain.User, error)
 	GetByID(ctx context.Context, userID string) (*domain.User, error)
+	GetDetails(ctx context.Context, userID string) (*domain.UserDetails, error)
+	ListUsers(ctx context.Context, filter domain.UserFilter) ([]*domain.UserDetails, error)
 }
 
 type PRService interface {
 	CreatePR(ctx context.Context, prID, prName, authorID string) (*domain.PullRequest, error)
-	MergePR(ctx context.Context, prID string) (*domain.PullRequest, error)
-	ReassignReviewer(ctx context.Context, prID, oldReviewerID string) (*domain.PullRequest, string, error)
+	MergePR(ctx context.Context, prID string, expectedVersion int) (*domain.PullRequest, error)
+	ReassignReviewer(ctx context.Context, prID, oldReviewerID string, expectedVersion int) (*domain.PullRequest, string, error)
 	GetPRsByReviewer(ctx context.Context, reviewerID string) ([]*domain.PullRequest, error)
 	GetByID(ctx context.Context, prID string) (*domain.PullRequest, error)
 }
 
 type StatsService interface {
-	GetStatistics(ctx context.Context) (*domain.Statistics, error)
+	GetStatistics(ctx context.Context, filter domain.StatsFilter) (*domain.Statistics, error)
+	GetCycleTime(ctx context.Context, filter domain.StatsFilter) (*domain.CycleTimeStats, error)
+	GetWorkload(ctx context.Context, filter domain.StatsFilter) (*domain.WorkloadStats, error)
 }
 
 type BulkDeactivationService interface {
-	DeactivateUsersAndReassignPRs(ctx context.Context, teamName string, userIDs []string) (*domain.BulkDeactivationResult, error)
+	DeactivateUsersAndReassignPRs(
+		ctx context.Context,
+		teamName string,
+		userIDs []string,
+		opts domain.BulkDeactivationOptions,
+	) (*domain.BulkDeactivationResult, error)
+}
+
+type DeletionService interface {
+	DeleteUser(ctx context.Context, userID string) (*domain.BulkDeactivationResult, error)
+	RestoreUser(ctx context.Context, userID string) (*domain.User, error)
+	DeleteTeam(ctx context.Context, teamName string) (*domain.BulkDeactivationResult, error)
+	RestoreTeam(ctx context.Context, teamName string) (*domain.Team, error)
+	DeletePR(ctx context.Context, prID string) error
+	RestorePR(ctx context.Context, prID string) (*domain.PullRequest, error)
+	ArchiveMergedPRs(ctx context.Context, olderThan time.Duration) (int, error)
+}
+
+type SnapshotService interface {
+	Export(ctx context.Context) (*domain.Snapshot, error)
+	Import(ctx context.Context, snapshot *domain.Snapshot, policy domain.ConflictPolicy) (*domain.ImportResult, error)
+}
+
+type RosterService interface {
+	ImportRoster(ctx context.Context, entries []domain.RosterEntry, dryRun bool) (*domain.RosterImportResult, error)
 }
@@ -2,20 +2,27 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
 	"github.com/Raisondetr3/Avito-test-assignment/internal/transport/http/dto"
 	"github.com/Raisondetr3/Avito-test-assignment/internal/transport/http/middleware"
 )
 
+var errInvalidIfMatch = errors.New(`If-Match must be a single version ETag such as "3"`)
+
 type PRHandler struct {
-	prService PRService
+	prService       PRService
+	deletionService DeletionService
 }
 
-func NewPRHandler(prService PRService) *PRHandler {
+func NewPRHandler(prService PRService, deletionService DeletionService) *PRHandler {
 	return &PRHandler{
-		prService: prService,
+		prService:       prService,
+		deletionService: deletionService,
 	}
 }
 
@@ -36,6 +43,7 @@ func (h *PRHandler) CreatePR(w http.ResponseWriter, r *http.Request) {
 		PR: mapPRToDTO(pr),
 	}
 
+	setETag(w, pr)
 	middleware.WriteJSON(w, http.StatusCreated, response)
 }
 
@@ -46,7 +54,13 @@ func (h *PRHandler) MergePR(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pr, err := h.prService.MergePR(r.Context(), req.PullRequestID)
+	expectedVersion, err := parseIfMatch(r)
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	pr, err := h.prService.MergePR(r.Context(), req.PullRequestID, expectedVersion)
 	if err != nil {
 		middleware.WriteError(w, err)
 		return
@@ -56,6 +70,7 @@ func (h *PRHandler) MergePR(w http.ResponseWriter, r *http.Request) {
 		PR: mapPRToDTO(pr),
 	}
 
+	setETag(w, pr)
 	middleware.WriteJSON(w, http.StatusOK, response)
 }
 
@@ -66,7 +81,13 @@ func (h *PRHandler) ReassignPR(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pr, newReviewerID, err := h.prService.ReassignReviewer(r.Context(), req.PullRequestID, req.OldReviewerID)
+	expectedVersion, err := parseIfMatch(r)
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	pr, newReviewerID, err := h.prService.ReassignReviewer(r.Context(), req.PullRequestID, req.OldReviewerID, expectedVersion)
 	if err != nil {
 		middleware.WriteError(w, err)
 		return
@@ -77,17 +98,128 @@ func (h *PRHandler) ReassignPR(w http.ResponseWriter, r *http.Request) {
 		ReplacedBy: newReviewerID,
 	}
 
+	setETag(w, pr)
+	middleware.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *PRHandler) GetPR(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "pull_request_id is required")
+		return
+	}
+
+	pr, err := h.prService.GetByID(r.Context(), prID)
+	if err != nil {
+		middleware.WriteError(w, err)
+		return
+	}
+
+	response := dto.PRResponse{
+		PR: mapPRToDTO(pr),
+	}
+
+	setETag(w, pr)
+	middleware.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *PRHandler) DeletePR(w http.ResponseWriter, r *http.Request) {
+	var req dto.PullRequestIDRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	if req.PullRequestID == "" {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "pull_request_id is required")
+		return
+	}
+
+	if err := h.deletionService.DeletePR(r.Context(), req.PullRequestID); err != nil {
+		middleware.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *PRHandler) RestorePR(w http.ResponseWriter, r *http.Request) {
+	var req dto.PullRequestIDRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	if req.PullRequestID == "" {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "pull_request_id is required")
+		return
+	}
+
+	pr, err := h.deletionService.RestorePR(r.Context(), req.PullRequestID)
+	if err != nil {
+		middleware.WriteError(w, err)
+		return
+	}
+
+	response := dto.PRResponse{
+		PR: mapPRToDTO(pr),
+	}
+
+	setETag(w, pr)
 	middleware.WriteJSON(w, http.StatusOK, response)
 }
 
 func mapPRToDTO(pr *domain.PullRequest) *dto.PullRequest {
 	return &dto.PullRequest{
-		PullRequestID:     pr.PullRequestID,
-		PullRequestName:   pr.PullRequestName,
-		AuthorID:          pr.AuthorID,
-		Status:            pr.Status.String(),
-		AssignedReviewers: pr.AssignedReviewers,
-		CreatedAt:         &pr.CreatedAt,
-		MergedAt:          pr.MergedAt,
+		PullRequestID:       pr.PullRequestID,
+		PullRequestName:     pr.PullRequestName,
+		AuthorID:            pr.AuthorID,
+		Status:              pr.Status.String(),
+		AssignedReviewers:   pr.AssignedReviewers,
+		ReviewerAssignments: mapReviewerAssignmentsToDTO(pr.ReviewerAssignments),
+		CreatedAt:           &pr.CreatedAt,
+		UpdatedAt:           &pr.UpdatedAt,
+		MergedAt:            pr.MergedAt,
+		Version:             pr.Version,
+	}
+}
+
+// setETag exposes the pull request version as a strong ETag, e.g. "3".
+func setETag(w http.ResponseWriter, pr *domain.PullRequest) {
+	w.Header().Set("ETag", strconv.Quote(strconv.Itoa(pr.Version)))
+}
+
+// parseIfMatch returns the version required by the If-Match header, or 0 if
+// the header is absent or "*".
+func parseIfMatch(r *http.Request) (int, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return 0, errInvalidIfMatch
+	}
+
+	version, err := strconv.Atoi(value[1 : len(value)-1])
+	if err != nil || version < 1 {
+		return 0, errInvalidIfMatch
+	}
+
+	return version, nil
+}
+
+func mapReviewerAssignmentsToDTO(assignments []domain.ReviewerAssignment) []*dto.ReviewerAssignment {
+	if len(assignments) == 0 {
+		return nil
+	}
+
+	result := make([]*dto.ReviewerAssignment, 0, len(assignments))
+	for _, a := range assignments {
+		result = append(result, &dto.ReviewerAssignment{
+			ReviewerID: a.ReviewerID,
+			AssignedAt: a.AssignedAt,
+		})
 	}
+	return result
 }
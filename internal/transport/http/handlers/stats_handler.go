@@ -1,11 +1,17 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
+	"time"
 
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
 	"github.com/Raisondetr3/Avito-test-assignment/internal/transport/http/middleware"
 )
 
+const dateLayout = "2006-01-02"
+
 type StatsHandler struct {
 	statsService StatsService
 }
@@ -17,7 +23,29 @@ func NewStatsHandler(statsService StatsService) *StatsHandler {
 }
 
 func (h *StatsHandler) GetStatistics(w http.ResponseWriter, r *http.Request) {
-	stats, err := h.statsService.GetStatistics(r.Context())
+	filter, err := parseStatsFilter(r.URL.Query())
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	stats, err := h.statsService.GetStatistics(r.Context(), filter)
+	if err != nil {
+		middleware.WriteError(w, err)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, stats)
+}
+
+func (h *StatsHandler) GetCycleTime(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseStatsFilter(r.URL.Query())
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	stats, err := h.statsService.GetCycleTime(r.Context(), filter)
 	if err != nil {
 		middleware.WriteError(w, err)
 		return
@@ -25,3 +53,59 @@ func (h *StatsHandler) GetStatistics(w http.ResponseWriter, r *http.Request) {
 
 	middleware.WriteJSON(w, http.StatusOK, stats)
 }
+
+func (h *StatsHandler) GetWorkload(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseStatsFilter(r.URL.Query())
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	stats, err := h.statsService.GetWorkload(r.Context(), filter)
+	if err != nil {
+		middleware.WriteError(w, err)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, stats)
+}
+
+func parseStatsFilter(query url.Values) (domain.StatsFilter, error) {
+	filter := domain.StatsFilter{
+		TeamName: query.Get("team_name"),
+	}
+
+	from, err := parseTimeParam(query, "from")
+	if err != nil {
+		return domain.StatsFilter{}, err
+	}
+	filter.From = from
+
+	to, err := parseTimeParam(query, "to")
+	if err != nil {
+		return domain.StatsFilter{}, err
+	}
+	filter.To = to
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return domain.StatsFilter{}, fmt.Errorf("from must be before to")
+	}
+
+	return filter, nil
+}
+
+func parseTimeParam(query url.Values, name string) (*time.Time, error) {
+	raw := query.Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, dateLayout} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			t = t.UTC()
+			return &t, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s must be an RFC 3339 timestamp or a YYYY-MM-DD date", name)
+}
@@ -1,8 +1,14 @@
 package handlers
 
 import (
+	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
 	"github.com/Raisondetr3/Avito-test-assignment/internal/transport/http/dto"
@@ -12,15 +18,30 @@ import (
 type TeamHandler struct {
 	teamService             TeamService
 	bulkDeactivationService BulkDeactivationService
+	deletionService         DeletionService
+	rosterService           RosterService
 }
 
-func NewTeamHandler(teamService TeamService, bulkDeactivationService BulkDeactivationService) *TeamHandler {
+func NewTeamHandler(
+	teamService TeamService,
+	bulkDeactivationService BulkDeactivationService,
+	deletionService DeletionService,
+	rosterService RosterService,
+) *TeamHandler {
 	return &TeamHandler{
 		teamService:             teamService,
 		bulkDeactivationService: bulkDeactivationService,
+		deletionService:         deletionService,
+		rosterService:           rosterService,
 	}
 }
 
+// maxRosterMemory is how much of an uploaded roster is kept in memory; the
+// rest is spooled to a temporary file.
+const maxRosterMemory = 10 << 20
+
+var rosterColumns = []string{"team_name", "user_id", "username", "is_active"}
+
 func (h *TeamHandler) CreateTeam(w http.ResponseWriter, r *http.Request) {
 	var req dto.CreateTeamRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -76,19 +97,219 @@ func (h *TeamHandler) BulkDeactivateUsers(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	result, err := h.bulkDeactivationService.DeactivateUsersAndReassignPRs(r.Context(), req.TeamName, req.UserIDs)
+	result, err := h.bulkDeactivationService.DeactivateUsersAndReassignPRs(r.Context(), req.TeamName, req.UserIDs,
+		domain.BulkDeactivationOptions{BestEffort: req.BestEffort},
+	)
+	if err != nil {
+		middleware.WriteError(w, err)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, mapBulkDeactivationResultToDTO(result))
+}
+
+func (h *TeamHandler) DeleteTeam(w http.ResponseWriter, r *http.Request) {
+	var req dto.TeamNameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	if req.TeamName == "" {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "team_name is required")
+		return
+	}
+
+	result, err := h.deletionService.DeleteTeam(r.Context(), req.TeamName)
+	if err != nil {
+		middleware.WriteError(w, err)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, mapBulkDeactivationResultToDTO(result))
+}
+
+func (h *TeamHandler) RestoreTeam(w http.ResponseWriter, r *http.Request) {
+	var req dto.TeamNameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	if req.TeamName == "" {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "team_name is required")
+		return
+	}
+
+	team, err := h.deletionService.RestoreTeam(r.Context(), req.TeamName)
+	if err != nil {
+		middleware.WriteError(w, err)
+		return
+	}
+
+	response := dto.TeamResponse{
+		Team: mapTeamToDTO(team),
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, response)
+}
+
+// ImportRoster reads a roster CSV from the "file" field of a multipart form.
+// With dry_run=true it only reports what the roster would change.
+func (h *TeamHandler) ImportRoster(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(maxRosterMemory); err != nil {
+		if middleware.IsBodyTooLarge(err) {
+			middleware.WriteBodyTooLarge(w)
+			return
+		}
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "Expected a multipart/form-data body")
+		return
+	}
+	defer r.MultipartForm.RemoveAll() //nolint:errcheck
+
+	dryRun := false
+	if value := r.FormValue("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "dry_run must be true or false")
+			return
+		}
+		dryRun = parsed
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "file is required")
+		return
+	}
+	defer file.Close()
+
+	entries, err := parseRosterCSV(file)
+	if err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_ROSTER", err.Error())
+		return
+	}
+
+	result, err := h.rosterService.ImportRoster(r.Context(), entries, dryRun)
 	if err != nil {
 		middleware.WriteError(w, err)
 		return
 	}
 
-	response := dto.BulkDeactivateResponse{
+	middleware.WriteJSON(w, http.StatusOK, mapRosterImportResultToDTO(result))
+}
+
+// parseRosterCSV reads a roster with a header row naming rosterColumns in any
+// order. Other columns and a UTF-8 byte order mark are ignored.
+func parseRosterCSV(r io.Reader) ([]domain.RosterEntry, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range rosterColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %s", name)
+		}
+	}
+
+	entries := make([]domain.RosterEntry, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			return strings.TrimSpace(record[columns[name]])
+		}
+
+		isActive, err := strconv.ParseBool(field("is_active"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: is_active must be true or false, got %q", line, field("is_active"))
+		}
+
+		entries = append(entries, domain.RosterEntry{
+			TeamName: field("team_name"),
+			UserID:   field("user_id"),
+			Username: field("username"),
+			IsActive: isActive,
+		})
+	}
+
+	return entries, nil
+}
+
+func mapRosterImportResultToDTO(result *domain.RosterImportResult) dto.RosterImportResponse {
+	diff := result.Diff
+	response := dto.RosterImportResponse{
+		DryRun:        result.DryRun,
+		NewTeams:      diff.NewTeams,
+		NewUsers:      mapRosterUsersToDTO(diff.NewUsers),
+		MovedUsers:    make([]dto.RosterMove, 0, len(diff.MovedUsers)),
+		UpdatedUsers:  mapRosterUsersToDTO(diff.UpdatedUsers),
+		Deactivations: make([]dto.RosterDeactivation, 0, len(diff.Deactivations)),
+		ReassignedPRs: []dto.ReassignedPRInfo{},
+		SkippedPRs:    []dto.SkippedPRInfo{},
+	}
+
+	for _, move := range diff.MovedUsers {
+		response.MovedUsers = append(response.MovedUsers, dto.RosterMove{
+			UserID:   move.UserID,
+			FromTeam: move.FromTeam,
+			ToTeam:   move.ToTeam,
+		})
+	}
+
+	for _, d := range diff.Deactivations {
+		response.Deactivations = append(response.Deactivations, dto.RosterDeactivation{
+			UserID:   d.UserID,
+			TeamName: d.TeamName,
+			Reason:   d.Reason,
+		})
+	}
+
+	if result.Deactivation != nil {
+		response.ReassignedPRs = mapReassignedPRsToDTO(result.Deactivation.ReassignedPRs)
+		response.SkippedPRs = mapSkippedPRsToDTO(result.Deactivation.SkippedPRs)
+	}
+
+	return response
+}
+
+func mapRosterUsersToDTO(users []*domain.User) []dto.RosterUser {
+	result := make([]dto.RosterUser, 0, len(users))
+	for _, user := range users {
+		result = append(result, dto.RosterUser{
+			UserID:   user.UserID,
+			Username: user.Username,
+			TeamName: user.TeamName,
+			IsActive: user.IsActive,
+		})
+	}
+	return result
+}
+
+func mapBulkDeactivationResultToDTO(result *domain.BulkDeactivationResult) dto.BulkDeactivateResponse {
+	return dto.BulkDeactivateResponse{
 		DeactivatedUsers: result.DeactivatedUsers,
 		ReassignedPRs:    mapReassignedPRsToDTO(result.ReassignedPRs),
 		SkippedPRs:       mapSkippedPRsToDTO(result.SkippedPRs),
 	}
-
-	middleware.WriteJSON(w, http.StatusOK, response)
 }
 
 func mapReassignedPRsToDTO(prs []domain.ReassignedPR) []dto.ReassignedPRInfo {
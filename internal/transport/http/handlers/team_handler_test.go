@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+)
+
+func TestParseRosterCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		want    []domain.RosterEntry
+		wantErr string
+	}{
+		{
+			name: "columns in any order",
+			csv:  "username,is_active,user_id,team_name\nAlice,true,u1,backend\nBob,false,u2,frontend\n",
+			want: []domain.RosterEntry{
+				{TeamName: "backend", UserID: "u1", Username: "Alice", IsActive: true},
+				{TeamName: "frontend", UserID: "u2", Username: "Bob", IsActive: false},
+			},
+		},
+		{
+			name: "byte order mark and extra columns",
+			csv:  "\ufeffteam_name,user_id,username,is_active,email\nbackend,u1,Alice,1,alice@example.com\n",
+			want: []domain.RosterEntry{{TeamName: "backend", UserID: "u1", Username: "Alice", IsActive: true}},
+		},
+		{
+			name: "header only",
+			csv:  "team_name,user_id,username,is_active\n",
+			want: []domain.RosterEntry{},
+		},
+		{name: "empty file", csv: "", wantErr: "file is empty"},
+		{
+			name:    "missing column",
+			csv:     "team_name,user_id,is_active\nbackend,u1,true\n",
+			wantErr: "missing column username",
+		},
+		{
+			name:    "bad is_active",
+			csv:     "team_name,user_id,username,is_active\nbackend,u1,Alice,true\nbackend,u2,Bob,yes\n",
+			wantErr: `line 3: is_active must be true or false, got "yes"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRosterCSV(strings.NewReader(tt.csv))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+type stubRosterService struct {
+	dryRun bool
+}
+
+func (s *stubRosterService) ImportRoster(_ context.Context, _ []domain.RosterEntry, dryRun bool) (*domain.RosterImportResult, error) {
+	s.dryRun = dryRun
+	return &domain.RosterImportResult{DryRun: dryRun, Diff: &domain.RosterDiff{}}, nil
+}
+
+func TestImportRosterDryRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		dryRun     string
+		wantStatus int
+		wantDryRun bool
+	}{
+		{name: "not set", wantStatus: http.StatusOK},
+		{name: "true", dryRun: "true", wantStatus: http.StatusOK, wantDryRun: true},
+		{name: "false", dryRun: "false", wantStatus: http.StatusOK},
+		{name: "invalid", dryRun: "maybe", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body bytes.Buffer
+			form := multipart.NewWriter(&body)
+			if tt.dryRun != "" {
+				if err := form.WriteField("dry_run", tt.dryRun); err != nil {
+					t.Fatalf("Failed to write form: %v", err)
+				}
+			}
+			file, err := form.CreateFormFile("file", "roster.csv")
+			if err != nil {
+				t.Fatalf("Failed to write form: %v", err)
+			}
+			file.Write([]byte("team_name,user_id,username,is_active\nbackend,u1,Alice,true\n")) //nolint:errcheck
+			form.Close()
+
+			req := httptest.NewRequest(http.MethodPost, "/team/import", &body)
+			req.Header.Set("Content-Type", form.FormDataContentType())
+			w := httptest.NewRecorder()
+
+			roster := &stubRosterService{}
+			NewTeamHandler(nil, nil, nil, roster).ImportRoster(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if roster.dryRun != tt.wantDryRun {
+				t.Fatalf("Expected dry run %v, got %v", tt.wantDryRun, roster.dryRun)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
 	"github.com/Raisondetr3/Avito-test-assignment/internal/transport/http/dto"
@@ -10,14 +11,16 @@ import (
 )
 
 type UserHandler struct {
-	userService UserService
-	prService   PRService
+	userService     UserService
+	prService       PRService
+	deletionService DeletionService
 }
 
-func NewUserHandler(userService UserService, prService PRService) *UserHandler {
+func NewUserHandler(userService UserService, prService PRService, deletionService DeletionService) *UserHandler {
 	return &UserHandler{
-		userService: userService,
-		prService:   prService,
+		userService:     userService,
+		prService:       prService,
+		deletionService: deletionService,
 	}
 }
 
@@ -72,6 +75,118 @@ func (h *UserHandler) GetReview(w http.ResponseWriter, r *http.Request) {
 	middleware.WriteJSON(w, http.StatusOK, response)
 }
 
+func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "user_id is required")
+		return
+	}
+
+	details, err := h.userService.GetDetails(r.Context(), userID)
+	if err != nil {
+		middleware.WriteError(w, err)
+		return
+	}
+
+	response := dto.UserDetailsResponse{
+		User: mapUserDetailsToDTO(details),
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := domain.UserFilter{
+		TeamName: query.Get("team_name"),
+	}
+
+	if raw := query.Get("is_active"); raw != "" {
+		isActive, err := strconv.ParseBool(raw)
+		if err != nil {
+			middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "is_active must be a boolean")
+			return
+		}
+		filter.IsActive = &isActive
+	}
+
+	users, err := h.userService.ListUsers(r.Context(), filter)
+	if err != nil {
+		middleware.WriteError(w, err)
+		return
+	}
+
+	userDTOs := make([]*dto.UserDetails, 0, len(users))
+	for _, details := range users {
+		userDTOs = append(userDTOs, mapUserDetailsToDTO(details))
+	}
+
+	response := dto.ListUsersResponse{
+		Users: userDTOs,
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	var req dto.UserIDRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	if req.UserID == "" {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "user_id is required")
+		return
+	}
+
+	result, err := h.deletionService.DeleteUser(r.Context(), req.UserID)
+	if err != nil {
+		middleware.WriteError(w, err)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, mapBulkDeactivationResultToDTO(result))
+}
+
+func (h *UserHandler) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	var req dto.UserIDRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	if req.UserID == "" {
+		middleware.WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "user_id is required")
+		return
+	}
+
+	user, err := h.deletionService.RestoreUser(r.Context(), req.UserID)
+	if err != nil {
+		middleware.WriteError(w, err)
+		return
+	}
+
+	response := dto.UserResponse{
+		User: mapUserToDTO(user),
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, response)
+}
+
+func mapUserDetailsToDTO(details *domain.UserDetails) *dto.UserDetails {
+	return &dto.UserDetails{
+		UserID:          details.User.UserID,
+		Username:        details.User.Username,
+		TeamName:        details.User.TeamName,
+		IsActive:        details.User.IsActive,
+		OpenReviewCount: details.OpenReviewCount,
+		CreatedAt:       details.User.CreatedAt,
+		UpdatedAt:       details.User.UpdatedAt,
+	}
+}
+
 func mapUserToDTO(user *domain.User) *dto.User {
 	return &dto.User{
 		UserID:   user.UserID,
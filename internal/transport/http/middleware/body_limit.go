@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	stderrors "errors"
+	"net/http"
+)
+
+// BodyLimit caps the size of request bodies. Routes listed in routes, keyed by
+// route template, get their own limit; all others get defaultLimit.
+type BodyLimit struct {
+	defaultLimit int64
+	routes       map[string]int64
+}
+
+func NewBodyLimit(defaultLimit int64, routes map[string]int64) *BodyLimit {
+	return &BodyLimit{defaultLimit: defaultLimit, routes: routes}
+}
+
+// Middleware rejects requests whose Content-Length is over the limit and
+// makes reads past the limit fail with *http.MaxBytesError, which handlers
+// report with WriteBodyTooLarge.
+func (l *BodyLimit) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := l.defaultLimit
+		if routeLimit, ok := l.routes[routeTemplate(r)]; ok {
+			limit = routeLimit
+		}
+
+		if r.ContentLength > limit {
+			WriteBodyTooLarge(w)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// IsBodyTooLarge reports whether err comes from reading past the body limit.
+func IsBodyTooLarge(err error) bool {
+	var tooLarge *http.MaxBytesError
+	return stderrors.As(err, &tooLarge)
+}
+
+func WriteBodyTooLarge(w http.ResponseWriter) {
+	WriteJSONError(w, http.StatusRequestEntityTooLarge, "INVALID_REQUEST", "Request body is too large")
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/logger"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/tlsconfig"
+)
+
+// ClientIdentity records the subject of a verified client certificate as the
+// caller of the request. Plain HTTP requests pass through unchanged.
+func ClientIdentity(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+			caller := tlsconfig.Identity(r.TLS.VerifiedChains[0][0])
+			r = r.WithContext(logger.WithCaller(r.Context(), caller))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
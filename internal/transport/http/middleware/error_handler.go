@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"encoding/json"
+	stderrors "errors"
+	"log/slog"
 	"net/http"
 
 	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
@@ -9,9 +11,18 @@ import (
 )
 
 func WriteError(w http.ResponseWriter, err error) {
-	appErr, ok := err.(*errors.AppError)
-	if !ok {
-		WriteJSONError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	var appErr *errors.AppError
+	if !stderrors.As(err, &appErr) {
+		requestID := w.Header().Get(RequestIDHeader)
+		slog.Error("internal error",
+			slog.String("request_id", requestID),
+			slog.String("error", err.Error()),
+		)
+		writeErrorResponse(w, http.StatusInternalServerError, dto.ErrorDetail{
+			Code:      "INTERNAL_ERROR",
+			Message:   "internal server error",
+			RequestID: requestID,
+		})
 		return
 	}
 
@@ -20,14 +31,18 @@ func WriteError(w http.ResponseWriter, err error) {
 }
 
 func WriteJSONError(w http.ResponseWriter, statusCode int, code, message string) {
+	writeErrorResponse(w, statusCode, dto.ErrorDetail{
+		Code:    code,
+		Message: message,
+	})
+}
+
+func writeErrorResponse(w http.ResponseWriter, statusCode int, detail dto.ErrorDetail) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 
 	response := dto.ErrorResponse{
-		Error: dto.ErrorDetail{
-			Code:    code,
-			Message: message,
-		},
+		Error: detail,
 	}
 
 	_ = json.NewEncoder(w).Encode(response) //nolint:errcheck
@@ -47,6 +62,18 @@ func getStatusCode(code errors.ErrorCode) int {
 		return http.StatusConflict
 	case errors.ErrCodeNotFound:
 		return http.StatusNotFound
+	case errors.ErrCodeConflict:
+		return http.StatusConflict
+	case errors.ErrCodeIdempotencyConflict:
+		return http.StatusConflict
+	case errors.ErrCodeInvalidSnapshot:
+		return http.StatusBadRequest
+	case errors.ErrCodeImportConflict:
+		return http.StatusConflict
+	case errors.ErrCodeInvalidRoster:
+		return http.StatusBadRequest
+	case errors.ErrCodeRateLimited:
+		return http.StatusTooManyRequests
 	default:
 		return http.StatusInternalServerError
 	}
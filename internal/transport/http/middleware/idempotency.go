@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/idempotency"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+	idempotencyStoreTimeout  = 5 * time.Second
+)
+
+type Idempotency struct {
+	store       idempotency.Store
+	lockTimeout time.Duration
+}
+
+// NewIdempotency creates the middleware. lockTimeout is how long a key stays
+// locked by a request that never completed before another request may reuse it.
+func NewIdempotency(store idempotency.Store, lockTimeout time.Duration) *Idempotency {
+	return &Idempotency{store: store, lockTimeout: lockTimeout}
+}
+
+// Middleware makes POST requests carrying an Idempotency-Key header safe to
+// retry: the first response is stored and replayed for later requests with
+// the same key and body. Keys are scoped to the caller, identified the same
+// way as for rate limiting. Server errors are not stored, so those requests
+// can be retried with the same key. Bodies are read into memory to be hashed,
+// so BodyLimit must run first. A nil Idempotency disables the feature.
+func (m *Idempotency) Middleware(next http.Handler) http.Handler {
+	if m == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if r.Method != http.MethodPost || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !isValidIdempotencyKey(key) {
+			WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST",
+				"Idempotency-Key must be 1-255 printable ASCII characters")
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if IsBodyTooLarge(err) {
+			WriteBodyTooLarge(w)
+			return
+		}
+		if err != nil {
+			WriteJSONError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := r.Context()
+		scope := callerKey(r)
+		requestHash := idempotency.HashRequest(r.Method, routeTemplate(r), body)
+
+		record, err := m.store.Reserve(ctx, scope, key, requestHash, m.lockTimeout)
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+
+		if record != nil {
+			switch {
+			case record.RequestHash != requestHash:
+				WriteError(w, errors.ErrIdempotencyKeyReused(key))
+			case !record.Completed:
+				WriteError(w, errors.ErrIdempotencyInProgress(key))
+			default:
+				replay(w, record)
+			}
+			return
+		}
+
+		rec := newResponseCapture(w)
+		next.ServeHTTP(rec, r)
+
+		// The client may already be gone; the outcome must still be recorded.
+		storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), idempotencyStoreTimeout)
+		defer cancel()
+
+		if rec.status >= http.StatusInternalServerError {
+			if err := m.store.Release(storeCtx, scope, key); err != nil {
+				slog.ErrorContext(ctx, "failed to release idempotency key", slog.String("error", err.Error()))
+			}
+			return
+		}
+
+		err = m.store.Complete(storeCtx, scope, key, idempotency.Response{
+			StatusCode:  rec.status,
+			ContentType: rec.Header().Get("Content-Type"),
+			ETag:        rec.Header().Get("ETag"),
+			Body:        rec.body.Bytes(),
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to store idempotent response", slog.String("error", err.Error()))
+		}
+	})
+}
+
+func replay(w http.ResponseWriter, record *idempotency.Record) {
+	if record.ContentType != "" {
+		w.Header().Set("Content-Type", record.ContentType)
+	}
+	if record.ETag != "" {
+		w.Header().Set("ETag", record.ETag)
+	}
+	w.Header().Set(IdempotentReplayedHeader, strconv.FormatBool(true))
+	w.WriteHeader(record.StatusCode)
+	_, _ = w.Write(record.Body) //nolint:errcheck
+}
+
+func isValidIdempotencyKey(key string) bool {
+	return len(key) <= maxIdempotencyKeyLength && isPrintableASCII(key)
+}
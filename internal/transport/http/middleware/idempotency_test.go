@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/repository/memory"
+)
+
+func TestIdempotencyBodyLimit(t *testing.T) {
+	bodyLimit := NewBodyLimit(1<<10, map[string]int64{"/upload": 1 << 20})
+	idempotency := NewIdempotency(memory.NewIdempotencyStore(), time.Minute)
+
+	r := mux.NewRouter()
+	r.Use(bodyLimit.Middleware, idempotency.Middleware)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); IsBodyTooLarge(err) {
+			WriteBodyTooLarge(w)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}
+	r.HandleFunc("/team/add", handler).Methods(http.MethodPost)
+	r.HandleFunc("/upload", handler).Methods(http.MethodPost)
+
+	tests := []struct {
+		name          string
+		path          string
+		key           string
+		size          int
+		unknownLength bool
+		want          int
+	}{
+		{name: "within the default limit", path: "/team/add", key: "small", size: 1 << 10, want: http.StatusCreated},
+		{name: "over the default limit", path: "/team/add", key: "large", size: 1<<10 + 1, want: http.StatusRequestEntityTooLarge},
+		{name: "over the limit without a key", path: "/team/add", size: 1<<10 + 1, want: http.StatusRequestEntityTooLarge},
+		{
+			name: "over the limit without a length", path: "/team/add", key: "chunked", size: 1<<10 + 1,
+			unknownLength: true, want: http.StatusRequestEntityTooLarge,
+		},
+		{name: "route with a larger limit", path: "/upload", key: "upload", size: 1 << 19, want: http.StatusCreated},
+		{name: "over the route limit", path: "/upload", key: "huge", size: 1<<20 + 1, want: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(strings.Repeat("a", tt.size)))
+			if tt.unknownLength {
+				req.ContentLength = -1
+			}
+			if tt.key != "" {
+				req.Header.Set(IdempotencyKeyHeader, tt.key)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Fatalf("Expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestIdempotencyReplayKeepsETag(t *testing.T) {
+	idempotency := NewIdempotency(memory.NewIdempotencyStore(), time.Minute)
+
+	calls := 0
+	r := mux.NewRouter()
+	r.Use(idempotency.Middleware)
+	r.HandleFunc("/pullRequest/merge", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("ETag", `"2"`)
+		WriteJSON(w, http.StatusOK, map[string]string{"status": "MERGED"})
+	}).Methods(http.MethodPost)
+
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/merge", strings.NewReader(`{"pull_request_id":"pr-1"}`))
+		req.Header.Set(IdempotencyKeyHeader, "merge-1")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	first := send()
+	replay := send()
+
+	if calls != 1 {
+		t.Fatalf("Expected the handler to run once, ran %d times", calls)
+	}
+	if replay.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatal("Expected Idempotent-Replayed header")
+	}
+	if got := replay.Header().Get("ETag"); got != first.Header().Get("ETag") {
+		t.Fatalf("Expected ETag %q, got %q", first.Header().Get("ETag"), got)
+	}
+	if replay.Body.String() != first.Body.String() {
+		t.Fatalf("Expected identical body, got %s", replay.Body.String())
+	}
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/logger"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/metrics"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/ratelimit"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+type RateLimiter struct {
+	limiter ratelimit.Limiter
+	policy  atomic.Pointer[ratelimit.Policy]
+}
+
+func NewRateLimiter(limiter ratelimit.Limiter, policy ratelimit.Policy) *RateLimiter {
+	l := &RateLimiter{limiter: limiter}
+	l.SetPolicy(policy)
+	return l
+}
+
+// SetPolicy replaces the limits and API keys. It is safe to call while
+// requests are being served; existing buckets are kept.
+func (l *RateLimiter) SetPolicy(policy ratelimit.Policy) {
+	l.policy.Store(&policy)
+}
+
+// Middleware enforces the limit of the matched route separately for every
+// caller. A nil RateLimiter lets all requests through. If the limiter backend
+// fails, the request is allowed rather than rejected.
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		policy := l.policy.Load()
+		route := routeTemplate(r)
+		limit := policy.For(route)
+		if limit.Unlimited() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		decision, err := l.limiter.Allow(r.Context(), route+" "+bucketKey(r, *policy), limit)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "rate limiter failed", slog.String("error", err.Error()))
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !decision.Allowed {
+			metrics.RateLimitedTotal.WithLabelValues(route).Inc()
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(decision.RetryAfter.Seconds()))))
+			WriteError(w, errors.ErrRateLimited())
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// bucketKey identifies who a request counts against. Unlike callerKey it
+// only trusts configured API keys: otherwise a client could send a new key
+// with every request and never run out of tokens.
+func bucketKey(r *http.Request, policy ratelimit.Policy) string {
+	if apiKey := r.Header.Get(APIKeyHeader); apiKey != "" && policy.KnownAPIKey(apiKey) {
+		return apiKeyScope(apiKey)
+	}
+	return identityKey(r)
+}
+
+// callerKey identifies who a request comes from: the API key if one is
+// sent, then the client certificate identity, then the client IP.
+func callerKey(r *http.Request) string {
+	if apiKey := r.Header.Get(APIKeyHeader); apiKey != "" {
+		return apiKeyScope(apiKey)
+	}
+	return identityKey(r)
+}
+
+func apiKeyScope(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return "key:" + hex.EncodeToString(sum[:])
+}
+
+// identityKey identifies a request by client certificate or, without one,
+// by client IP.
+func identityKey(r *http.Request) string {
+	if caller := logger.CallerFromContext(r.Context()); caller != "" {
+		return "caller:" + caller
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/logger"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 128
+
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !isValidRequestID(requestID) {
+			requestID = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, requestID)
+		ctx := logger.WithRequestID(r.Context(), requestID)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b) //nolint:errcheck
+	return hex.EncodeToString(b)
+}
+
+func isValidRequestID(id string) bool {
+	return len(id) <= maxRequestIDLength && isPrintableASCII(id)
+}
+
+// isPrintableASCII reports whether s is non-empty and contains only visible
+// ASCII characters, which makes it safe to echo in headers and logs.
+func isPrintableASCII(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
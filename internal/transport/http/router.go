@@ -4,32 +4,71 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 
+	"github.com/Raisondetr3/Avito-test-assignment/internal/metrics"
 	"github.com/Raisondetr3/Avito-test-assignment/internal/transport/http/handlers"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/transport/http/middleware"
 )
 
-func NewRouter(teamHandler *handlers.TeamHandler, userHandler *handlers.UserHandler, prHandler *handlers.PRHandler, statsHandler *handlers.StatsHandler) *mux.Router {
+const (
+	maxRequestBodySize = 1 << 20
+	// maxUploadBodySize applies to roster and snapshot uploads.
+	maxUploadBodySize = 32 << 20
+)
+
+func NewRouter(
+	teamHandler *handlers.TeamHandler,
+	userHandler *handlers.UserHandler,
+	prHandler *handlers.PRHandler,
+	statsHandler *handlers.StatsHandler,
+	healthHandler *handlers.HealthHandler,
+	adminHandler *handlers.AdminHandler,
+	rateLimiter *middleware.RateLimiter,
+	idempotency *middleware.Idempotency,
+) *mux.Router {
 	r := mux.NewRouter()
+	r.Use(otelmux.Middleware("pr-reviewer-service"), middleware.RequestID, middleware.ClientIdentity, middleware.AccessLog, middleware.Metrics)
 
-	r.HandleFunc("/team/add", teamHandler.CreateTeam).Methods(http.MethodPost)
-	r.HandleFunc("/team/get", teamHandler.GetTeam).Methods(http.MethodGet)
-	r.HandleFunc("/team/deactivateUsers", teamHandler.BulkDeactivateUsers).Methods(http.MethodPost)
+	r.HandleFunc("/health/live", healthHandler.Live).Methods(http.MethodGet)
+	r.HandleFunc("/health/ready", healthHandler.Ready).Methods(http.MethodGet)
+	r.Handle("/metrics", metrics.Handler()).Methods(http.MethodGet)
 
-	r.HandleFunc("/users/setIsActive", userHandler.SetActive).Methods(http.MethodPost)
-	r.HandleFunc("/users/getReview", userHandler.GetReview).Methods(http.MethodGet)
+	bodyLimit := middleware.NewBodyLimit(maxRequestBodySize, map[string]int64{
+		"/team/import":  maxUploadBodySize,
+		"/admin/import": maxUploadBodySize,
+	})
 
-	r.HandleFunc("/pullRequest/create", prHandler.CreatePR).Methods(http.MethodPost)
-	r.HandleFunc("/pullRequest/merge", prHandler.MergePR).Methods(http.MethodPost)
-	r.HandleFunc("/pullRequest/reassign", prHandler.ReassignPR).Methods(http.MethodPost)
+	api := r.NewRoute().Subrouter()
+	api.Use(rateLimiter.Middleware, bodyLimit.Middleware, idempotency.Middleware)
 
-	r.HandleFunc("/stats", statsHandler.GetStatistics).Methods(http.MethodGet)
+	api.HandleFunc("/team/add", teamHandler.CreateTeam).Methods(http.MethodPost)
+	api.HandleFunc("/team/get", teamHandler.GetTeam).Methods(http.MethodGet)
+	api.HandleFunc("/team/deactivateUsers", teamHandler.BulkDeactivateUsers).Methods(http.MethodPost)
+	api.HandleFunc("/team/delete", teamHandler.DeleteTeam).Methods(http.MethodPost)
+	api.HandleFunc("/team/restore", teamHandler.RestoreTeam).Methods(http.MethodPost)
+	api.HandleFunc("/team/import", teamHandler.ImportRoster).Methods(http.MethodPost)
 
-	r.HandleFunc("/health", healthCheck).Methods(http.MethodGet)
+	api.HandleFunc("/users/setIsActive", userHandler.SetActive).Methods(http.MethodPost)
+	api.HandleFunc("/users/getReview", userHandler.GetReview).Methods(http.MethodGet)
+	api.HandleFunc("/users/get", userHandler.GetUser).Methods(http.MethodGet)
+	api.HandleFunc("/users/list", userHandler.ListUsers).Methods(http.MethodGet)
+	api.HandleFunc("/users/delete", userHandler.DeleteUser).Methods(http.MethodPost)
+	api.HandleFunc("/users/restore", userHandler.RestoreUser).Methods(http.MethodPost)
 
-	return r
-}
+	api.HandleFunc("/pullRequest/create", prHandler.CreatePR).Methods(http.MethodPost)
+	api.HandleFunc("/pullRequest/merge", prHandler.MergePR).Methods(http.MethodPost)
+	api.HandleFunc("/pullRequest/reassign", prHandler.ReassignPR).Methods(http.MethodPost)
+	api.HandleFunc("/pullRequest/get", prHandler.GetPR).Methods(http.MethodGet)
+	api.HandleFunc("/pullRequest/delete", prHandler.DeletePR).Methods(http.MethodPost)
+	api.HandleFunc("/pullRequest/restore", prHandler.RestorePR).Methods(http.MethodPost)
 
-func healthCheck(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write([]byte("OK")) //nolint:errcheck
+	api.HandleFunc("/stats", statsHandler.GetStatistics).Methods(http.MethodGet)
+	api.HandleFunc("/stats/cycleTime", statsHandler.GetCycleTime).Methods(http.MethodGet)
+	api.HandleFunc("/stats/workload", statsHandler.GetWorkload).Methods(http.MethodGet)
+
+	api.HandleFunc("/admin/export", adminHandler.Export).Methods(http.MethodGet)
+	api.HandleFunc("/admin/import", adminHandler.Import).Methods(http.MethodPost)
+
+	return r
 }
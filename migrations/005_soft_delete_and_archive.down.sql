@@ -0,0 +1,18 @@
+INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at, updated_at, merged_at, version)
+SELECT pull_request_id, pull_request_name, author_id, status, created_at, updated_at, merged_at, version
+FROM pull_requests_archive
+ON CONFLICT (pull_request_id) DO NOTHING;
+
+INSERT INTO pr_reviewers (pull_request_id, reviewer_id, assigned_at)
+SELECT pull_request_id, reviewer_id, assigned_at
+FROM pr_reviewers_archive
+ON CONFLICT (pull_request_id, reviewer_id) DO NOTHING;
+
+DROP TABLE IF EXISTS pr_reviewers_archive;
+DROP TABLE IF EXISTS pull_requests_archive;
+
+DROP INDEX IF EXISTS idx_pr_merged_at;
+
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE teams DROP COLUMN IF EXISTS deleted_at;
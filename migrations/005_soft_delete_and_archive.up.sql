@@ -0,0 +1,46 @@
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_pr_merged_at ON pull_requests(merged_at) WHERE status = 'MERGED';
+
+CREATE TABLE IF NOT EXISTS pull_requests_archive (
+    pull_request_id VARCHAR(255) PRIMARY KEY,
+    pull_request_name VARCHAR(255) NOT NULL,
+    author_id VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    merged_at TIMESTAMP,
+    version INTEGER NOT NULL,
+    deleted_at TIMESTAMP,
+    archived_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_pr_archive_author FOREIGN KEY (author_id)
+        REFERENCES users(user_id)
+        ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_archive_author ON pull_requests_archive(author_id);
+CREATE INDEX IF NOT EXISTS idx_pr_archive_merged_at ON pull_requests_archive(merged_at);
+
+CREATE TABLE IF NOT EXISTS pr_reviewers_archive (
+    pull_request_id VARCHAR(255) NOT NULL,
+    reviewer_id VARCHAR(255) NOT NULL,
+    assigned_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (pull_request_id, reviewer_id),
+    CONSTRAINT fk_pr_reviewers_archive_pr FOREIGN KEY (pull_request_id)
+        REFERENCES pull_requests_archive(pull_request_id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_pr_reviewers_archive_user FOREIGN KEY (reviewer_id)
+        REFERENCES users(user_id)
+        ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_archive_reviewer ON pr_reviewers_archive(reviewer_id);
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_archive_assigned_at ON pr_reviewers_archive(assigned_at);
+
+COMMENT ON COLUMN teams.deleted_at IS 'Set when the team is soft-deleted; deleted rows are hidden from every query';
+COMMENT ON COLUMN users.deleted_at IS 'Set when the user or their team is soft-deleted';
+COMMENT ON COLUMN pull_requests.deleted_at IS 'Set when the pull request is soft-deleted';
+COMMENT ON TABLE pull_requests_archive IS 'Merged pull requests moved out of pull_requests by the archival job; still counted in statistics';
+COMMENT ON TABLE pr_reviewers_archive IS 'Reviewer assignments of archived pull requests';
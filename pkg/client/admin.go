@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+const ndjsonContentType = "application/x-ndjson"
+
+// Export returns a snapshot of all teams, users and pull requests.
+func (c *Client) Export(ctx context.Context, opts ...CallOption) (*Snapshot, error) {
+	var snapshot Snapshot
+	if err := c.get(ctx, "/admin/export", url.Values{"format": {"json"}}, &snapshot, opts); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// ExportNDJSON copies the snapshot to w, one record per line. It is not
+// retried once writing to w has started.
+func (c *Client) ExportNDJSON(ctx context.Context, w io.Writer, opts ...CallOption) error {
+	return c.get(ctx, "/admin/export", url.Values{"format": {"ndjson"}}, w, opts)
+}
+
+// Import loads a snapshot produced by Export; policy decides what happens
+// to records that already exist.
+func (c *Client) Import(ctx context.Context, snapshot *Snapshot, policy ConflictPolicy, opts ...CallOption) (*ImportResponse, error) {
+	req := newRequest(http.MethodPost, "/admin/import", opts)
+	req.query = url.Values{"on_conflict": {string(policy)}}
+	if err := req.setJSON(snapshot); err != nil {
+		return nil, err
+	}
+
+	var resp ImportResponse
+	if err := c.do(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ImportNDJSON loads a snapshot in the format of ExportNDJSON. The snapshot
+// is read into memory so that the request can be retried.
+func (c *Client) ImportNDJSON(ctx context.Context, r io.Reader, policy ConflictPolicy, opts ...CallOption) (*ImportResponse, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	req := newRequest(http.MethodPost, "/admin/import", opts)
+	req.query = url.Values{"on_conflict": {string(policy)}}
+	req.header.Set("Content-Type", ndjsonContentType)
+	req.body = body
+
+	var resp ImportResponse
+	if err := c.do(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Live checks that the process is up.
+func (c *Client) Live(ctx context.Context, opts ...CallOption) (*HealthResponse, error) {
+	var health HealthResponse
+	if err := c.get(ctx, "/health/live", nil, &health, opts); err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+// Ready runs the readiness checks of the service. An unready service
+// returns the checks together with an *Error with status 503; that status
+// is not retried.
+func (c *Client) Ready(ctx context.Context, opts ...CallOption) (*HealthResponse, error) {
+	req := newRequest(http.MethodGet, "/health/ready", opts)
+	req.accept = []int{http.StatusServiceUnavailable}
+
+	var health HealthResponse
+	err := c.do(ctx, req, &health)
+	if apiErr, ok := err.(*Error); ok && apiErr.StatusCode == http.StatusServiceUnavailable && health.Status != "" {
+		apiErr.Message = "service is not ready"
+		return &health, apiErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
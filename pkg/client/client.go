@@ -0,0 +1,342 @@
+// Package client is a Go client for the PR reviewer service.
+//
+// Every endpoint has a typed method taking and returning the request and
+// response types of the API. Error responses of the service are returned as
+// *Error. Requests are retried with exponential backoff on network errors and
+// on 429, 502, 503 and 504 responses, within the deadline of the context;
+// POST requests then carry an Idempotency-Key, the same for every attempt, so
+// that a retry never applies a change twice.
+//
+//	c := client.New("http://localhost:8080", client.WithAPIKey("ci"))
+//	pr, err := c.CreatePR(ctx, client.CreatePRRequest{
+//		PullRequestID:   "pr-1001",
+//		PullRequestName: "Add search",
+//		AuthorID:        "u1",
+//	})
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	mathrand "math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/transport/http/dto"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+
+	apiKeyHeader         = "X-API-Key"
+	idempotencyKeyHeader = "Idempotency-Key"
+	requestIDHeader      = "X-Request-ID"
+)
+
+// RetryPolicy controls how often and how long a failed request is retried.
+// The backoff doubles from InitialBackoff up to MaxBackoff, with jitter; a
+// longer Retry-After of the service wins. No retry is made if the wait would
+// end after the deadline of the context.
+type RetryPolicy struct {
+	// MaxAttempts counts the first attempt; 1 disables retries.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy is used unless WithRetry says otherwise.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+}
+
+// NoRetry sends every request once. POST requests then carry no
+// Idempotency-Key unless one is passed with IdempotencyKey.
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+type Client struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+	retry   RetryPolicy
+}
+
+type Option func(*Client)
+
+// WithHTTPClient replaces the default client, which has a timeout of 30
+// seconds per attempt. Use it for TLS settings such as client certificates.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.http = httpClient
+	}
+}
+
+// WithAPIKey sends the key in the X-API-Key header, which identifies the
+// caller for rate limiting and idempotency keys.
+func WithAPIKey(apiKey string) Option {
+	return func(c *Client) {
+		c.apiKey = apiKey
+	}
+}
+
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// New returns a client for the service at baseURL, e.g.
+// "http://localhost:8080".
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: defaultTimeout},
+		retry:   DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.retry.MaxAttempts < 1 {
+		c.retry.MaxAttempts = 1
+	}
+	return c
+}
+
+// CallOption sets a header of a single call.
+type CallOption func(header http.Header)
+
+// IfMatch makes a merge or reassign fail with CONFLICT unless the pull
+// request still has the given version.
+func IfMatch(version int) CallOption {
+	return func(header http.Header) {
+		header.Set("If-Match", strconv.Quote(strconv.Itoa(version)))
+	}
+}
+
+// IdempotencyKey sets the Idempotency-Key of a POST request instead of a
+// generated one, e.g. to make it safe to repeat across process restarts.
+func IdempotencyKey(key string) CallOption {
+	return func(header http.Header) {
+		header.Set(idempotencyKeyHeader, key)
+	}
+}
+
+// RequestID sets the X-Request-ID the service logs the request with.
+func RequestID(id string) CallOption {
+	return func(header http.Header) {
+		header.Set(requestIDHeader, id)
+	}
+}
+
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   []byte
+	// accept lists non-2xx statuses whose body is still decoded into out;
+	// do returns an *Error for them after decoding.
+	accept []int
+}
+
+func newRequest(method, path string, opts []CallOption) *request {
+	req := &request{method: method, path: path, header: http.Header{}}
+	for _, opt := range opts {
+		opt(req.header)
+	}
+	return req
+}
+
+// setJSON encodes in as the request body.
+func (r *request) setJSON(in any) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	r.body = data
+	r.header.Set("Content-Type", "application/json")
+	return nil
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any, opts []CallOption) error {
+	req := newRequest(http.MethodGet, path, opts)
+	req.query = query
+	return c.do(ctx, req, out)
+}
+
+func (c *Client) post(ctx context.Context, path string, in, out any, opts []CallOption) error {
+	req := newRequest(http.MethodPost, path, opts)
+	if err := req.setJSON(in); err != nil {
+		return err
+	}
+	return c.do(ctx, req, out)
+}
+
+// do sends the request, retrying as the policy allows, and decodes the
+// response into out, if not nil. An io.Writer out receives the raw body.
+func (c *Client) do(ctx context.Context, req *request, out any) error {
+	if req.method == http.MethodPost && c.retry.MaxAttempts > 1 && req.header.Get(idempotencyKeyHeader) == "" {
+		req.header.Set(idempotencyKeyHeader, newIdempotencyKey())
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.send(ctx, req)
+		retryable := err != nil
+		if err == nil {
+			err = c.read(resp, req, out)
+			retryable = isRetryable(err, req)
+		}
+		if err == nil || !retryable || attempt >= c.retry.MaxAttempts || ctx.Err() != nil {
+			return err
+		}
+
+		wait := c.backoff(attempt)
+		if apiErr, ok := err.(*Error); ok && apiErr.RetryAfter > wait {
+			wait = apiErr.RetryAfter
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, req *request) (*http.Response, error) {
+	target := c.baseURL + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+
+	var body io.Reader
+	if req.body != nil {
+		body = bytes.NewReader(req.body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range req.header {
+		httpReq.Header[key] = values
+	}
+	if c.apiKey != "" {
+		httpReq.Header.Set(apiKeyHeader, c.apiKey)
+	}
+
+	return c.http.Do(httpReq)
+}
+
+func (c *Client) read(resp *http.Response, req *request, out any) error {
+	defer resp.Body.Close()
+
+	ok := resp.StatusCode >= 200 && resp.StatusCode < 300
+	accepted := false
+	for _, status := range req.accept {
+		accepted = accepted || resp.StatusCode == status
+	}
+	if !ok && !accepted {
+		return newError(resp)
+	}
+
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		var err error
+		if w, isWriter := out.(io.Writer); isWriter {
+			_, err = io.Copy(w, resp.Body)
+		} else {
+			err = json.NewDecoder(resp.Body).Decode(out)
+		}
+		if err != nil {
+			return fmt.Errorf("%s %s: failed to read response: %w", req.method, req.path, err)
+		}
+	}
+
+	if !ok {
+		return &Error{
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("unexpected status %d", resp.StatusCode),
+			RequestID:  resp.Header.Get(requestIDHeader),
+		}
+	}
+	return nil
+}
+
+func newError(resp *http.Response) *Error {
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get(requestIDHeader),
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	var errResp dto.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil || errResp.Error.Code == "" {
+		apiErr.Message = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+		return apiErr
+	}
+
+	apiErr.Code = ErrorCode(errResp.Error.Code)
+	apiErr.Message = errResp.Error.Message
+	if errResp.Error.RequestID != "" {
+		apiErr.RequestID = errResp.Error.RequestID
+	}
+	return apiErr
+}
+
+// isRetryable reports whether the response may be a transient failure:
+// rate limiting or an unavailable service or proxy.
+func isRetryable(err error, req *request) bool {
+	apiErr, ok := err.(*Error)
+	if !ok {
+		return false
+	}
+	for _, status := range req.accept {
+		if apiErr.StatusCode == status {
+			return false
+		}
+	}
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// backoff returns the wait after the given attempt: the doubled backoff,
+// capped, of which the upper half is random.
+func (c *Client) backoff(attempt int) time.Duration {
+	wait := c.retry.InitialBackoff
+	for i := 1; i < attempt && wait < c.retry.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if c.retry.MaxBackoff > 0 && wait > c.retry.MaxBackoff {
+		wait = c.retry.MaxBackoff
+	}
+	if wait <= 0 {
+		return 0
+	}
+	return wait/2 + mathrand.N(wait/2+1)
+}
+
+func newIdempotencyKey() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:]) //nolint:errcheck
+	return hex.EncodeToString(b[:])
+}
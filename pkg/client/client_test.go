@@ -0,0 +1,168 @@
+package client
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/transport/http/dto"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/transport/http/middleware"
+)
+
+var fastRetry = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+// flakyServer fails the first failures requests with the given status and
+// records the Idempotency-Key of every request.
+type flakyServer struct {
+	mu       sync.Mutex
+	failures int
+	status   int
+	keys     []string
+}
+
+func (s *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.keys = append(s.keys, r.Header.Get(idempotencyKeyHeader))
+	fail := len(s.keys) <= s.failures
+	s.mu.Unlock()
+
+	if fail {
+		middleware.WriteJSONError(w, s.status, string(ErrCodeRateLimited), "slow down")
+		return
+	}
+	middleware.WriteJSON(w, http.StatusCreated, dto.PRResponse{PR: &dto.PullRequest{PullRequestID: "pr-1", Status: "OPEN"}})
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		failures int
+		wantErr  bool
+		requests int
+	}{
+		{"succeeds after retries", http.StatusServiceUnavailable, 2, false, 3},
+		{"gives up after max attempts", http.StatusTooManyRequests, 5, true, 3},
+		{"client errors are not retried", http.StatusConflict, 1, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &flakyServer{failures: tt.failures, status: tt.status}
+			server := httptest.NewServer(handler)
+			defer server.Close()
+
+			c := New(server.URL, WithRetry(fastRetry))
+			pr, err := c.CreatePR(context.Background(), CreatePRRequest{PullRequestID: "pr-1"})
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && pr.PullRequestID != "pr-1" {
+				t.Errorf("Expected pr-1, got %+v", pr)
+			}
+			if len(handler.keys) != tt.requests {
+				t.Fatalf("Expected %d requests, got %d", tt.requests, len(handler.keys))
+			}
+			for _, key := range handler.keys {
+				if key == "" || key != handler.keys[0] {
+					t.Fatalf("Expected the same Idempotency-Key on every attempt, got %q", handler.keys)
+				}
+			}
+		})
+	}
+}
+
+func TestNoRetrySendsNoIdempotencyKey(t *testing.T) {
+	handler := &flakyServer{failures: 1, status: http.StatusServiceUnavailable}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	_, err := New(server.URL, WithRetry(NoRetry)).CreatePR(context.Background(), CreatePRRequest{PullRequestID: "pr-1"})
+	if StatusCode(err) != http.StatusServiceUnavailable {
+		t.Fatalf("Expected a 503 error, got %v", err)
+	}
+	if len(handler.keys) != 1 || handler.keys[0] != "" {
+		t.Fatalf("Expected one request without Idempotency-Key, got %q", handler.keys)
+	}
+}
+
+func TestRetryAfterBeyondDeadline(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Retry-After", "60")
+		middleware.WriteJSONError(w, http.StatusTooManyRequests, string(ErrCodeRateLimited), "slow down")
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err := New(server.URL, WithRetry(fastRetry)).GetPR(ctx, "pr-1")
+	if !IsCode(err, ErrCodeRateLimited) {
+		t.Fatalf("Expected RATE_LIMITED, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected to give up without waiting, took %v", elapsed)
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request, got %d", requests)
+	}
+
+	var apiErr *Error
+	if !stderrors.As(err, &apiErr) || apiErr.RetryAfter != time.Minute {
+		t.Errorf("Expected Retry-After of 1m, got %+v", apiErr)
+	}
+}
+
+func TestErrorIsAppError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("pull_request_id") != "pr-1" {
+			t.Errorf("Unexpected query %q", r.URL.RawQuery)
+		}
+		middleware.WriteError(w, errors.ErrPRNotFound("pr-1"))
+	}))
+	defer server.Close()
+
+	_, err := New(server.URL).GetPR(context.Background(), "pr-1")
+
+	var appErr *errors.AppError
+	if !stderrors.As(err, &appErr) {
+		t.Fatalf("Expected an AppError, got %T", err)
+	}
+	if appErr.Code != errors.ErrCodeNotFound || err.Error() != appErr.Error() {
+		t.Errorf("Expected %v, got %v", err, appErr)
+	}
+	if StatusCode(err) != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", StatusCode(err))
+	}
+}
+
+func TestReadyReturnsChecksWhenUnready(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		middleware.WriteJSON(w, http.StatusServiceUnavailable, dto.HealthResponse{
+			Status: "fail",
+			Checks: map[string]dto.HealthCheckResult{"database": {Status: "fail", Error: "down"}},
+		})
+	}))
+	defer server.Close()
+
+	health, err := New(server.URL, WithRetry(fastRetry)).Ready(context.Background())
+	if StatusCode(err) != http.StatusServiceUnavailable {
+		t.Fatalf("Expected a 503 error, got %v", err)
+	}
+	if health == nil || health.Checks["database"].Error != "down" {
+		t.Fatalf("Expected the failed checks, got %+v", health)
+	}
+	if requests != 1 {
+		t.Errorf("Expected an unready service not to be retried, got %d requests", requests)
+	}
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/errors"
+)
+
+type ErrorCode = errors.ErrorCode
+
+// Error codes of the service. INVALID_REQUEST and INTERNAL_ERROR come from
+// the transport layer and have no AppError constant.
+const (
+	ErrCodeInvalidRequest      ErrorCode = "INVALID_REQUEST"
+	ErrCodeInternal            ErrorCode = "INTERNAL_ERROR"
+	ErrCodeTeamExists                    = errors.ErrCodeTeamExists
+	ErrCodePRExists                      = errors.ErrCodePRExists
+	ErrCodePRMerged                      = errors.ErrCodePRMerged
+	ErrCodeNotAssigned                   = errors.ErrCodeNotAssigned
+	ErrCodeNoCandidate                   = errors.ErrCodeNoCandidate
+	ErrCodeNotFound                      = errors.ErrCodeNotFound
+	ErrCodeConflict                      = errors.ErrCodeConflict
+	ErrCodeRateLimited                   = errors.ErrCodeRateLimited
+	ErrCodeIdempotencyConflict           = errors.ErrCodeIdempotencyConflict
+	ErrCodeInvalidSnapshot               = errors.ErrCodeInvalidSnapshot
+	ErrCodeImportConflict                = errors.ErrCodeImportConflict
+	ErrCodeInvalidRoster                 = errors.ErrCodeInvalidRoster
+)
+
+// Error is an error response of the service. Code is empty if the response
+// had no error body, e.g. a 502 from a proxy or an unready health check.
+type Error struct {
+	StatusCode int
+	Code       ErrorCode
+	Message    string
+	RequestID  string
+	// RetryAfter is the Retry-After of a 429 response.
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("[%s] %s", e.Code, e.Message)
+}
+
+// AppError returns the error as the service reported it.
+func (e *Error) AppError() *errors.AppError {
+	return errors.NewAppError(e.Code, e.Message)
+}
+
+// As lets errors.As convert an *Error to an *errors.AppError.
+func (e *Error) As(target any) bool {
+	appErr, ok := target.(**errors.AppError)
+	if !ok {
+		return false
+	}
+	*appErr = e.AppError()
+	return true
+}
+
+// IsCode reports whether err is an error response with the given code.
+func IsCode(err error, code ErrorCode) bool {
+	var apiErr *Error
+	return stderrors.As(err, &apiErr) && apiErr.Code == code
+}
+
+// StatusCode returns the HTTP status of an error response, or 0 if err is
+// not one.
+func StatusCode(err error) int {
+	var apiErr *Error
+	if stderrors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
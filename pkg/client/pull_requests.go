@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/transport/http/dto"
+)
+
+// CreatePR creates a pull request and assigns up to two reviewers from the
+// author's team.
+func (c *Client) CreatePR(ctx context.Context, req CreatePRRequest, opts ...CallOption) (*PullRequest, error) {
+	var resp dto.PRResponse
+	if err := c.post(ctx, "/pullRequest/create", req, &resp, opts); err != nil {
+		return nil, err
+	}
+	return resp.PR, nil
+}
+
+// MergePR merges a pull request; merging it again returns it unchanged.
+// Pass IfMatch to guard against concurrent changes.
+func (c *Client) MergePR(ctx context.Context, prID string, opts ...CallOption) (*PullRequest, error) {
+	var resp dto.PRResponse
+	if err := c.post(ctx, "/pullRequest/merge", dto.MergePRRequest{PullRequestID: prID}, &resp, opts); err != nil {
+		return nil, err
+	}
+	return resp.PR, nil
+}
+
+// ReassignReviewer replaces a reviewer with another member of their team.
+// Pass IfMatch to guard against concurrent changes.
+func (c *Client) ReassignReviewer(ctx context.Context, prID, oldReviewerID string, opts ...CallOption) (*ReassignResponse, error) {
+	var resp ReassignResponse
+	req := dto.ReassignRequest{PullRequestID: prID, OldReviewerID: oldReviewerID}
+	if err := c.post(ctx, "/pullRequest/reassign", req, &resp, opts); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) GetPR(ctx context.Context, prID string, opts ...CallOption) (*PullRequest, error) {
+	var resp dto.PRResponse
+	if err := c.get(ctx, "/pullRequest/get", url.Values{"pull_request_id": {prID}}, &resp, opts); err != nil {
+		return nil, err
+	}
+	return resp.PR, nil
+}
+
+func (c *Client) DeletePR(ctx context.Context, prID string, opts ...CallOption) error {
+	return c.post(ctx, "/pullRequest/delete", dto.PullRequestIDRequest{PullRequestID: prID}, nil, opts)
+}
+
+func (c *Client) RestorePR(ctx context.Context, prID string, opts ...CallOption) (*PullRequest, error) {
+	var resp dto.PRResponse
+	if err := c.post(ctx, "/pullRequest/restore", dto.PullRequestIDRequest{PullRequestID: prID}, &resp, opts); err != nil {
+		return nil, err
+	}
+	return resp.PR, nil
+}
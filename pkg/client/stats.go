@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+func (c *Client) Stats(ctx context.Context, filter StatsFilter, opts ...CallOption) (*Statistics, error) {
+	var stats Statistics
+	if err := c.get(ctx, "/stats", statsQuery(filter), &stats, opts); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// CycleTime returns time to merge percentiles of pull requests merged in
+// the period.
+func (c *Client) CycleTime(ctx context.Context, filter StatsFilter, opts ...CallOption) (*CycleTimeStats, error) {
+	var stats CycleTimeStats
+	if err := c.get(ctx, "/stats/cycleTime", statsQuery(filter), &stats, opts); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// Workload returns review load per user and its fairness per team.
+func (c *Client) Workload(ctx context.Context, filter StatsFilter, opts ...CallOption) (*WorkloadStats, error) {
+	var stats WorkloadStats
+	if err := c.get(ctx, "/stats/workload", statsQuery(filter), &stats, opts); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+func statsQuery(filter StatsFilter) url.Values {
+	query := url.Values{}
+	if filter.TeamName != "" {
+		query.Set("team_name", filter.TeamName)
+	}
+	if filter.From != nil {
+		query.Set("from", filter.From.Format(time.RFC3339))
+	}
+	if filter.To != nil {
+		query.Set("to", filter.To.Format(time.RFC3339))
+	}
+	return query
+}
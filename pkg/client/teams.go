@@ -0,0 +1,87 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/transport/http/dto"
+)
+
+func (c *Client) CreateTeam(ctx context.Context, req CreateTeamRequest, opts ...CallOption) (*Team, error) {
+	var resp dto.TeamResponse
+	if err := c.post(ctx, "/team/add", req, &resp, opts); err != nil {
+		return nil, err
+	}
+	return resp.Team, nil
+}
+
+func (c *Client) GetTeam(ctx context.Context, teamName string, opts ...CallOption) (*Team, error) {
+	var team Team
+	if err := c.get(ctx, "/team/get", url.Values{"team_name": {teamName}}, &team, opts); err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// DeactivateUsers deactivates members of a team, all of them if
+// req.UserIDs is empty, and moves their open reviews to other members.
+func (c *Client) DeactivateUsers(ctx context.Context, req BulkDeactivateRequest, opts ...CallOption) (*BulkDeactivateResponse, error) {
+	var resp BulkDeactivateResponse
+	if err := c.post(ctx, "/team/deactivateUsers", req, &resp, opts); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeleteTeam deactivates all members of the team before deleting it.
+func (c *Client) DeleteTeam(ctx context.Context, teamName string, opts ...CallOption) (*BulkDeactivateResponse, error) {
+	var resp BulkDeactivateResponse
+	if err := c.post(ctx, "/team/delete", dto.TeamNameRequest{TeamName: teamName}, &resp, opts); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) RestoreTeam(ctx context.Context, teamName string, opts ...CallOption) (*Team, error) {
+	var resp dto.TeamResponse
+	if err := c.post(ctx, "/team/restore", dto.TeamNameRequest{TeamName: teamName}, &resp, opts); err != nil {
+		return nil, err
+	}
+	return resp.Team, nil
+}
+
+// ImportRoster syncs teams with a roster CSV. The roster is read into memory
+// so that the request can be retried.
+func (c *Client) ImportRoster(ctx context.Context, roster io.Reader, dryRun bool, opts ...CallOption) (*RosterImportResponse, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("dry_run", strconv.FormatBool(dryRun)); err != nil {
+		return nil, err
+	}
+	part, err := form.CreateFormFile("file", "roster.csv")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, roster); err != nil {
+		return nil, fmt.Errorf("failed to read roster: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	req := newRequest(http.MethodPost, "/team/import", opts)
+	req.header.Set("Content-Type", form.FormDataContentType())
+	req.body = body.Bytes()
+
+	var resp RosterImportResponse
+	if err := c.do(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
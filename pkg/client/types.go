@@ -0,0 +1,56 @@
+package client
+
+import (
+	"github.com/Raisondetr3/Avito-test-assignment/internal/domain"
+	"github.com/Raisondetr3/Avito-test-assignment/internal/transport/http/dto"
+)
+
+// The request and response types are those of the service, so that they
+// cannot drift apart from the API.
+type (
+	Team                   = dto.Team
+	TeamMember             = dto.TeamMember
+	CreateTeamRequest      = dto.CreateTeamRequest
+	BulkDeactivateRequest  = dto.BulkDeactivateRequest
+	BulkDeactivateResponse = dto.BulkDeactivateResponse
+	ReassignedPRInfo       = dto.ReassignedPRInfo
+	SkippedPRInfo          = dto.SkippedPRInfo
+	RosterImportResponse   = dto.RosterImportResponse
+	RosterUser             = dto.RosterUser
+	RosterMove             = dto.RosterMove
+	RosterDeactivation     = dto.RosterDeactivation
+
+	User              = dto.User
+	UserDetails       = dto.UserDetails
+	GetReviewResponse = dto.GetReviewResponse
+	PullRequestShort  = dto.PullRequestShort
+
+	PullRequest        = dto.PullRequest
+	ReviewerAssignment = dto.ReviewerAssignment
+	CreatePRRequest    = dto.CreatePRRequest
+	ReassignResponse   = dto.ReassignResponse
+
+	Snapshot            = dto.Snapshot
+	SnapshotTeam        = dto.SnapshotTeam
+	SnapshotUser        = dto.SnapshotUser
+	SnapshotPullRequest = dto.SnapshotPullRequest
+	ImportResponse      = dto.ImportResponse
+	ImportCounts        = dto.ImportCounts
+
+	HealthResponse    = dto.HealthResponse
+	HealthCheckResult = dto.HealthCheckResult
+
+	UserFilter      = domain.UserFilter
+	StatsFilter     = domain.StatsFilter
+	Statistics      = domain.Statistics
+	CycleTimeStats  = domain.CycleTimeStats
+	DurationSummary = domain.DurationSummary
+	WorkloadStats   = domain.WorkloadStats
+	ConflictPolicy  = domain.ConflictPolicy
+)
+
+const (
+	ConflictFail      = domain.ConflictFail
+	ConflictSkip      = domain.ConflictSkip
+	ConflictOverwrite = domain.ConflictOverwrite
+)
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/Raisondetr3/Avito-test-assignment/internal/transport/http/dto"
+)
+
+// SetUserActive activates or deactivates a user. Open reviews of a
+// deactivated user stay assigned; see DeactivateUsers.
+func (c *Client) SetUserActive(ctx context.Context, userID string, isActive bool, opts ...CallOption) (*User, error) {
+	var resp dto.UserResponse
+	req := dto.SetActiveRequest{UserID: userID, IsActive: isActive}
+	if err := c.post(ctx, "/users/setIsActive", req, &resp, opts); err != nil {
+		return nil, err
+	}
+	return resp.User, nil
+}
+
+// GetUserReviews lists the pull requests the user is assigned to review.
+func (c *Client) GetUserReviews(ctx context.Context, userID string, opts ...CallOption) (*GetReviewResponse, error) {
+	var resp GetReviewResponse
+	if err := c.get(ctx, "/users/getReview", url.Values{"user_id": {userID}}, &resp, opts); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) GetUser(ctx context.Context, userID string, opts ...CallOption) (*UserDetails, error) {
+	var resp dto.UserDetailsResponse
+	if err := c.get(ctx, "/users/get", url.Values{"user_id": {userID}}, &resp, opts); err != nil {
+		return nil, err
+	}
+	return resp.User, nil
+}
+
+func (c *Client) ListUsers(ctx context.Context, filter UserFilter, opts ...CallOption) ([]*UserDetails, error) {
+	query := url.Values{}
+	if filter.TeamName != "" {
+		query.Set("team_name", filter.TeamName)
+	}
+	if filter.IsActive != nil {
+		query.Set("is_active", strconv.FormatBool(*filter.IsActive))
+	}
+
+	var resp dto.ListUsersResponse
+	if err := c.get(ctx, "/users/list", query, &resp, opts); err != nil {
+		return nil, err
+	}
+	return resp.Users, nil
+}
+
+// DeleteUser deactivates the user, moving their open reviews, and deletes
+// them.
+func (c *Client) DeleteUser(ctx context.Context, userID string, opts ...CallOption) (*BulkDeactivateResponse, error) {
+	var resp BulkDeactivateResponse
+	if err := c.post(ctx, "/users/delete", dto.UserIDRequest{UserID: userID}, &resp, opts); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) RestoreUser(ctx context.Context, userID string, opts ...CallOption) (*User, error) {
+	var resp dto.UserResponse
+	if err := c.post(ctx, "/users/restore", dto.UserIDRequest{UserID: userID}, &resp, opts); err != nil {
+		return nil, err
+	}
+	return resp.User, nil
+}